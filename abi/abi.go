@@ -21,6 +21,7 @@ type ABI struct {
 	Methods      map[string]*Method
 	MethodsBySig map[string]*Method
 	Events       map[string]*Event
+	Errors       map[string]*Error
 }
 
 func (a *ABI) addEvent(e *Event) {
@@ -30,6 +31,13 @@ func (a *ABI) addEvent(e *Event) {
 	a.Events[e.Name] = e
 }
 
+func (a *ABI) addError(e *Error) {
+	if len(a.Errors) == 0 {
+		a.Errors = map[string]*Error{}
+	}
+	a.Errors[e.Name] = e
+}
+
 func (a *ABI) addMethod(m *Method) {
 	if len(a.Methods) == 0 {
 		a.Methods = map[string]*Method{}
@@ -116,6 +124,12 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 				Inputs:    field.Inputs.Type(),
 			}
 
+		case "error":
+			a.addError(&Error{
+				Name:   field.Name,
+				Inputs: field.Inputs.Type(),
+			})
+
 		case "fallback":
 		case "receive":
 			// do nothing
@@ -145,6 +159,32 @@ func (self *ABI) DecodeTxInput(input []byte) (map[string]interface{}, error) {
 	return nil, fmt.Errorf("no matched method")
 }
 
+// ErrorByID returns the error with the selector, nil if not found
+func (self *ABI) ErrorByID(id []byte) *Error {
+	for _, e := range self.Errors {
+		if bytes.Equal(e.ID(), id) {
+			return e
+		}
+	}
+	return nil
+}
+
+// DecodeRevert formats the revert data of the custom errors of the contract
+func (self *ABI) DecodeRevert(data []byte) (string, bool) {
+	if len(data) < 4 {
+		return "", false
+	}
+	e := self.ErrorByID(data[:4])
+	if e == nil {
+		return "", false
+	}
+	msg, err := e.Format(data)
+	if err != nil {
+		return "", false
+	}
+	return msg, true
+}
+
 // Method is a callable function in the contract
 type Method struct {
 	Name    string
@@ -434,8 +474,14 @@ func NewABIFromList(humanReadableAbi []string) (*ABI, error) {
 				return nil, err
 			}
 			res.addEvent(evnt)
+		} else if strings.HasPrefix(c, "error ") {
+			e, err := NewError(c)
+			if err != nil {
+				return nil, err
+			}
+			res.addError(e)
 		} else {
-			return nil, fmt.Errorf("either event or function expected")
+			return nil, fmt.Errorf("either event, error or function expected")
 		}
 	}
 	return res, nil
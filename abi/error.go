@@ -0,0 +1,113 @@
+package abi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/laizy/web3"
+)
+
+// Error is a custom error of the contract, `error InsufficientBalance(uint256 available, uint256 required)`
+type Error struct {
+	Name   string
+	Inputs *Type
+}
+
+// Sig returns the signature of the error
+func (e *Error) Sig() string {
+	return buildSignature(e.Name, e.Inputs)
+}
+
+func (e *Error) DetailedSig() string {
+	return buildHumanSignature(e.Name, e.Inputs)
+}
+
+// ID returns the selector of the error used in the revert data
+func (e *Error) ID() []byte {
+	k := acquireKeccak()
+	k.Write([]byte(e.Sig()))
+	dst := k.Sum(nil)[:4]
+	releaseKeccak(k)
+	return dst
+}
+
+// MustNewError creates a new solidity error object or fails
+func MustNewError(name string) *Error {
+	e, err := NewError(name)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// NewError creates a new solidity error object using the signature
+func NewError(name string) (*Error, error) {
+	name, typ, err := parseEventSignature(strings.TrimPrefix(name, "error "))
+	if err != nil {
+		return nil, err
+	}
+	return &Error{Name: name, Inputs: typ}, nil
+}
+
+// Match checks whether the revert data is from this error
+func (e *Error) Match(data []byte) bool {
+	return len(data) >= 4 && bytes.Equal(data[:4], e.ID())
+}
+
+// Decode decodes the arguments of the revert data, including the selector
+func (e *Error) Decode(data []byte) (map[string]interface{}, error) {
+	if !e.Match(data) {
+		return nil, fmt.Errorf("revert data does not match this error")
+	}
+	val, err := Decode(e.Inputs, data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return val.(map[string]interface{}), nil
+}
+
+// Format decodes the revert data and formats it as `InsufficientBalance(10, 20)`
+func (e *Error) Format(data []byte) (string, error) {
+	args, err := e.Decode(data)
+	if err != nil {
+		return "", err
+	}
+	values := make([]string, len(e.Inputs.tuple))
+	for i, elem := range e.Inputs.tuple {
+		values[i] = formatValue(args[NameToKey(elem.Name, i)])
+	}
+	return fmt.Sprintf("%s(%s)", e.Name, strings.Join(values, ", ")), nil
+}
+
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case *big.Int:
+		return val.String()
+	case web3.Address:
+		return val.String()
+	case string:
+		return strconv.Quote(val)
+	case []byte:
+		return "0x" + hex.EncodeToString(val)
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return "0x" + hex.EncodeToString(convertArrayToBytes(value).Bytes())
+		}
+		fallthrough
+	case reflect.Slice:
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = formatValue(value.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
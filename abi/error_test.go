@@ -0,0 +1,55 @@
+package abi
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	abi, err := NewABI(`[
+		{
+			"type": "error",
+			"name": "InsufficientBalance",
+			"inputs": [
+				{"name": "available", "type": "uint256"},
+				{"name": "required", "type": "uint256"}
+			]
+		}
+	]`)
+	assert.NoError(t, err)
+
+	e := abi.Errors["InsufficientBalance"]
+	assert.Equal(t, "InsufficientBalance(uint256,uint256)", e.Sig())
+	assert.Equal(t, "cf479181", hex.EncodeToString(e.ID()))
+	assert.Equal(t, e, abi.ErrorByID(e.ID()))
+
+	args, err := e.Inputs.Encode([]interface{}{big.NewInt(10), big.NewInt(20)})
+	assert.NoError(t, err)
+	data := append(e.ID(), args...)
+	msg, ok := abi.DecodeRevert(data)
+	assert.True(t, ok)
+	assert.Equal(t, "InsufficientBalance(10, 20)", msg)
+
+	_, ok = abi.DecodeRevert(data[:4])
+	assert.False(t, ok)
+	_, ok = abi.DecodeRevert([]byte{1, 2, 3, 4})
+	assert.False(t, ok)
+}
+
+func TestError_HumanReadable(t *testing.T) {
+	abi, err := NewABIFromList([]string{"error Unauthorized(address caller, string reason, bytes2 code)"})
+	assert.NoError(t, err)
+
+	e := abi.Errors["Unauthorized"]
+	assert.Equal(t, "Unauthorized(address,string,bytes2)", e.Sig())
+	caller := web3.BytesToAddress([]byte{0xca})
+	args, err := e.Inputs.Encode([]interface{}{caller, "owner", [2]byte{1, 2}})
+	assert.NoError(t, err)
+	msg, err := e.Format(append(e.ID(), args...))
+	assert.NoError(t, err)
+	assert.Equal(t, "Unauthorized("+caller.String()+", \"owner\", 0x0102)", msg)
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2021 The Ontology Authors
+// Copyright 2014 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package web3
+
+import (
+	"golang.org/x/crypto/sha3"
+)
+
+// BloomByteLength represents the number of bytes used in a header log bloom.
+const BloomByteLength = 256
+
+// Bloom represents a 2048 bit bloom filter.
+type Bloom [BloomByteLength]byte
+
+// Add adds d to the filter. Future calls of Test(d) will return true.
+func (b *Bloom) Add(d []byte) {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(d)
+	var buf [32]byte
+	h.Sum(buf[:0])
+	// For each of the three pairs of bytes, the low 11 bits select the bit to set
+	for i := 0; i < 6; i += 2 {
+		bit := (uint(buf[i+1]) | uint(buf[i])<<8) & 2047
+		b[BloomByteLength-1-bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Or sets b to the union of b and other.
+func (b *Bloom) Or(other Bloom) {
+	for i := range b {
+		b[i] |= other[i]
+	}
+}
+
+// Test checks if the given data is possibly present in the filter.
+func (b Bloom) Test(d []byte) bool {
+	var probe Bloom
+	probe.Add(d)
+	for i := range probe {
+		if probe[i]&b[i] != probe[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Bytes returns the backing byte slice of the bloom
+func (b Bloom) Bytes() []byte {
+	return b[:]
+}
+
+// LogsBloom returns the bloom filter of the addresses and topics of the logs.
+func LogsBloom(logs []*Log) Bloom {
+	var bloom Bloom
+	for _, log := range logs {
+		bloom.Add(log.Address[:])
+		for _, topic := range log.Topics {
+			bloom.Add(topic[:])
+		}
+	}
+	return bloom
+}
@@ -0,0 +1,61 @@
+package web3
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestBloom(t *testing.T) {
+	positive := []string{"testtest", "test", "hallo", "other"}
+	negative := []string{"tes", "lo"}
+
+	var bloom Bloom
+	for _, data := range positive {
+		bloom.Add([]byte(data))
+	}
+	for _, data := range positive {
+		assert.True(t, bloom.Test([]byte(data)), data)
+	}
+	for _, data := range negative {
+		assert.False(t, bloom.Test([]byte(data)), data)
+	}
+}
+
+func TestLogsBloom(t *testing.T) {
+	log := &Log{
+		Address: HexToAddress("0x22341ae42d6dd7384bc8584e50419ea3ac75b83f"),
+		Topics:  []Hash{HexToHash("0x04491edcd115127caedbd478e2e7895ed80c7847e903431f94f9cfa579cad47f")},
+	}
+	bloom := LogsBloom([]*Log{log})
+	assert.True(t, bloom.Test(log.Address[:]))
+	assert.True(t, bloom.Test(log.Topics[0][:]))
+
+	// every entry sets at most three bits
+	bits := 0
+	for _, b := range bloom {
+		for ; b != 0; b &= b - 1 {
+			bits++
+		}
+	}
+	assert.True(t, bits > 0 && bits <= 6)
+
+	var other Bloom
+	other.Or(bloom)
+	assert.Equal(t, bloom, other)
+}
+
+func TestBloomExtensively(t *testing.T) {
+	exp := HexToHash("0xc8d3ca65cdb4874300a9e39475508f23ed6da09fdbc487f89a2dcf50b09eb263")
+	var b Bloom
+	for i := 0; i < 100; i++ {
+		b.Add([]byte(fmt.Sprintf("xxxxxxxxxx data %d yyyyyyyyyyyyyy", i)))
+	}
+	h := sha3.NewLegacyKeccak256()
+	h.Write(b.Bytes())
+	var got Hash
+	h.Sum(got[:0])
+	assert.Equal(t, exp, got)
+}
@@ -3,6 +3,9 @@ package compiler
 import (
 	"fmt"
 	"strings"
+
+	"github.com/laizy/web3/sourcemap"
+	"github.com/laizy/web3/utils/common/hexutil"
 )
 
 type factory func(path string) Compiler
@@ -29,9 +32,13 @@ func NewCompiler(name string, path string) (Compiler, error) {
 
 // Artifact is a contract output from the compiler
 type Artifact struct {
-	Abi        string
-	Bin        string
-	BinRuntime string
+	Abi           string
+	Bin           string
+	BinRuntime    string
+	SrcMap        string
+	SrcMapRuntime string
+	// Sources are the compiled source files indexed by the file index of the source maps
+	Sources []sourcemap.Source
 }
 
 func NewArtifact(abi, bin, binRuntime string) *Artifact {
@@ -48,3 +55,22 @@ func unifyHexString(hex string) string {
 	}
 	return hex
 }
+
+// SourceMaps returns the creation code and the runtime code of the contract with their source maps
+func (a *Artifact) SourceMaps(name string) (creation, runtime *sourcemap.Contract, err error) {
+	bin, err := hexutil.Decode(a.Bin)
+	if err != nil {
+		return nil, nil, err
+	}
+	binRuntime, err := hexutil.Decode(a.BinRuntime)
+	if err != nil {
+		return nil, nil, err
+	}
+	if creation, err = sourcemap.NewContract(name, bin, a.SrcMap, a.Sources); err != nil {
+		return nil, nil, err
+	}
+	if runtime, err = sourcemap.NewContract(name, binRuntime, a.SrcMapRuntime, a.Sources); err != nil {
+		return nil, nil, err
+	}
+	return creation, runtime, nil
+}
@@ -13,18 +13,22 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/laizy/web3/sourcemap"
 	"github.com/laizy/web3/utils"
 )
 
 type solcOutput struct {
-	Contracts map[string]*solcContract
-	Version   string
+	Contracts  map[string]*solcContract
+	SourceList []string `json:"sourceList"`
+	Version    string
 }
 
 type solcContract struct {
-	BinRuntime string `json:"bin-runtime"`
-	Bin        string
-	Abi        interface{}
+	BinRuntime    string `json:"bin-runtime"`
+	Bin           string
+	Abi           interface{}
+	SrcMap        string `json:"srcmap"`
+	SrcMapRuntime string `json:"srcmap-runtime"`
 }
 
 // Solidity is the solidity compiler
@@ -60,7 +64,7 @@ func (s *Solidity) Compile(files ...string) (map[string]*Artifact, error) {
 func (s *Solidity) compileImpl(code string, files ...string) (map[string]*Artifact, error) {
 	args := []string{
 		"--combined-json",
-		"bin,bin-runtime,abi",
+		"bin,bin-runtime,abi,srcmap,srcmap-runtime",
 	}
 	if code != "" {
 		args = append(args, "-")
@@ -87,13 +91,27 @@ func (s *Solidity) compileImpl(code string, files ...string) (map[string]*Artifa
 		return nil, err
 	}
 
+	sources := make([]sourcemap.Source, len(output.SourceList))
+	for i, name := range output.SourceList {
+		sources[i].Name = name
+		if name == "<stdin>" {
+			sources[i].Content = code
+		} else if content, err := ioutil.ReadFile(name); err == nil {
+			sources[i].Content = string(content)
+		}
+	}
+
 	artifacts := map[string]*Artifact{}
 	for name, i := range output.Contracts {
 		_abi := fmt.Sprint(i.Abi)
 		if reflect.TypeOf(i.Abi).Kind() != reflect.String { //some compiler version set abi to struct, while others sets abi to string
 			_abi = utils.JsonStr(i.Abi)
 		}
-		artifacts[name] = NewArtifact(_abi, i.Bin, i.BinRuntime)
+		artifact := NewArtifact(_abi, i.Bin, i.BinRuntime)
+		artifact.SrcMap = i.SrcMap
+		artifact.SrcMapRuntime = i.SrcMapRuntime
+		artifact.Sources = sources
+		artifacts[name] = artifact
 	}
 	return artifacts, nil
 }
@@ -161,6 +161,11 @@ type Txn struct {
 	gasPrice uint64
 	Data     []byte
 	hash     web3.Hash
+
+	// eip1559 fees, the transaction is sent as a dynamic fee transaction if any is set
+	maxFeePerGas         *big.Int
+	maxPriorityFeePerGas *big.Int
+	accessList           web3.AccessList
 }
 
 func (t *Txn) isContractDeployment() bool {
@@ -230,10 +235,42 @@ func (self *SignedTx) SendTransaction(signer *Signer) *web3.Receipt {
 	return signer.SendTransaction(self.Transaction)
 }
 
+func (t *Txn) isDynamicFee() bool {
+	return t.maxFeePerGas != nil || t.maxPriorityFeePerGas != nil
+}
+
+// fillDynamicFee sets the missing eip1559 fees. The default fee cap leaves room
+// for the base fee to double before the transaction gets stuck.
+func (t *Txn) fillDynamicFee() error {
+	if t.maxPriorityFeePerGas == nil {
+		tip, err := t.provider.Eth().MaxPriorityFeePerGas()
+		if err != nil {
+			return err
+		}
+		t.maxPriorityFeePerGas = tip
+	}
+	if t.maxFeePerGas == nil {
+		head, err := t.provider.Eth().GetBlockByNumber(web3.Latest, false)
+		if err != nil {
+			return err
+		}
+		if head.BaseFee == nil {
+			return fmt.Errorf("chain does not support eip1559 dynamic fee")
+		}
+		fee := new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+		t.maxFeePerGas = fee.Add(fee, t.maxPriorityFeePerGas)
+	}
+	return nil
+}
+
 func (t *Txn) ToTransaction() (*web3.Transaction, error) {
 	var err error
-	// estimate gas price
-	if t.gasPrice == 0 {
+	if t.isDynamicFee() {
+		if err = t.fillDynamicFee(); err != nil {
+			return nil, err
+		}
+	} else if t.gasPrice == 0 {
+		// estimate gas price
 		t.gasPrice, err = t.provider.Eth().GasPrice()
 		if err != nil {
 			return nil, err
@@ -268,6 +305,16 @@ func (t *Txn) ToTransaction() (*web3.Transaction, error) {
 	if t.to != nil {
 		txn.To = t.to
 	}
+	if t.isDynamicFee() {
+		txn.Type = web3.TransactionDynamicFee
+		txn.GasPrice = 0
+		txn.MaxFeePerGas = new(big.Int).Set(t.maxFeePerGas)
+		txn.MaxPriorityFeePerGas = new(big.Int).Set(t.maxPriorityFeePerGas)
+		txn.AccessList = t.accessList
+	} else if t.accessList != nil {
+		txn.Type = web3.TransactionAccessList
+		txn.AccessList = t.accessList
+	}
 
 	return txn, nil
 }
@@ -292,6 +339,24 @@ func (t *Txn) SetGasPrice(gasPrice uint64) *Txn {
 	return t
 }
 
+// SetMaxFeePerGas sets the eip1559 fee cap, the transaction is sent as a dynamic fee transaction
+func (t *Txn) SetMaxFeePerGas(fee *big.Int) *Txn {
+	t.maxFeePerGas = new(big.Int).Set(fee)
+	return t
+}
+
+// SetMaxPriorityFeePerGas sets the eip1559 tip, the transaction is sent as a dynamic fee transaction
+func (t *Txn) SetMaxPriorityFeePerGas(fee *big.Int) *Txn {
+	t.maxPriorityFeePerGas = new(big.Int).Set(fee)
+	return t
+}
+
+// SetAccessList sets the eip2930 access list of the transaction
+func (t *Txn) SetAccessList(list web3.AccessList) *Txn {
+	t.accessList = list
+	return t
+}
+
 // SetGasLimit sets the gas limit of the transaction
 func (t *Txn) SetGasLimit(gasLimit uint64) *Txn {
 	t.gasLimit = gasLimit
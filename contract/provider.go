@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm"
 	"github.com/laizy/web3/executor"
 	"github.com/laizy/web3/jsonrpc"
 	"github.com/laizy/web3/utils"
@@ -30,7 +31,7 @@ func NewSigner(hexPrivKey string, client *jsonrpc.Client, chainId uint64) *Signe
 	account, err := wallet.NewWalletFromPrivKey(key)
 	utils.Ensure(err)
 
-	signer := wallet.NewEIP155Signer(chainId)
+	signer := wallet.NewLondonSigner(chainId)
 
 	nonce, err := client.Eth().GetNonce(account.Address(), web3.Latest)
 	utils.Ensure(err)
@@ -42,6 +43,7 @@ func NewSigner(hexPrivKey string, client *jsonrpc.Client, chainId uint64) *Signe
 		Executor: executor.NewExecutor(client),
 		Nonce:    nonce,
 	}
+	result.Executor.SetChainID(chainId)
 
 	return result
 }
@@ -81,6 +83,12 @@ func (self *Signer) ExecuteTxn(tx *web3.Transaction) (*web3.ExecutionResult, *we
 	return result, receipt
 }
 
+// EnableGasProfiler profiles the gas of the transactions executed by ExecuteTxn, names of
+// contracts and methods are resolved with the resolver which may be nil.
+func (self *Signer) EnableGasProfiler(resolver evm.NameResolver) *evm.GasProfiler {
+	return self.Executor.EnableGasProfiler(resolver)
+}
+
 func (self *Signer) WaitTx(hs web3.Hash) *web3.Receipt {
 	for {
 		receipt, err := self.Client.Eth().GetTransactionReceipt(hs)
@@ -102,7 +102,38 @@ var PrecompiledContractsYoloV2 = map[web3.Address]PrecompiledContract{
 	web3.BytesToAddress([]byte{18}): &bls12381MapG2{},
 }
 
+// PrecompiledContractsBerlin contains the default set of pre-compiled Ethereum
+// contracts used in the Berlin release. It is also used for London, Shanghai and
+// Cancun: the Cancun KZG point evaluation precompile (0x0a) needs a trusted setup
+// and is not supported yet.
+var PrecompiledContractsBerlin = map[web3.Address]PrecompiledContract{
+	web3.BytesToAddress([]byte{1}): &ecrecover{},
+	web3.BytesToAddress([]byte{2}): &sha256hash{},
+	web3.BytesToAddress([]byte{3}): &ripemd160hash{},
+	web3.BytesToAddress([]byte{4}): &dataCopy{},
+	web3.BytesToAddress([]byte{5}): &bigModExp{eip2565: true},
+	web3.BytesToAddress([]byte{6}): &bn256AddIstanbul{},
+	web3.BytesToAddress([]byte{7}): &bn256ScalarMulIstanbul{},
+	web3.BytesToAddress([]byte{8}): &bn256PairingIstanbul{},
+	web3.BytesToAddress([]byte{9}): &blake2F{},
+}
+
+// PrecompiledContractsBLS contains the set of pre-compiled Ethereum
+// contracts specified in EIP-2537. These are exported for testing purposes.
+var PrecompiledContractsBLS = map[web3.Address]PrecompiledContract{
+	web3.BytesToAddress([]byte{10}): &bls12381G1Add{},
+	web3.BytesToAddress([]byte{11}): &bls12381G1Mul{},
+	web3.BytesToAddress([]byte{12}): &bls12381G1MultiExp{},
+	web3.BytesToAddress([]byte{13}): &bls12381G2Add{},
+	web3.BytesToAddress([]byte{14}): &bls12381G2Mul{},
+	web3.BytesToAddress([]byte{15}): &bls12381G2MultiExp{},
+	web3.BytesToAddress([]byte{16}): &bls12381Pairing{},
+	web3.BytesToAddress([]byte{17}): &bls12381MapG1{},
+	web3.BytesToAddress([]byte{18}): &bls12381MapG2{},
+}
+
 var (
+	PrecompiledAddressesBerlin    []web3.Address
 	PrecompiledAddressesYoloV2    []web3.Address
 	PrecompiledAddressesIstanbul  []web3.Address
 	PrecompiledAddressesByzantium []web3.Address
@@ -114,7 +145,7 @@ func init() {
 		PrecompiledAddressesHomestead = append(PrecompiledAddressesHomestead, k)
 	}
 	for k := range PrecompiledContractsByzantium {
-		PrecompiledAddressesHomestead = append(PrecompiledAddressesByzantium, k)
+		PrecompiledAddressesByzantium = append(PrecompiledAddressesByzantium, k)
 	}
 	for k := range PrecompiledContractsIstanbul {
 		PrecompiledAddressesIstanbul = append(PrecompiledAddressesIstanbul, k)
@@ -122,6 +153,9 @@ func init() {
 	for k := range PrecompiledContractsYoloV2 {
 		PrecompiledAddressesYoloV2 = append(PrecompiledAddressesYoloV2, k)
 	}
+	for k := range PrecompiledContractsBerlin {
+		PrecompiledAddressesBerlin = append(PrecompiledAddressesBerlin, k)
+	}
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
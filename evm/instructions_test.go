@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/laizy/web3"
@@ -653,3 +654,144 @@ func TestCreate2Addreses(t *testing.T) {
 		}
 	}
 }
+
+func TestOpMCopy(t *testing.T) {
+	// Test cases from https://eips.ethereum.org/EIPS/eip-5656#test-cases
+	for i, tc := range []struct {
+		dst, src, len string
+		pre           string
+		want          string
+		wantGas       uint64
+	}{
+		{ // MCOPY 0 32 32 - copy 32 bytes from offset 32 to offset 0.
+			dst: "0x0", src: "0x20", len: "0x20",
+			pre:     "0000000000000000000000000000000000000000000000000000000000000000 000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			want:    "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f 000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			wantGas: 6,
+		},
+		{ // MCOPY 0 0 32 - copy 32 bytes from offset 0 to offset 0.
+			dst: "0x0", src: "0x0", len: "0x20",
+			pre:     "0101010101010101010101010101010101010101010101010101010101010101",
+			want:    "0101010101010101010101010101010101010101010101010101010101010101",
+			wantGas: 6,
+		},
+		{ // MCOPY 0 1 8 - copy 8 bytes from offset 1 to offset 0 (overlapping).
+			dst: "0x0", src: "0x1", len: "0x8",
+			pre:     "000102030405060708 000000000000000000000000000000000000000000000000",
+			want:    "010203040506070808 000000000000000000000000000000000000000000000000",
+			wantGas: 6,
+		},
+		{ // MCOPY 1 0 8 - copy 8 bytes from offset 0 to offset 1 (overlapping).
+			dst: "0x1", src: "0x0", len: "0x8",
+			pre:     "000102030405060708 000000000000000000000000000000000000000000000000",
+			want:    "000001020304050607 000000000000000000000000000000000000000000000000",
+			wantGas: 6,
+		},
+		{ // MCOPY 0xFFFFFFFFFFFF 0xFFFFFFFFFFFF 0 - copy zero bytes from out-of-bounds index.
+			dst: "0xFFFFFFFFFFFF", src: "0xFFFFFFFFFFFF", len: "0x0",
+			pre:     "11",
+			want:    "11",
+			wantGas: 3,
+		},
+		{ // MCOPY 0x20 0 1 - copy one byte and expand the memory by one word.
+			dst: "0x20", src: "0x0", len: "0x1",
+			pre:     "0000000000000000000000000000000000000000000000000000000000000001",
+			want:    "0000000000000000000000000000000000000000000000000000000000000001 0000000000000000000000000000000000000000000000000000000000000000",
+			wantGas: 12, // constant 3 + copy 3 + expansion to 2 words 6 - 0 charged before
+		},
+	} {
+		var (
+			env            = NewEVM(BlockContext{}, TxContext{}, nil, params.TestChainConfig, Config{})
+			stack          = newstack()
+			pc             = uint64(0)
+			evmInterpreter = NewEVMInterpreter(env, env.vmConfig)
+			mem            = NewMemory()
+		)
+		data := common.FromHex(strings.ReplaceAll(tc.pre, " ", ""))
+		mem.Resize(uint64(len(data)))
+		mem.Set(0, uint64(len(data)), data)
+
+		length, _ := uint256.FromHex(tc.len)
+		src, _ := uint256.FromHex(tc.src)
+		dst, _ := uint256.FromHex(tc.dst)
+		stack.push(length)
+		stack.push(src)
+		stack.push(dst)
+
+		memSize, overflow := memoryMcopy(stack)
+		if overflow {
+			t.Fatalf("case %d: memory size overflow", i)
+		}
+		memorySize := toWordSize(memSize) * 32
+		dynamicCost, err := gasMcopy(env, nil, stack, mem, memorySize)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if memorySize > 0 {
+			mem.Resize(memorySize)
+		}
+		opMcopy(&pc, evmInterpreter, &callCtx{mem, stack, nil, nil})
+
+		want := common.FromHex(strings.ReplaceAll(tc.want, " ", ""))
+		if have := mem.Data(); !bytes.Equal(want, have) {
+			t.Errorf("case %d: \nwant: %#x\nhave: %#x\n", i, want, have)
+		}
+		if haveGas := GasFastestStep + dynamicCost; haveGas != tc.wantGas {
+			t.Errorf("case %d: gas wrong, want %d have %d\n", i, tc.wantGas, haveGas)
+		}
+	}
+}
+
+func TestOpBlobHash(t *testing.T) {
+	hashes := []web3.Hash{web3.BytesToHash([]byte{1}), web3.BytesToHash([]byte{2})}
+	var (
+		env            = NewEVM(BlockContext{}, TxContext{BlobHashes: hashes}, nil, params.TestChainConfig, Config{})
+		stack          = newstack()
+		pc             = uint64(0)
+		evmInterpreter = NewEVMInterpreter(env, env.vmConfig)
+	)
+	for i, tc := range []struct {
+		index uint64
+		want  web3.Hash
+	}{
+		{0, hashes[0]},
+		{1, hashes[1]},
+		{2, web3.Hash{}},
+	} {
+		stack.push(new(uint256.Int).SetUint64(tc.index))
+		opBlobHash(&pc, evmInterpreter, &callCtx{nil, stack, nil, nil})
+		if have := stack.pop(); web3.Hash(have.Bytes32()) != tc.want {
+			t.Errorf("case %d: want %x, have %x", i, tc.want, have.Bytes32())
+		}
+	}
+}
+
+func TestForkInstructionSets(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		jt    JumpTable
+		op    OpCode
+		exist bool
+	}{
+		{"istanbul", istanbulInstructionSet, BASEFEE, false},
+		{"london", londonInstructionSet, BASEFEE, true},
+		{"london", londonInstructionSet, PUSH0, false},
+		{"shanghai", shanghaiInstructionSet, PUSH0, true},
+		{"shanghai", shanghaiInstructionSet, TLOAD, false},
+		{"cancun", cancunInstructionSet, TLOAD, true},
+		{"cancun", cancunInstructionSet, TSTORE, true},
+		{"cancun", cancunInstructionSet, MCOPY, true},
+		{"cancun", cancunInstructionSet, BLOBHASH, true},
+		{"cancun", cancunInstructionSet, BLOBBASEFEE, true},
+	} {
+		if exist := tc.jt[tc.op] != nil; exist != tc.exist {
+			t.Errorf("%s: opcode %v defined: %v, want %v", tc.name, tc.op, exist, tc.exist)
+		}
+	}
+	if gas := berlinInstructionSet[SLOAD].constantGas; gas != 0 {
+		t.Errorf("berlin SLOAD constant gas: have %d, want 0", gas)
+	}
+	if gas := istanbulInstructionSet[SLOAD].constantGas; gas != params.SloadGasEIP2200 {
+		t.Errorf("istanbul SLOAD constant gas: have %d, want %d", gas, params.SloadGasEIP2200)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"math/big"
 
 	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/params"
 )
 
 // StateDB is an EVM database for full state querying.
@@ -50,6 +51,14 @@ type StateDB interface {
 	Suicide(web3.Address) bool
 	HasSuicided(web3.Address) bool
 
+	// Selfdestruct6780 is post-EIP6780 selfdestruct, which means that it's a
+	// send-all-to-beneficiary, unless the contract was created in this same
+	// transaction, in which case it will be destructed.
+	Selfdestruct6780(web3.Address)
+
+	GetTransientState(addr web3.Address, key web3.Hash) web3.Hash
+	SetTransientState(addr web3.Address, key, value web3.Hash)
+
 	// Exist reports whether the given account exists in state.
 	// Notably this should also return true for suicided accounts.
 	Exist(web3.Address) bool
@@ -57,6 +66,19 @@ type StateDB interface {
 	// is defined according to EIP161 (balance = nonce = code = 0).
 	Empty(web3.Address) bool
 
+	// PrepareAccessList resets the per transaction state (access list, transient
+	// storage) and warms up the addresses and slots touched by the transaction
+	// itself, as required by EIP-2929, EIP-2930 and EIP-3651.
+	PrepareAccessList(rules params.Rules, sender, coinbase web3.Address, dest *web3.Address, precompiles []web3.Address, txAccesses web3.AccessList)
+	AddressInAccessList(addr web3.Address) bool
+	SlotInAccessList(addr web3.Address, slot web3.Hash) (addressOk bool, slotOk bool)
+	// AddAddressToAccessList adds the given address to the access list. This operation is safe to perform
+	// even if the feature/fork is not active yet
+	AddAddressToAccessList(addr web3.Address)
+	// AddSlotToAccessList adds the given (address,slot) to the access list. This operation is safe to perform
+	// even if the feature/fork is not active yet
+	AddSlotToAccessList(addr web3.Address, slot web3.Hash)
+
 	RevertToSnapshot(int)
 	Snapshot() int
 
@@ -58,14 +58,55 @@ var (
 	constantinopleInstructionSet   = newConstantinopleInstructionSet()
 	istanbulInstructionSet         = newIstanbulInstructionSet()
 	yoloV2InstructionSet           = newYoloV2InstructionSet()
+	berlinInstructionSet           = newBerlinInstructionSet()
+	londonInstructionSet           = newLondonInstructionSet()
+	shanghaiInstructionSet         = newShanghaiInstructionSet()
+	cancunInstructionSet           = newCancunInstructionSet()
 )
 
 // JumpTable contains the EVM opcodes supported at a given fork.
 type JumpTable [256]*operation
 
+// newCancunInstructionSet returns the frontier, homestead, byzantium,
+// constantinople, istanbul, berlin, london, shanghai and cancun instructions.
+func newCancunInstructionSet() JumpTable {
+	instructionSet := newShanghaiInstructionSet()
+	enable4844(&instructionSet) // BLOBHASH opcode - https://eips.ethereum.org/EIPS/eip-4844
+	enable7516(&instructionSet) // BLOBBASEFEE opcode - https://eips.ethereum.org/EIPS/eip-7516
+	enable1153(&instructionSet) // Transient storage opcodes - https://eips.ethereum.org/EIPS/eip-1153
+	enable5656(&instructionSet) // MCOPY opcode - https://eips.ethereum.org/EIPS/eip-5656
+	enable6780(&instructionSet) // SELFDESTRUCT only in same transaction - https://eips.ethereum.org/EIPS/eip-6780
+	return instructionSet
+}
+
+// newShanghaiInstructionSet returns the frontier, homestead, byzantium,
+// constantinople, istanbul, berlin, london and shanghai instructions.
+func newShanghaiInstructionSet() JumpTable {
+	instructionSet := newLondonInstructionSet()
+	enable3855(&instructionSet) // PUSH0 instruction - https://eips.ethereum.org/EIPS/eip-3855
+	enable3860(&instructionSet) // Limit and meter initcode - https://eips.ethereum.org/EIPS/eip-3860
+	return instructionSet
+}
+
+// newLondonInstructionSet returns the frontier, homestead, byzantium,
+// constantinople, istanbul, berlin and london instructions.
+func newLondonInstructionSet() JumpTable {
+	instructionSet := newBerlinInstructionSet()
+	enable3529(&instructionSet) // Reduction in refunds - https://eips.ethereum.org/EIPS/eip-3529
+	enable3198(&instructionSet) // BASEFEE opcode - https://eips.ethereum.org/EIPS/eip-3198
+	return instructionSet
+}
+
+// newBerlinInstructionSet returns the frontier, homestead, byzantium,
+// constantinople, istanbul and berlin instructions.
+func newBerlinInstructionSet() JumpTable {
+	instructionSet := newIstanbulInstructionSet()
+	enable2929(&instructionSet) // Access lists for trie accesses - https://eips.ethereum.org/EIPS/eip-2929
+	return instructionSet
+}
+
 // newYoloV2InstructionSet creates an instructionset containing
 // - "EIP-2315: Simple Subroutines"
-// - "EIP-2929: Gas cost increases for state access opcodes"
 func newYoloV2InstructionSet() JumpTable {
 	instructionSet := newIstanbulInstructionSet()
 	enable2315(&instructionSet) // Subroutines - https://eips.ethereum.org/EIPS/eip-2315
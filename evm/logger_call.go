@@ -0,0 +1,188 @@
+package evm
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/errors"
+	"github.com/laizy/web3/utils/common/hexutil"
+)
+
+// TxTracer is implemented by tracers which need the gas limit and the gas left of the whole
+// transaction, the intrinsic gas is already deducted from the gas passed to CaptureStart.
+type TxTracer interface {
+	CaptureTxStart(gasLimit uint64)
+	CaptureTxEnd(restGas uint64)
+}
+
+// CallFrame is a call made during the execution. It is serialized in the same format as the
+// callTracer of geth, so the result can be compared with debug_traceTransaction of nodes.
+type CallFrame struct {
+	Type         OpCode
+	From         web3.Address
+	To           *web3.Address // nil if the contract creation failed
+	Value        *big.Int      // nil for STATICCALL
+	Gas          uint64
+	GasUsed      uint64
+	Input        []byte
+	Output       []byte
+	Error        string
+	RevertReason string
+	Calls        []*CallFrame
+}
+
+type callFrameMarshaling struct {
+	Type         string         `json:"type"`
+	From         web3.Address   `json:"from"`
+	To           *web3.Address  `json:"to,omitempty"`
+	Value        *hexutil.Big   `json:"value,omitempty"`
+	Gas          hexutil.Uint64 `json:"gas"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Input        hexutil.Bytes  `json:"input"`
+	Output       hexutil.Bytes  `json:"output,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	RevertReason string         `json:"revertReason,omitempty"`
+	Calls        []*CallFrame   `json:"calls,omitempty"`
+}
+
+func (self *CallFrame) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&callFrameMarshaling{
+		Type:         self.Type.String(),
+		From:         self.From,
+		To:           self.To,
+		Value:        (*hexutil.Big)(self.Value),
+		Gas:          hexutil.Uint64(self.Gas),
+		GasUsed:      hexutil.Uint64(self.GasUsed),
+		Input:        self.Input,
+		Output:       self.Output,
+		Error:        self.Error,
+		RevertReason: self.RevertReason,
+		Calls:        self.Calls,
+	})
+}
+
+func (self *CallFrame) UnmarshalJSON(data []byte) error {
+	var dec callFrameMarshaling
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	*self = CallFrame{
+		Type:         StringToOp(dec.Type),
+		From:         dec.From,
+		To:           dec.To,
+		Value:        (*big.Int)(dec.Value),
+		Gas:          uint64(dec.Gas),
+		GasUsed:      uint64(dec.GasUsed),
+		Input:        dec.Input,
+		Output:       dec.Output,
+		Error:        dec.Error,
+		RevertReason: dec.RevertReason,
+		Calls:        dec.Calls,
+	}
+	return nil
+}
+
+// CallTracer builds the tree of the call frames of the execution, it implements Tracer and TxTracer.
+type CallTracer struct {
+	callstack []*CallFrame
+	gasLimit  uint64
+}
+
+// NewCallTracer creates a tracer building the call frames of the execution
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+func (t *CallTracer) CaptureTxStart(gasLimit uint64) {
+	t.gasLimit = gasLimit
+}
+
+func (t *CallTracer) CaptureTxEnd(restGas uint64) {
+	if len(t.callstack) == 0 {
+		return
+	}
+	t.callstack[0].Gas = t.gasLimit
+	t.callstack[0].GasUsed = t.gasLimit - restGas
+}
+
+func (t *CallTracer) CaptureStart(from web3.Address, to web3.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	typ := CALL
+	if create {
+		typ = CREATE
+	}
+	if t.gasLimit != 0 {
+		gas = t.gasLimit
+	}
+	t.callstack = []*CallFrame{newCallFrame(typ, from, to, input, gas, value)}
+}
+
+func (t *CallTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack,
+	rStack *ReturnStack, rData []byte, contract *Contract, depth int, err error) {
+}
+
+func (t *CallTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack,
+	rStack *ReturnStack, contract *Contract, depth int, err error) {
+}
+
+func (t *CallTracer) CaptureEnd(output []byte, gasUsed uint64, tm time.Duration, err error) {
+	if len(t.callstack) == 0 {
+		return
+	}
+	t.callstack[0].finish(output, gasUsed, err)
+}
+
+func (t *CallTracer) CaptureEnter(typ OpCode, from web3.Address, to web3.Address, input []byte, gas uint64, value *big.Int) {
+	t.callstack = append(t.callstack, newCallFrame(typ, from, to, input, gas, value))
+}
+
+func (t *CallTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	size := len(t.callstack)
+	if size <= 1 {
+		return
+	}
+	frame := t.callstack[size-1]
+	t.callstack = t.callstack[:size-1]
+	frame.finish(output, gasUsed, err)
+	parent := t.callstack[size-2]
+	parent.Calls = append(parent.Calls, frame)
+}
+
+// Result returns the outermost call frame, nil if nothing is executed
+func (t *CallTracer) Result() *CallFrame {
+	if len(t.callstack) == 0 {
+		return nil
+	}
+	return t.callstack[0]
+}
+
+func newCallFrame(typ OpCode, from web3.Address, to web3.Address, input []byte, gas uint64, value *big.Int) *CallFrame {
+	frame := &CallFrame{
+		Type:  typ,
+		From:  from,
+		To:    &to,
+		Gas:   gas,
+		Input: append([]byte(nil), input...),
+	}
+	if value != nil {
+		frame.Value = new(big.Int).Set(value)
+	}
+	return frame
+}
+
+func (self *CallFrame) finish(output []byte, gasUsed uint64, err error) {
+	self.GasUsed = gasUsed
+	if err == nil || err == errors.ErrExecutionReverted {
+		self.Output = append([]byte(nil), output...)
+	}
+	if err != nil {
+		self.Error = err.Error()
+		if err == errors.ErrExecutionReverted {
+			self.RevertReason, _ = web3.DecodeRevert(output)
+		}
+		if self.Type == CREATE || self.Type == CREATE2 {
+			self.To = nil
+		}
+	}
+}
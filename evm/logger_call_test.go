@@ -0,0 +1,104 @@
+package evm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/params"
+	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/evm/storage/overlaydb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEVM(tracer Tracer, codes map[web3.Address]string) (*EVM, StateDB) {
+	statedb := storage.NewStateDB(storage.NewCacheDB(overlaydb.NewOverlayDB(overlaydb.NewMemStore())), web3.Hash{}, web3.Hash{})
+	for addr, code := range codes {
+		raw, _ := hex.DecodeString(code)
+		statedb.CreateAccount(addr)
+		statedb.SetCode(addr, raw)
+	}
+	blockCtx := BlockContext{
+		CanTransfer: func(db StateDB, addr web3.Address, amount *big.Int) bool {
+			return db.GetBalance(addr).Cmp(amount) >= 0
+		},
+		Transfer:    func(db StateDB, from, to web3.Address, amount *big.Int) {},
+		BlockNumber: big.NewInt(0),
+		Time:        big.NewInt(0),
+		Difficulty:  big.NewInt(0),
+	}
+	return NewEVM(blockCtx, TxContext{}, statedb, params.TestChainConfig, Config{Debug: true, Tracer: tracer}), statedb
+}
+
+func TestCallTracer(t *testing.T) {
+	caller := web3.BytesToAddress([]byte{0xca})
+	a, b, c := web3.BytesToAddress([]byte{0xa}), web3.BytesToAddress([]byte{0xb}), web3.BytesToAddress([]byte{0xc})
+	// b reverts with Error("no")
+	revert := "7f08c379a000000000000000000000000000000000000000000000000000000000600052" +
+		"6020600452" + "6002602452" +
+		"7f6e6f000000000000000000000000000000000000000000000000000000000000604452" +
+		"60646000fd"
+	// c returns the word 42
+	ret := "602a60005260206000f3"
+	// a calls b, then calls c with 4 bytes of input
+	call := "6000600060006000600073" + hex.EncodeToString(b[:]) + "5af150" +
+		"6020600060046000600073" + hex.EncodeToString(c[:]) + "5af150" + "00"
+
+	tracer := NewCallTracer()
+	env, _ := newTestEVM(tracer, map[web3.Address]string{a: call, b: revert, c: ret})
+	_, _, err := env.Call(AccountRef(caller), a, []byte{1, 2}, 100000, big.NewInt(0))
+	assert.Nil(t, err)
+
+	res := tracer.Result()
+	assert.Equal(t, CALL, res.Type)
+	assert.Equal(t, a, *res.To)
+	assert.Equal(t, []byte{1, 2}, res.Input)
+	assert.Equal(t, uint64(100000), res.Gas)
+	assert.Equal(t, 2, len(res.Calls))
+
+	reverted := res.Calls[0]
+	assert.Equal(t, b, *reverted.To)
+	assert.Equal(t, "execution reverted", reverted.Error)
+	assert.Equal(t, "no", reverted.RevertReason)
+	assert.Equal(t, uint64(54), reverted.GasUsed)
+
+	returned := res.Calls[1]
+	assert.Equal(t, c, *returned.To)
+	assert.Equal(t, "", returned.Error)
+	assert.Equal(t, make([]byte, 4), returned.Input)
+	assert.Equal(t, web3.BytesToHash([]byte{42}).Bytes(), returned.Output)
+	assert.Equal(t, uint64(18), returned.GasUsed)
+
+	raw, err := json.Marshal(res)
+	assert.Nil(t, err)
+	var decoded CallFrame
+	assert.Nil(t, json.Unmarshal(raw, &decoded))
+	again, err := json.Marshal(&decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, string(raw), string(again))
+
+	var fields map[string]interface{}
+	assert.Nil(t, json.Unmarshal(raw, &fields))
+	assert.Equal(t, "CALL", fields["type"])
+	assert.Equal(t, "0x186a0", fields["gas"])
+	assert.Equal(t, "0x0102", fields["input"])
+}
+
+func TestCallTracerSelfdestruct(t *testing.T) {
+	caller, a := web3.BytesToAddress([]byte{0xca}), web3.BytesToAddress([]byte{0xa})
+	beneficiary := web3.BytesToAddress([]byte{0xbe})
+	// a selfdestructs to the beneficiary, under the EIP-6780 rules of Cancun
+	tracer := NewCallTracer()
+	env, _ := newTestEVM(tracer, map[web3.Address]string{a: "73" + hex.EncodeToString(beneficiary[:]) + "ff"})
+	assert.True(t, env.ChainRules().IsCancun)
+	_, _, err := env.Call(AccountRef(caller), a, nil, 100000, big.NewInt(0))
+	assert.Nil(t, err)
+
+	res := tracer.Result()
+	assert.Equal(t, 1, len(res.Calls))
+	assert.Equal(t, SELFDESTRUCT, res.Calls[0].Type)
+	assert.Equal(t, a, res.Calls[0].From)
+	assert.Equal(t, beneficiary, *res.Calls[0].To)
+}
@@ -0,0 +1,330 @@
+package evm
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/laizy/web3"
+)
+
+// NameResolver resolves the names of the contracts and methods shown in the gas profile,
+// registry.EventRegistry implements it.
+type NameResolver interface {
+	// ContractName returns the name of the contract, empty if unknown
+	ContractName(addr web3.Address) string
+	// MethodSig returns the signature of the method with the 4-byte selector, empty if unknown
+	MethodSig(selector [4]byte) string
+}
+
+// GasFrame is the gas consumed by a call frame
+type GasFrame struct {
+	Type     OpCode
+	Contract web3.Address // address of the executed code
+	Method   string       // method signature, the hex selector if it is not resolved
+	Gas      uint64       // gas given to the frame
+	GasUsed  uint64       // gas used by the frame and its sub calls
+	SelfGas  uint64       // gas used by the frame itself, excluding the sub calls
+	Calls    []*GasFrame
+
+	opGas  uint64 // gas of the opcodes executed in the frame
+	lastOp OpCode
+	hasOp  bool
+}
+
+// GasEntry is the gas aggregated under a name, such as a contract, a method or an opcode
+type GasEntry struct {
+	Name  string
+	Count uint64
+	Gas   uint64
+}
+
+// GasProfile is the gas consumption of the profiled executions aggregated per contract, per
+// method, per opcode, and as a tree of call frames. Gas of sub calls is attributed to the callee.
+type GasProfile struct {
+	GasUsed   uint64 // gas used by the transactions, zero if the transactions are not traced
+	Intrinsic uint64 // intrinsic gas of the transactions
+	Refund    uint64 // gas refunded to the transactions
+	Frames    []*GasFrame
+	Contracts []*GasEntry // sorted by gas in descending order
+	Methods   []*GasEntry // sorted by gas in descending order
+	Opcodes   []*GasEntry // sorted by gas in descending order
+}
+
+type gasCounter map[string]*GasEntry
+
+func (self gasCounter) add(name string, count, gas uint64) {
+	entry := self[name]
+	if entry == nil {
+		entry = &GasEntry{Name: name}
+		self[name] = entry
+	}
+	entry.Count += count
+	entry.Gas += gas
+}
+
+func (self gasCounter) sorted() []*GasEntry {
+	entries := make([]*GasEntry, 0, len(self))
+	for _, entry := range self {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Gas != entries[j].Gas {
+			return entries[i].Gas > entries[j].Gas
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// GasProfiler is a tracer attributing the consumed gas to contracts, methods, call frames and
+// opcodes. It accumulates the profile of every execution it traces until Reset is called.
+type GasProfiler struct {
+	resolver NameResolver
+
+	frames    []*GasFrame
+	callstack []*GasFrame
+	opcodes   gasCounter
+	skipExit  bool
+
+	gasUsed, intrinsic, refund uint64
+	gasLimit, startGas         uint64
+}
+
+// NewGasProfiler creates a gas profiler, names are resolved with the resolver which may be nil
+func NewGasProfiler(resolver NameResolver) *GasProfiler {
+	return &GasProfiler{resolver: resolver, opcodes: make(gasCounter)}
+}
+
+// Reset drops the collected profile
+func (self *GasProfiler) Reset() {
+	self.frames, self.callstack = nil, nil
+	self.opcodes = make(gasCounter)
+	self.gasUsed, self.intrinsic, self.refund = 0, 0, 0
+}
+
+func (self *GasProfiler) CaptureTxStart(gasLimit uint64) {
+	self.gasLimit = gasLimit
+}
+
+func (self *GasProfiler) CaptureTxEnd(restGas uint64) {
+	if len(self.frames) == 0 || self.gasLimit == 0 {
+		return
+	}
+	root := self.frames[len(self.frames)-1]
+	used := self.gasLimit - restGas
+	intrinsic := self.gasLimit - self.startGas
+	self.gasUsed += used
+	self.intrinsic += intrinsic
+	if intrinsic+root.GasUsed > used {
+		self.refund += intrinsic + root.GasUsed - used
+	}
+	self.gasLimit = 0
+}
+
+func (self *GasProfiler) CaptureStart(from web3.Address, to web3.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	typ := CALL
+	if create {
+		typ = CREATE
+	}
+	root := self.newFrame(typ, to, input, gas)
+	self.frames = append(self.frames, root)
+	self.callstack = []*GasFrame{root}
+	self.startGas = gas
+}
+
+func (self *GasProfiler) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack,
+	rStack *ReturnStack, rData []byte, contract *Contract, depth int, err error) {
+	frame := self.current()
+	if frame == nil {
+		return
+	}
+	frame.lastOp, frame.hasOp = op, true
+	if err != nil {
+		// the gas burned by the failure is attributed to the opcode when the frame exits
+		return
+	}
+	switch op {
+	case CALL, CALLCODE, DELEGATECALL, STATICCALL:
+		// the gas forwarded to the callee is attributed to the callee
+		if cost >= env.callGasTemp {
+			cost -= env.callGasTemp
+		}
+	}
+	frame.opGas += cost
+	self.opcodes.add(op.String(), 1, cost)
+}
+
+func (self *GasProfiler) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack,
+	rStack *ReturnStack, contract *Contract, depth int, err error) {
+	if frame := self.current(); frame != nil {
+		frame.lastOp, frame.hasOp = op, true
+	}
+}
+
+func (self *GasProfiler) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) {
+	if len(self.callstack) == 0 {
+		return
+	}
+	self.exit(self.callstack[0], gasUsed)
+	self.callstack = nil
+}
+
+func (self *GasProfiler) CaptureEnter(typ OpCode, from web3.Address, to web3.Address, input []byte, gas uint64, value *big.Int) {
+	if typ == SELFDESTRUCT {
+		self.skipExit = true
+		return
+	}
+	parent := self.current()
+	if parent == nil {
+		return
+	}
+	frame := self.newFrame(typ, to, input, gas)
+	parent.Calls = append(parent.Calls, frame)
+	self.callstack = append(self.callstack, frame)
+}
+
+func (self *GasProfiler) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if self.skipExit {
+		self.skipExit = false
+		return
+	}
+	if len(self.callstack) <= 1 {
+		return
+	}
+	frame := self.callstack[len(self.callstack)-1]
+	self.callstack = self.callstack[:len(self.callstack)-1]
+	self.exit(frame, gasUsed)
+}
+
+func (self *GasProfiler) current() *GasFrame {
+	if len(self.callstack) == 0 {
+		return nil
+	}
+	return self.callstack[len(self.callstack)-1]
+}
+
+func (self *GasProfiler) newFrame(typ OpCode, to web3.Address, input []byte, gas uint64) *GasFrame {
+	frame := &GasFrame{Type: typ, Contract: to, Gas: gas}
+	switch {
+	case typ == CREATE || typ == CREATE2:
+		frame.Method = "constructor"
+	case len(input) < 4:
+		frame.Method = "fallback"
+	default:
+		var selector [4]byte
+		copy(selector[:], input)
+		if self.resolver != nil {
+			frame.Method = self.resolver.MethodSig(selector)
+		}
+		if frame.Method == "" {
+			frame.Method = fmt.Sprintf("0x%x", selector)
+		}
+	}
+	return frame
+}
+
+// exit computes the gas used by the frame itself. The gas not covered by the executed opcodes,
+// which is burned by a failure or paid for the code deposit of a creation, is attributed to the
+// last opcode.
+func (self *GasProfiler) exit(frame *GasFrame, gasUsed uint64) {
+	frame.GasUsed = gasUsed
+	frame.SelfGas = gasUsed
+	for _, call := range frame.Calls {
+		if frame.SelfGas < call.GasUsed {
+			frame.SelfGas = 0
+			break
+		}
+		frame.SelfGas -= call.GasUsed
+	}
+	if frame.hasOp && frame.SelfGas > frame.opGas {
+		self.opcodes.add(frame.lastOp.String(), 0, frame.SelfGas-frame.opGas)
+		frame.opGas = frame.SelfGas
+	}
+}
+
+func (self *GasProfiler) contractName(addr web3.Address) string {
+	if self.resolver != nil {
+		if name := self.resolver.ContractName(addr); name != "" {
+			return name
+		}
+	}
+	return addr.String()
+}
+
+// Profile returns the gas profile of the executions traced so far
+func (self *GasProfiler) Profile() *GasProfile {
+	contracts, methods := make(gasCounter), make(gasCounter)
+	var walk func(frame *GasFrame)
+	walk = func(frame *GasFrame) {
+		name := self.contractName(frame.Contract)
+		contracts.add(name, 1, frame.SelfGas)
+		methods.add(name+"."+frame.Method, 1, frame.SelfGas)
+		for _, call := range frame.Calls {
+			walk(call)
+		}
+	}
+	for _, frame := range self.frames {
+		walk(frame)
+	}
+	return &GasProfile{
+		GasUsed:   self.gasUsed,
+		Intrinsic: self.intrinsic,
+		Refund:    self.refund,
+		Frames:    self.frames,
+		Contracts: contracts.sorted(),
+		Methods:   methods.sorted(),
+		Opcodes:   self.opcodes.sorted(),
+	}
+}
+
+// WriteTable writes the profile as text tables
+func (self *GasProfiler) WriteTable(writer io.Writer) error {
+	profile := self.Profile()
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	if profile.GasUsed != 0 {
+		fmt.Fprintf(w, "gas used\tintrinsic\trefund\t\n%d\t%d\t%d\t\n\n", profile.GasUsed, profile.Intrinsic, profile.Refund)
+	}
+	tables := []struct {
+		title   string
+		entries []*GasEntry
+	}{
+		{"contract", profile.Contracts},
+		{"method", profile.Methods},
+		{"opcode", profile.Opcodes},
+	}
+	for i, table := range tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s\tcount\tgas\t\n", table.title)
+		for _, entry := range table.entries {
+			fmt.Fprintf(w, "%s\t%d\t%d\t\n", entry.Name, entry.Count, entry.Gas)
+		}
+	}
+	return w.Flush()
+}
+
+// WriteFolded writes the gas used by each call frame itself in the folded stack format, which
+// is the input of flamegraph.pl and similar tools.
+func (self *GasProfiler) WriteFolded(writer io.Writer) error {
+	var err error
+	var walk func(stack []string, frame *GasFrame)
+	walk = func(stack []string, frame *GasFrame) {
+		stack = append(stack, self.contractName(frame.Contract)+"."+frame.Method)
+		if frame.SelfGas != 0 && err == nil {
+			_, err = fmt.Fprintf(writer, "%s %d\n", strings.Join(stack, ";"), frame.SelfGas)
+		}
+		for _, call := range frame.Calls {
+			walk(stack, call)
+		}
+	}
+	for _, frame := range self.frames {
+		walk(nil, frame)
+	}
+	return err
+}
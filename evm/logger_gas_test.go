@@ -0,0 +1,63 @@
+package evm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/stretchr/testify/assert"
+)
+
+type testResolver map[web3.Address]string
+
+func (self testResolver) ContractName(addr web3.Address) string { return self[addr] }
+
+func (self testResolver) MethodSig(selector [4]byte) string {
+	if selector == [4]byte{} {
+		return "store()"
+	}
+	return ""
+}
+
+func TestGasProfiler(t *testing.T) {
+	caller := web3.BytesToAddress([]byte{0xca})
+	a, b := web3.BytesToAddress([]byte{0xa}), web3.BytesToAddress([]byte{0xb})
+	// a calls store() of b twice, b returns the word 42 which costs 18 gas
+	call := "6000600060046000600073" + hex.EncodeToString(b[:]) + "5af150"
+	profiler := NewGasProfiler(testResolver{a: "A", b: "B"})
+	env, _ := newTestEVM(profiler, map[web3.Address]string{a: call + call + "00", b: "602a60005260206000f3"})
+	_, left, err := env.Call(AccountRef(caller), a, nil, 100000, big.NewInt(0))
+	assert.Nil(t, err)
+
+	profile := profiler.Profile()
+	assert.Equal(t, 1, len(profile.Frames))
+	root := profile.Frames[0]
+	assert.Equal(t, 100000-left, root.GasUsed)
+	assert.Equal(t, 2, len(root.Calls))
+	assert.Equal(t, root.GasUsed-36, root.SelfGas)
+
+	assert.Equal(t, &GasEntry{Name: "B", Count: 2, Gas: 36}, profile.Contracts[1])
+	assert.Equal(t, &GasEntry{Name: "B.store()", Count: 2, Gas: 36}, profile.Methods[1])
+	assert.Equal(t, "A.fallback", profile.Methods[0].Name)
+	total := uint64(0)
+	for _, entry := range profile.Opcodes {
+		total += entry.Gas
+	}
+	assert.Equal(t, root.GasUsed, total)
+
+	var folded bytes.Buffer
+	assert.Nil(t, profiler.WriteFolded(&folded))
+	lines := strings.Split(strings.TrimSpace(folded.String()), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Equal(t, "A.fallback;B.store() 18", lines[1])
+
+	var table bytes.Buffer
+	assert.Nil(t, profiler.WriteTable(&table))
+	assert.Contains(t, table.String(), "B.store()")
+
+	profiler.Reset()
+	assert.Equal(t, 0, len(profiler.Profile().Frames))
+}
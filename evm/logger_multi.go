@@ -0,0 +1,72 @@
+package evm
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/laizy/web3"
+)
+
+// MultiTracer fans out the tracing events to several tracers, so a call tree, a gas profile and
+// struct logs can be collected in one run. The transaction level events are forwarded to the
+// tracers implementing TxTracer.
+type MultiTracer []Tracer
+
+// NewMultiTracer creates a tracer forwarding every event to the given tracers in order
+func NewMultiTracer(tracers ...Tracer) MultiTracer {
+	return MultiTracer(tracers)
+}
+
+func (self MultiTracer) CaptureTxStart(gasLimit uint64) {
+	for _, t := range self {
+		if tracer, ok := t.(TxTracer); ok {
+			tracer.CaptureTxStart(gasLimit)
+		}
+	}
+}
+
+func (self MultiTracer) CaptureTxEnd(restGas uint64) {
+	for _, t := range self {
+		if tracer, ok := t.(TxTracer); ok {
+			tracer.CaptureTxEnd(restGas)
+		}
+	}
+}
+
+func (self MultiTracer) CaptureStart(from web3.Address, to web3.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	for _, t := range self {
+		t.CaptureStart(from, to, create, input, gas, value)
+	}
+}
+
+func (self MultiTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack,
+	rStack *ReturnStack, rData []byte, contract *Contract, depth int, err error) {
+	for _, t := range self {
+		t.CaptureState(env, pc, op, gas, cost, memory, stack, rStack, rData, contract, depth, err)
+	}
+}
+
+func (self MultiTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack,
+	rStack *ReturnStack, contract *Contract, depth int, err error) {
+	for _, t := range self {
+		t.CaptureFault(env, pc, op, gas, cost, memory, stack, rStack, contract, depth, err)
+	}
+}
+
+func (self MultiTracer) CaptureEnd(output []byte, gasUsed uint64, tm time.Duration, err error) {
+	for _, t := range self {
+		t.CaptureEnd(output, gasUsed, tm, err)
+	}
+}
+
+func (self MultiTracer) CaptureEnter(typ OpCode, from web3.Address, to web3.Address, input []byte, gas uint64, value *big.Int) {
+	for _, t := range self {
+		t.CaptureEnter(typ, from, to, input, gas, value)
+	}
+}
+
+func (self MultiTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	for _, t := range self {
+		t.CaptureExit(output, gasUsed, err)
+	}
+}
@@ -0,0 +1,50 @@
+package evm
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingTracer records the call frame events it receives
+type countingTracer struct {
+	StructLogger
+	enters, exits int
+	gasLimit      uint64
+}
+
+func (self *countingTracer) CaptureEnter(typ OpCode, from web3.Address, to web3.Address, input []byte, gas uint64, value *big.Int) {
+	self.enters++
+}
+
+func (self *countingTracer) CaptureExit(output []byte, gasUsed uint64, err error) { self.exits++ }
+
+func (self *countingTracer) CaptureTxStart(gasLimit uint64) { self.gasLimit = gasLimit }
+func (self *countingTracer) CaptureTxEnd(restGas uint64)    {}
+
+func TestMultiTracer(t *testing.T) {
+	caller := web3.BytesToAddress([]byte{0xca})
+	a, b := web3.BytesToAddress([]byte{0xa}), web3.BytesToAddress([]byte{0xb})
+	// a calls b twice, b stops immediately
+	call := "6000600060006000600073" + hex.EncodeToString(b[:]) + "5af150"
+	counter := &countingTracer{StructLogger: *NewStructLogger(nil)}
+	calls := NewCallTracer()
+	logs := NewStructLogger(nil)
+	tracer := NewMultiTracer(calls, logs, counter)
+
+	env, _ := newTestEVM(tracer, map[web3.Address]string{a: call + call + "00", b: "00"})
+	tracer.CaptureTxStart(100000)
+	_, _, err := env.Call(AccountRef(caller), a, nil, 100000, big.NewInt(0))
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, len(calls.Result().Calls))
+	assert.Equal(t, 2, counter.enters)
+	assert.Equal(t, 2, counter.exits)
+	assert.Equal(t, uint64(100000), counter.gasLimit)
+	// 2 * 9 opcodes of a, 2 STOPs of b and the last STOP of a
+	assert.Equal(t, 21, len(logs.StructLogs()))
+	assert.Equal(t, len(logs.StructLogs()), len(counter.StructLogs()))
+}
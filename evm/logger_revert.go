@@ -0,0 +1,202 @@
+package evm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/laizy/web3/evm/errors"
+	"github.com/laizy/web3/sourcemap"
+)
+
+// StackFrame is a frame of the stack trace of a failed execution
+type StackFrame struct {
+	Address  web3.Address // address of the executed code
+	Contract string       // name of the matched contract, empty if the code is unknown
+	PC       uint64
+	Op       OpCode
+	Position *sourcemap.Position // nil if the instruction is not mapped to a source file
+}
+
+func (self *StackFrame) String() string {
+	name := self.Contract
+	if name == "" {
+		name = self.Address.String()
+	}
+	if self.Position == nil {
+		return fmt.Sprintf("%s (pc %d, %s)", name, self.PC, self.Op)
+	}
+	return fmt.Sprintf("%s (%s) %s", name, self.Position, self.Position.Snippet)
+}
+
+type revertFrame struct {
+	address  web3.Address
+	contract *sourcemap.Contract
+	resolved bool
+	pc       uint64
+	op       OpCode
+	// stack trace and revert data of the last failed sub call
+	failed       []*StackFrame
+	failedOutput []byte
+}
+
+// RevertTracer resolves the failing instruction of each call frame of a failed execution to the
+// solidity source, it implements Tracer. Contracts are matched by the address of the code
+// first, then by the hash of the code, which differs from the compiled code if the contract has
+// immutables or linked libraries.
+type RevertTracer struct {
+	byAddress map[web3.Address]*sourcemap.Contract
+	byCode    map[web3.Hash]*sourcemap.Contract
+
+	callstack []*revertFrame
+	trace     []*StackFrame
+	err       error
+	reason    string
+}
+
+// NewRevertTracer creates a tracer resolving stack traces of failed executions
+func NewRevertTracer() *RevertTracer {
+	return &RevertTracer{
+		byAddress: make(map[web3.Address]*sourcemap.Contract),
+		byCode:    make(map[web3.Hash]*sourcemap.Contract),
+	}
+}
+
+// AddContract registers the contract, which is matched by the hash of its code
+func (self *RevertTracer) AddContract(contracts ...*sourcemap.Contract) {
+	for _, c := range contracts {
+		if c != nil {
+			self.byCode[crypto.Keccak256Hash(c.Code)] = c
+		}
+	}
+}
+
+// AddDeployed registers the runtime code of the contract deployed at addr
+func (self *RevertTracer) AddDeployed(addr web3.Address, c *sourcemap.Contract) {
+	self.byAddress[addr] = c
+}
+
+// StackTrace returns the stack trace of the last failed execution, starting from the innermost
+// frame, nil if the execution succeeded.
+func (self *RevertTracer) StackTrace() []*StackFrame {
+	return self.trace
+}
+
+// Error returns the error of the last execution
+func (self *RevertTracer) Error() error {
+	return self.err
+}
+
+// Report formats the error and the stack trace of the last execution, empty if it succeeded
+func (self *RevertTracer) Report() string {
+	if self.err == nil {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString("Error: " + self.err.Error())
+	if self.reason != "" {
+		buf.WriteString(": " + self.reason)
+	}
+	buf.WriteString("\n")
+	for _, frame := range self.trace {
+		buf.WriteString("  at " + frame.String() + "\n")
+	}
+	return buf.String()
+}
+
+func (self *RevertTracer) CaptureStart(from web3.Address, to web3.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	self.callstack = []*revertFrame{{address: to}}
+	self.trace, self.err, self.reason = nil, nil, ""
+}
+
+func (self *RevertTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack,
+	rStack *ReturnStack, rData []byte, contract *Contract, depth int, err error) {
+	frame := self.current()
+	if frame == nil {
+		return
+	}
+	frame.pc, frame.op = pc, op
+	if !frame.resolved {
+		frame.resolved = true
+		if contract.CodeAddr != nil {
+			frame.address = *contract.CodeAddr
+		}
+		frame.contract = self.byAddress[frame.address]
+		if frame.contract == nil {
+			hash := contract.CodeHash
+			if hash == (web3.Hash{}) {
+				hash = crypto.Keccak256Hash(contract.Code)
+			}
+			frame.contract = self.byCode[hash]
+		}
+	}
+}
+
+func (self *RevertTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack,
+	rStack *ReturnStack, contract *Contract, depth int, err error) {
+}
+
+func (self *RevertTracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) {
+	if len(self.callstack) == 0 {
+		return
+	}
+	if err != nil {
+		self.trace = self.stackTrace(self.callstack[0], output)
+		self.err = err
+		if err == errors.ErrExecutionReverted {
+			self.reason, _ = web3.DecodeRevert(output)
+		}
+	}
+	self.callstack = nil
+}
+
+func (self *RevertTracer) CaptureEnter(typ OpCode, from web3.Address, to web3.Address, input []byte, gas uint64, value *big.Int) {
+	if parent := self.current(); parent != nil {
+		parent.failed, parent.failedOutput = nil, nil
+	}
+	self.callstack = append(self.callstack, &revertFrame{address: to})
+}
+
+func (self *RevertTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(self.callstack) <= 1 {
+		return
+	}
+	frame := self.callstack[len(self.callstack)-1]
+	self.callstack = self.callstack[:len(self.callstack)-1]
+	if err == nil {
+		return
+	}
+	// the caller is still at the call instruction
+	parent := self.current()
+	parent.failed = append(self.stackTrace(frame, output), self.stackFrame(parent))
+	parent.failedOutput = append([]byte(nil), output...)
+}
+
+func (self *RevertTracer) current() *revertFrame {
+	if len(self.callstack) == 0 {
+		return nil
+	}
+	return self.callstack[len(self.callstack)-1]
+}
+
+// stackTrace returns the stack trace of the failed frame. The trace of the failed sub call is
+// kept if the frame bubbles up its revert data.
+func (self *RevertTracer) stackTrace(frame *revertFrame, output []byte) []*StackFrame {
+	if frame.failed != nil && bytes.Equal(frame.failedOutput, output) {
+		return frame.failed
+	}
+	return []*StackFrame{self.stackFrame(frame)}
+}
+
+func (self *RevertTracer) stackFrame(frame *revertFrame) *StackFrame {
+	res := &StackFrame{Address: frame.address, PC: frame.pc, Op: frame.op}
+	if frame.contract != nil {
+		res.Contract = frame.contract.Name
+		res.Position, _ = frame.contract.Position(frame.pc)
+	}
+	return res
+}
@@ -0,0 +1,78 @@
+package evm
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/errors"
+	"github.com/laizy/web3/sourcemap"
+	"github.com/stretchr/testify/assert"
+)
+
+const revertSource = `contract A {
+    function f() public {
+        b.g();
+    }
+}
+contract B {
+    function g() public {
+        require(false, "no");
+    }
+}
+`
+
+func TestRevertTracer(t *testing.T) {
+	caller, addrA, addrB := web3.BytesToAddress([]byte{1}), web3.BytesToAddress([]byte{0xa}), web3.BytesToAddress([]byte{0xb})
+	// a: PUSH1 0, CALL, REVERT. b: PUSH1 0, REVERT
+	// the source maps point at "contract A", "b.g()", "b.g()" and "contract B", `require(false, "no")`
+	sources := []sourcemap.Source{{Name: "test.sol", Content: revertSource}}
+	a, err := sourcemap.NewContract("A", []byte{0x60, 0x00, 0xf1, 0xfd}, "0:10:0;47:5:0;47:5:0", sources)
+	assert.Nil(t, err)
+	b, err := sourcemap.NewContract("B", []byte{0x60, 0x00, 0xfd}, "62:10:0;109:20:0", sources)
+	assert.Nil(t, err)
+	tracer := NewRevertTracer()
+	tracer.AddContract(b)
+	tracer.AddDeployed(addrA, a)
+
+	contractA := NewContract(AccountRef(caller), AccountRef(addrA), big.NewInt(0), 0)
+	contractA.SetCallCode(&addrA, web3.Hash{}, a.Code)
+	contractB := NewContract(AccountRef(addrA), AccountRef(addrB), big.NewInt(0), 0)
+	contractB.SetCallCode(&addrB, web3.Hash{}, b.Code)
+	revert, _ := hex.DecodeString("08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000002" +
+		"6e6f000000000000000000000000000000000000000000000000000000000000")
+
+	step := func(c *Contract, pc uint64, op OpCode) {
+		tracer.CaptureState(nil, pc, op, 0, 0, nil, nil, nil, nil, c, 0, nil)
+	}
+	tracer.CaptureStart(caller, addrA, false, nil, 0, big.NewInt(0))
+	step(contractA, 0, PUSH1)
+	step(contractA, 2, CALL)
+	tracer.CaptureEnter(CALL, addrA, addrB, nil, 0, big.NewInt(0))
+	step(contractB, 0, PUSH1)
+	step(contractB, 2, REVERT)
+	tracer.CaptureExit(revert, 0, errors.ErrExecutionReverted)
+	step(contractA, 3, REVERT)
+	tracer.CaptureEnd(revert, 0, 0, errors.ErrExecutionReverted)
+
+	trace := tracer.StackTrace()
+	assert.Equal(t, 2, len(trace))
+	assert.Equal(t, "B", trace[0].Contract)
+	assert.Equal(t, &sourcemap.Position{File: "test.sol", Line: 8, Column: 9, Snippet: `require(false, "no")`}, trace[0].Position)
+	assert.Equal(t, "A", trace[1].Contract)
+	assert.Equal(t, uint64(2), trace[1].PC)
+	assert.Equal(t, 3, trace[1].Position.Line)
+	assert.Equal(t, "Error: execution reverted: no\n"+
+		"  at B (test.sol:8:9) require(false, \"no\")\n"+
+		"  at A (test.sol:3:9) b.g()\n", tracer.Report())
+
+	// a new execution resets the trace
+	tracer.CaptureStart(caller, addrA, false, nil, 0, big.NewInt(0))
+	step(contractA, 0, PUSH1)
+	tracer.CaptureEnd(nil, 0, 0, nil)
+	assert.Nil(t, tracer.StackTrace())
+	assert.Equal(t, "", tracer.Report())
+}
@@ -97,3 +97,69 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestTimestampForks(t *testing.T) {
+	shanghai, cancun := uint64(100), uint64(200)
+	config := &ChainConfig{
+		BerlinBlock:  big.NewInt(0),
+		LondonBlock:  big.NewInt(10),
+		ShanghaiTime: &shanghai,
+		CancunTime:   &cancun,
+	}
+	tests := []struct {
+		block, time                      uint64
+		berlin, london, shanghai, cancun bool
+	}{
+		{0, 0, true, false, false, false},
+		{10, 99, true, true, false, false},
+		{10, 100, true, true, true, false},
+		{10, 200, true, true, true, true},
+		// time based forks are not active before london
+		{5, 300, true, false, false, false},
+	}
+	for i, test := range tests {
+		rules := config.RulesAt(new(big.Int).SetUint64(test.block), test.time)
+		if rules.IsBerlin != test.berlin || rules.IsLondon != test.london ||
+			rules.IsShanghai != test.shanghai || rules.IsCancun != test.cancun {
+			t.Errorf("test %d: unexpected rules %+v", i, rules)
+		}
+	}
+}
+
+func TestGetChainConfig(t *testing.T) {
+	tests := []struct {
+		chainID, block, time             uint64
+		berlin, london, shanghai, cancun bool
+	}{
+		// mainnet
+		{1, 12243999, 0, false, false, false, false},
+		{1, 12965000, 1628166822, true, true, false, false},
+		{1, 17034870, 1681338455, true, true, true, false},
+		{1, 19426587, 1710338135, true, true, true, true},
+		// sepolia
+		{11155111, 0, 1677557087, true, true, false, false},
+		{11155111, 5187023, 1706655072, true, true, true, true},
+		// devnets have every fork from the genesis
+		{1337, 0, 0, true, true, true, true},
+	}
+	for i, test := range tests {
+		rules := GetChainConfig(test.chainID).RulesAt(new(big.Int).SetUint64(test.block), test.time)
+		if rules.IsBerlin != test.berlin || rules.IsLondon != test.london ||
+			rules.IsShanghai != test.shanghai || rules.IsCancun != test.cancun {
+			t.Errorf("test %d: unexpected rules %+v", i, rules)
+		}
+	}
+
+	// the schedule of a devnet can be overridden
+	config := DevChainConfig(31337)
+	config.CancunTime = nil
+	SetChainConfig(config)
+	defer func() {
+		chainConfigsLock.Lock()
+		delete(chainConfigs, 31337)
+		chainConfigsLock.Unlock()
+	}()
+	if rules := GetChainConfig(31337).RulesAt(big.NewInt(1), 100); !rules.IsShanghai || rules.IsCancun {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+}
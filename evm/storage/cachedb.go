@@ -31,6 +31,14 @@ type CacheDB struct {
 	memdb      *overlaydb.MemDB
 	backend    *overlaydb.OverlayDB
 	keyScratch []byte
+	// changes records the previous value of every write to memdb, so writes can be undone by revertTo
+	changes []cacheChange
+}
+
+type cacheChange struct {
+	key     []byte
+	prev    []byte
+	unknown bool
 }
 
 const initCap = 1024
@@ -50,6 +58,7 @@ func (self *CacheDB) SetDbErr(err error) {
 
 func (self *CacheDB) Reset() {
 	self.memdb.Reset()
+	self.changes = self.changes[:0]
 }
 
 func ensureBuffer(b []byte, n int) []byte {
@@ -76,6 +85,7 @@ func (self *CacheDB) Commit() {
 		}
 	})
 	self.memdb.Reset()
+	self.changes = self.changes[:0]
 }
 
 func (self *CacheDB) Put(key []byte, value []byte) {
@@ -93,9 +103,38 @@ func (self *CacheDB) GenAccountStateKey(addr web3.Address, key []byte) []byte {
 
 func (self *CacheDB) put(prefix schema.DataEntryPrefix, key []byte, value []byte) {
 	self.keyScratch = makePrefixedKey(self.keyScratch, byte(prefix), key)
+	self.journal(self.keyScratch)
 	self.memdb.Put(self.keyScratch, value)
 }
 
+// journal records the current value of the key in memdb before it is overwritten
+func (self *CacheDB) journal(key []byte) {
+	prev, unknown := self.memdb.Get(key)
+	self.changes = append(self.changes, cacheChange{
+		key:     append([]byte{}, key...),
+		prev:    append([]byte{}, prev...),
+		unknown: unknown,
+	})
+}
+
+// snapshot returns the position of the write journal
+func (self *CacheDB) snapshot() int {
+	return len(self.changes)
+}
+
+// revertTo undoes the writes after the given journal position in reverse order
+func (self *CacheDB) revertTo(pos int) {
+	for i := len(self.changes) - 1; i >= pos; i-- {
+		change := self.changes[i]
+		if change.unknown {
+			self.memdb.Remove(change.key)
+		} else {
+			self.memdb.Put(change.key, change.prev)
+		}
+	}
+	self.changes = self.changes[:pos]
+}
+
 func (self *CacheDB) Get(key []byte) ([]byte, error) {
 	return self.get(schema.ST_STORAGE, key)
 }
@@ -121,6 +160,7 @@ func (self *CacheDB) Delete(key []byte) {
 // Delete item from cache
 func (self *CacheDB) delete(prefix schema.DataEntryPrefix, key []byte) {
 	self.keyScratch = makePrefixedKey(self.keyScratch, byte(prefix), key)
+	self.journal(self.keyScratch)
 	self.memdb.Delete(self.keyScratch)
 }
 
@@ -128,11 +168,17 @@ func (self *CacheDB) NewIterator(key []byte) schema.StoreIterator {
 	pkey := make([]byte, 1+len(key))
 	pkey[0] = byte(schema.ST_STORAGE)
 	copy(pkey[1:], key)
+
+	return &Iter{self.newJoinIter(pkey)}
+}
+
+// newJoinIter iterates over the entries with the prefixed key of the cache merged with the backend
+func (self *CacheDB) newJoinIter(pkey []byte) *overlaydb.JoinIter {
 	prefixRange := util.BytesPrefix(pkey)
 	backIter := self.backend.NewIterator(pkey)
 	memIter := self.memdb.NewIterator(prefixRange)
 
-	return &Iter{overlaydb.NewJoinIter(memIter, backIter)}
+	return overlaydb.NewJoinIter(memIter, backIter)
 }
 
 type Iter struct {
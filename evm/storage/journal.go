@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"github.com/laizy/web3"
+)
+
+// journalEntry is a modification of the per transaction state of StateDB which can be reverted,
+// the writes to the cache db are journaled by CacheDB itself.
+type journalEntry interface {
+	revert(*StateDB)
+}
+
+// journal is the list of state modifications since the last commit, RevertToSnapshot replays it
+// backwards instead of restoring a copy of the state taken by every Snapshot.
+type journal struct {
+	entries []journalEntry
+}
+
+func (self *journal) append(entry journalEntry) {
+	self.entries = append(self.entries, entry)
+}
+
+func (self *journal) length() int {
+	return len(self.entries)
+}
+
+// revert undoes the modifications after the given journal position in reverse order
+func (self *journal) revert(statedb *StateDB, pos int) {
+	for i := len(self.entries) - 1; i >= pos; i-- {
+		self.entries[i].revert(statedb)
+		self.entries[i] = nil
+	}
+	self.entries = self.entries[:pos]
+}
+
+func (self *journal) reset() {
+	self.entries = self.entries[:0]
+}
+
+type (
+	suicideChange struct {
+		addr web3.Address
+	}
+	createChange struct {
+		addr web3.Address
+	}
+	accessListAddAccountChange struct {
+		addr web3.Address
+	}
+	accessListAddSlotChange struct {
+		addr web3.Address
+		slot web3.Hash
+	}
+	transientStorageChange struct {
+		addr      web3.Address
+		key, prev web3.Hash
+	}
+)
+
+func (self suicideChange) revert(s *StateDB) {
+	delete(s.Suicided, self.addr)
+}
+
+func (self createChange) revert(s *StateDB) {
+	delete(s.created, self.addr)
+}
+
+func (self accessListAddAccountChange) revert(s *StateDB) {
+	s.accessList.DeleteAddress(self.addr)
+}
+
+func (self accessListAddSlotChange) revert(s *StateDB) {
+	s.accessList.DeleteSlot(self.addr, self.slot)
+}
+
+func (self transientStorageChange) revert(s *StateDB) {
+	s.transientStorage.Set(self.addr, self.key, self.prev)
+}
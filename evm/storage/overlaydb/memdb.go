@@ -336,6 +336,23 @@ func (p *MemDB) Delete(key []byte) {
 	p.Put(key, nil)
 }
 
+// Remove removes the entry of the given key, unlike Delete the key becomes unknown
+// instead of deleted. It returns false if the MemDB does not contain the key.
+func (p *MemDB) Remove(key []byte) bool {
+	node, exact := p.findGE(key, true)
+	if !exact {
+		return false
+	}
+	h := p.nodeData[node+nHeight]
+	for i, n := range p.prevNode[:h] {
+		m := n + nNext + i
+		p.nodeData[m] = p.nodeData[p.nodeData[m]+nNext+i]
+	}
+	p.kvSize -= p.nodeData[node+nKey] + p.nodeData[node+nVal]
+	p.n--
+	return true
+}
+
 // Get gets the value for the given key. It returns unkown == true if the
 // MemDB does not contain the key. It returns nil, false if MemDB has deleted the key
 //
@@ -36,3 +36,31 @@ func TestIter(t *testing.T) {
 	assert.Equal(t, iter.Last(), true)
 	assert.Equal(t, len(iter.Value()), 0)
 }
+
+func TestRemove(t *testing.T) {
+	db := NewMemDB(0, 0)
+	for _, k := range []string{"a", "b", "c"} {
+		db.Put([]byte(k), []byte(k))
+	}
+	db.Delete([]byte("c"))
+
+	assert.True(t, db.Remove([]byte("b")))
+	assert.False(t, db.Remove([]byte("b")))
+	_, unknown := db.Get([]byte("b"))
+	assert.True(t, unknown)
+	assert.Equal(t, 2, db.Len())
+
+	assert.True(t, db.Remove([]byte("c")))
+	_, unknown = db.Get([]byte("c"))
+	assert.True(t, unknown)
+
+	var keys []string
+	db.ForEach(func(key, val []byte) {
+		keys = append(keys, string(key))
+	})
+	assert.Equal(t, []string{"a"}, keys)
+
+	db.Put([]byte("b"), []byte("b2"))
+	val, _ := db.Get([]byte("b"))
+	assert.Equal(t, []byte("b2"), val)
+}
@@ -0,0 +1,29 @@
+package overlaydb
+
+import (
+	"github.com/laizy/web3/evm/storage/schema"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// MemStore is a schema.PersistStore kept in memory, mostly useful for tests
+type MemStore struct {
+	db *MemDB
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{db: NewMemDB(0, 0)}
+}
+
+func (self *MemStore) Get(key []byte) ([]byte, error) {
+	val, unknown := self.db.Get(key)
+	if unknown {
+		return nil, schema.ErrNotFound
+	}
+	return val, nil
+}
+
+func (self *MemStore) BatchPut(key []byte, value []byte) { self.db.Put(key, value) }
+func (self *MemStore) BatchDelete(key []byte)            { self.db.Delete(key) }
+func (self *MemStore) NewIterator(prefix []byte) schema.StoreIterator {
+	return self.db.NewIterator(util.BytesPrefix(prefix))
+}
@@ -96,6 +96,11 @@ func (self *OverlayDB) GetWriteSet() *MemDB {
 	return self.memdb
 }
 
+// SetWriteSet replaces the write set, used to roll back to a clone taken by GetWriteSet().DeepClone()
+func (self *OverlayDB) SetWriteSet(memdb *MemDB) {
+	self.memdb = memdb
+}
+
 func (self *OverlayDB) ChangeHash() web3.Hash {
 	stateDiff := sha256.New()
 	self.memdb.ForEach(func(key, val []byte) {
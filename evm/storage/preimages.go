@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/laizy/web3"
+)
+
+// maxSlotOffset is the largest offset from a hashed slot searched when resolving a storage slot,
+// it covers the fields of structs and the first elements of dynamic arrays.
+const maxSlotOffset = 256
+
+// Preimages records the preimages of the keccak256 hashes computed by the SHA3 opcode, which
+// allows mapping storage slots back to the mapping keys that produced them. Preimages are kept
+// even if the execution producing them is reverted.
+type Preimages struct {
+	lock    sync.RWMutex
+	entries map[web3.Hash][]byte
+}
+
+// NewPreimages creates an empty preimage store
+func NewPreimages() *Preimages {
+	return &Preimages{entries: make(map[web3.Hash][]byte)}
+}
+
+// Add records the preimage of the hash
+func (self *Preimages) Add(hash web3.Hash, preimage []byte) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if _, ok := self.entries[hash]; !ok {
+		self.entries[hash] = append([]byte{}, preimage...)
+	}
+}
+
+// Get returns the preimage of the hash, nil if unknown
+func (self *Preimages) Get(hash web3.Hash) []byte {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return self.entries[hash]
+}
+
+// Len returns the number of recorded preimages
+func (self *Preimages) Len() int {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return len(self.entries)
+}
+
+// SlotStep is one level of the derivation of a storage slot, the slot is keccak256(Key . parent)
+// for a mapping or keccak256(parent) for a dynamic array, plus Offset.
+type SlotStep struct {
+	Key    []byte // the 32 bytes mapping key, nil for a dynamic array
+	Offset uint64 // the struct field or array element offset from the hashed slot
+}
+
+// SlotLabel describes how a storage slot is derived from the slot of a state variable
+type SlotLabel struct {
+	Base web3.Hash  // the slot of the state variable
+	Path []SlotStep // outermost first
+}
+
+// ResolveSlot maps the storage slot back to the state variable slot and the mapping keys or
+// array offsets it is derived from. A slot without known preimage resolves to itself as base.
+func (self *Preimages) ResolveSlot(slot web3.Hash) *SlotLabel {
+	label := &SlotLabel{Base: slot}
+	for {
+		preimage, offset := self.findPreimage(label.Base)
+		var step SlotStep
+		switch len(preimage) {
+		case 64:
+			step = SlotStep{Key: preimage[:32], Offset: offset}
+			label.Base = web3.BytesToHash(preimage[32:])
+		case 32:
+			step = SlotStep{Offset: offset}
+			label.Base = web3.BytesToHash(preimage)
+		default:
+			return label
+		}
+		label.Path = append([]SlotStep{step}, label.Path...)
+	}
+}
+
+// findPreimage looks for a preimage of the slot or of one of the maxSlotOffset slots before it
+func (self *Preimages) findPreimage(slot web3.Hash) ([]byte, uint64) {
+	val := new(big.Int).SetBytes(slot[:])
+	one := big.NewInt(1)
+	for offset := uint64(0); offset <= maxSlotOffset && val.Sign() >= 0; offset++ {
+		if preimage := self.Get(web3.BytesToHash(val.Bytes())); len(preimage) == 32 || len(preimage) == 64 {
+			return preimage, offset
+		}
+		val.Sub(val, one)
+	}
+	return nil, 0
+}
+
+// String formats the label with the base slot as name, e.g. 0x1[0xabc]
+func (self *SlotLabel) String() string {
+	return self.Format(nil)
+}
+
+// Format formats the label with the name of the state variable, e.g. balances[0xabc] for the
+// balance of 0xabc in the mapping at the slot of balances. Struct fields and array elements are
+// shown as an offset, e.g. users[0x1].+2 or list[].+3. Bases missing in names are shown as slots.
+func (self *SlotLabel) Format(names map[web3.Hash]string) string {
+	var sb strings.Builder
+	if name, ok := names[self.Base]; ok {
+		sb.WriteString(name)
+	} else {
+		sb.WriteString(trimHex(self.Base[:]))
+	}
+	for _, step := range self.Path {
+		if step.Key != nil {
+			sb.WriteString("[" + trimHex(step.Key) + "]")
+		} else {
+			sb.WriteString("[]")
+		}
+		if step.Offset != 0 {
+			fmt.Fprintf(&sb, ".+%d", step.Offset)
+		}
+	}
+	return sb.String()
+}
+
+// trimHex formats the bytes as hex without leading zeros
+func trimHex(b []byte) string {
+	str := strings.TrimLeft(hex.EncodeToString(b), "0")
+	if str == "" {
+		str = "0"
+	}
+	return "0x" + str
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSlot(t *testing.T) {
+	preimages := NewPreimages()
+	hash := func(data ...[]byte) web3.Hash {
+		var preimage []byte
+		for _, d := range data {
+			preimage = append(preimage, d...)
+		}
+		h := crypto.Keccak256Hash(preimage)
+		preimages.Add(h, preimage)
+		return h
+	}
+	add := func(h web3.Hash, offset int64) web3.Hash {
+		val := new(big.Int).SetBytes(h[:])
+		return web3.BytesToHash(val.Add(val, big.NewInt(offset)).Bytes())
+	}
+	slot := func(i byte) web3.Hash { return web3.BytesToHash([]byte{i}) }
+	owner := web3.BytesToHash(web3.HexToAddress("0x00000000000000000000000000000000000000ab").Bytes())
+	spender := web3.BytesToHash(web3.HexToAddress("0x00000000000000000000000000000000000000cd").Bytes())
+	names := map[web3.Hash]string{slot(3): "balances", slot(4): "allowance", slot(5): "users", slot(6): "list"}
+
+	// balances[owner]
+	label := preimages.ResolveSlot(hash(owner[:], slot(3).Bytes()))
+	assert.Equal(t, slot(3), label.Base)
+	assert.Equal(t, "balances[0xab]", label.Format(names))
+
+	// allowance[owner][spender]
+	label = preimages.ResolveSlot(hash(spender[:], hash(owner[:], slot(4).Bytes()).Bytes()))
+	assert.Equal(t, "allowance[0xab][0xcd]", label.Format(names))
+
+	// users[owner].field at offset 2
+	label = preimages.ResolveSlot(add(hash(owner[:], slot(5).Bytes()), 2))
+	assert.Equal(t, []SlotStep{{Key: owner[:], Offset: 2}}, label.Path)
+	assert.Equal(t, "users[0xab].+2", label.Format(names))
+
+	// list[7] of a dynamic array
+	label = preimages.ResolveSlot(add(hash(slot(6).Bytes()), 7))
+	assert.Equal(t, "list[].+7", label.Format(names))
+
+	// plain state variables resolve to themselves
+	label = preimages.ResolveSlot(slot(1))
+	assert.Equal(t, slot(1), label.Base)
+	assert.Empty(t, label.Path)
+	assert.Equal(t, "0x1", label.String())
+}
+
+func TestAddPreimage(t *testing.T) {
+	statedb := newTestStateDB()
+	data := []byte{1, 2, 3}
+	statedb.AddPreimage(crypto.Keccak256Hash(data), data)
+
+	statedb.Preimages = NewPreimages()
+	statedb.AddPreimage(crypto.Keccak256Hash(data), data)
+	data[0] = 0xff // the evm reuses its memory
+	assert.Equal(t, []byte{1, 2, 3}, statedb.Preimages.Get(crypto.Keccak256Hash([]byte{1, 2, 3})))
+	assert.Equal(t, 1, statedb.Preimages.Len())
+}
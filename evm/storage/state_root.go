@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"bytes"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/laizy/web3/evm/storage/schema"
+	"github.com/laizy/web3/trie"
+	"github.com/laizy/web3/utils/codec"
+	"github.com/umbracle/fastrlp"
+)
+
+// emptyCodeHash is the code hash of accounts without code, stored as the zero hash in EthAccount
+var emptyCodeHash = crypto.Keccak256Hash(nil)
+
+// StateRoot computes the root of the state trie over all the accounts of the cache merged with
+// its backend. The root only matches the chain when the backend holds the full state. Empty
+// accounts, e.g. the missing accounts cached by a remote backend, are not part of the state
+// (EIP-161).
+func (self *CacheDB) StateRoot() (web3.Hash, error) {
+	accounts := trie.NewSecureTrie()
+	ar := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(ar)
+
+	iter := self.newJoinIter([]byte{byte(schema.ST_ETH_ACCOUNT)})
+	defer iter.Release()
+	for has := iter.First(); has; has = iter.Next() {
+		addr := web3.BytesToAddress(iter.Key()[1:])
+		var acct EthAccount
+		if err := acct.Deserialization(codec.NewZeroCopySource(iter.Value())); err != nil {
+			return web3.Hash{}, err
+		}
+		root, err := self.StorageRoot(addr)
+		if err != nil {
+			return web3.Hash{}, err
+		}
+		if acct.IsEmpty() && root == trie.EmptyRoot {
+			continue
+		}
+		codeHash := acct.CodeHash
+		if codeHash == (web3.Hash{}) {
+			codeHash = emptyCodeHash
+		}
+
+		ar.Reset()
+		vv := ar.NewArray()
+		vv.Set(ar.NewUint(acct.Nonce))
+		vv.Set(ar.NewBigInt(acct.Balance.ToBig()))
+		vv.Set(ar.NewCopyBytes(root[:]))
+		vv.Set(ar.NewCopyBytes(codeHash[:]))
+		accounts.Update(addr[:], vv.MarshalTo(nil))
+	}
+	if err := iter.Error(); err != nil {
+		return web3.Hash{}, err
+	}
+
+	return accounts.Hash(), nil
+}
+
+// StorageRoot computes the root of the storage trie of the contract
+func (self *CacheDB) StorageRoot(addr web3.Address) (web3.Hash, error) {
+	slots := trie.NewSecureTrie()
+	ar := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(ar)
+
+	iter := self.NewIterator(addr[:])
+	defer iter.Release()
+	for has := iter.First(); has; has = iter.Next() {
+		// values are stored as 32 bytes, the trie holds the rlp of the value without leading zeros
+		val := bytes.TrimLeft(iter.Value(), "\x00")
+		if len(val) == 0 {
+			continue
+		}
+		ar.Reset()
+		slots.Update(iter.Key()[web3.AddressLength:], ar.NewCopyBytes(val).MarshalTo(nil))
+	}
+	if err := iter.Error(); err != nil {
+		return web3.Hash{}, err
+	}
+
+	return slots.Hash(), nil
+}
@@ -25,7 +25,7 @@ import (
 
 	"github.com/laizy/web3"
 	"github.com/laizy/web3/crypto"
-	"github.com/laizy/web3/evm/storage/overlaydb"
+	"github.com/laizy/web3/evm/params"
 	"github.com/laizy/web3/evm/storage/schema"
 	"github.com/laizy/web3/utils/codec"
 	"github.com/laizy/web3/utils/common/hexutil"
@@ -93,8 +93,16 @@ type StateDB struct {
 	thash, bhash  web3.Hash
 	txIndex       int
 	refund        uint64
-	snapshots     []*snapshot
+	journal       journal
+	snapshots     []snapshot
 	BalanceHandle BalanceHandle
+	// Preimages records the SHA3 preimages when preimage recording is enabled in the evm config
+	Preimages *Preimages
+
+	// per transaction state, reset by PrepareAccessList
+	accessList       *accessList
+	transientStorage transientStorage
+	created          map[web3.Address]bool
 }
 
 func NewStateDB(cacheDB *CacheDB, thash, bhash web3.Hash) *StateDB {
@@ -107,19 +115,108 @@ func NewStateDB(cacheDB *CacheDB, thash, bhash web3.Hash) *StateDB {
 		refund:        0,
 		snapshots:     nil,
 		BalanceHandle: &balanceHandle{},
+
+		accessList:       newAccessList(),
+		transientStorage: newTransientStorage(),
+		created:          make(map[web3.Address]bool),
 	}
 }
 
 func (self *StateDB) Prepare(thash, bhash web3.Hash) {
 	self.thash = thash
 	self.bhash = bhash
-	//	s.accessList = newAccessList()
+}
+
+// PrepareAccessList handles the preparatory steps for executing a state transition with
+// regards to EIP-2929, EIP-2930, EIP-3651 and EIP-1153:
+//
+// - reset the access list and transient storage
+// - add sender, destination, precompiles and the tx access list to the access list (Berlin)
+// - add coinbase to the access list (Shanghai)
+func (self *StateDB) PrepareAccessList(rules params.Rules, sender, coinbase web3.Address, dst *web3.Address, precompiles []web3.Address, list web3.AccessList) {
+	self.journal.reset()
+	self.snapshots = self.snapshots[:0]
+	self.accessList = newAccessList()
+	self.transientStorage = newTransientStorage()
+	self.created = make(map[web3.Address]bool)
+	if !rules.IsBerlin {
+		return
+	}
+	self.AddAddressToAccessList(sender)
+	if dst != nil {
+		self.AddAddressToAccessList(*dst)
+		// If it's a create-tx, the destination will be added inside evm.create
+	}
+	for _, addr := range precompiles {
+		self.AddAddressToAccessList(addr)
+	}
+	for _, el := range list {
+		self.AddAddressToAccessList(el.Address)
+		for _, key := range el.Storage {
+			self.AddSlotToAccessList(el.Address, key)
+		}
+	}
+	if rules.IsShanghai { // EIP-3651: warm coinbase
+		self.AddAddressToAccessList(coinbase)
+	}
+}
+
+// AddAddressToAccessList adds the given address to the access list
+func (self *StateDB) AddAddressToAccessList(addr web3.Address) {
+	if self.accessList.AddAddress(addr) {
+		self.journal.append(accessListAddAccountChange{addr: addr})
+	}
+}
+
+// AddSlotToAccessList adds the given (address, slot)-tuple to the access list
+func (self *StateDB) AddSlotToAccessList(addr web3.Address, slot web3.Hash) {
+	addrMod, slotMod := self.accessList.AddSlot(addr, slot)
+	if addrMod {
+		// In practice, this should not happen, since there is no way to enter the
+		// scope of 'address' without having the 'address' become already added
+		// to the access list (via call-variant, create, etc).
+		// Better safe than sorry, though
+		self.journal.append(accessListAddAccountChange{addr: addr})
+	}
+	if slotMod {
+		self.journal.append(accessListAddSlotChange{addr: addr, slot: slot})
+	}
+}
+
+// AddressInAccessList returns true if the given address is in the access list.
+func (self *StateDB) AddressInAccessList(addr web3.Address) bool {
+	return self.accessList.ContainsAddress(addr)
+}
+
+// SlotInAccessList returns true if the given (address, slot)-tuple is in the access list.
+func (self *StateDB) SlotInAccessList(addr web3.Address, slot web3.Hash) (addressPresent bool, slotPresent bool) {
+	return self.accessList.Contains(addr, slot)
+}
+
+// GetTransientState gets transient storage for a given account.
+func (self *StateDB) GetTransientState(addr web3.Address, key web3.Hash) web3.Hash {
+	return self.transientStorage.Get(addr, key)
+}
+
+// SetTransientState sets transient storage for a given account.
+func (self *StateDB) SetTransientState(addr web3.Address, key, value web3.Hash) {
+	prev := self.transientStorage.Get(addr, key)
+	if prev == value {
+		return
+	}
+	self.journal.append(transientStorageChange{addr: addr, key: key, prev: prev})
+	self.transientStorage.Set(addr, key, value)
 }
 
 func (self *StateDB) DbErr() error {
 	return self.cacheDB.backend.Error()
 }
 
+// SetDbErr records an error met while reading the state, it is reported by DbErr
+func (self *StateDB) SetDbErr(err error) {
+	self.cacheDB.SetDbErr(err)
+}
+
 func (self *StateDB) BlockHash() web3.Hash {
 	return self.bhash
 }
@@ -148,15 +245,16 @@ func (self *StateDB) CommitToCacheDB() error {
 
 	self.Suicided = make(map[web3.Address]bool)
 	self.snapshots = self.snapshots[:0]
+	self.journal.reset()
 
 	return nil
 }
 
 type snapshot struct {
-	changes  *overlaydb.MemDB
-	suicided map[web3.Address]bool
-	logsSize int
-	refund   uint64
+	journalSize int
+	cacheSize   int
+	logsSize    int
+	refund      uint64
 }
 
 func (self *StateDB) AddRefund(gas uint64) {
@@ -216,7 +314,7 @@ type EthAccount struct {
 }
 
 func (self *EthAccount) IsEmpty() bool {
-	return self.Nonce == 0 && self.CodeHash == web3.Hash{}
+	return self.Nonce == 0 && self.CodeHash == web3.Hash{} && (self.Balance == nil || self.Balance.IsZero())
 }
 
 func (self *EthAccount) Serialization(sink *codec.ZeroCopySink) {
@@ -248,6 +346,7 @@ func (self *CacheDB) GetEthAccount(addr web3.Address) (val EthAccount, err error
 	}
 
 	if len(value) == 0 {
+		val.Balance = uint256.NewInt()
 		return val, nil
 	}
 
@@ -315,7 +414,10 @@ func (self *StateDB) Suicide(addr web3.Address) bool {
 	if acct.IsEmpty() {
 		return false
 	}
-	self.Suicided[addr] = true
+	if !self.Suicided[addr] {
+		self.journal.append(suicideChange{addr: addr})
+		self.Suicided[addr] = true
+	}
 	err := self.BalanceHandle.SetBalance(self.cacheDB, addr, big.NewInt(0))
 	if err != nil {
 		self.cacheDB.SetDbErr(err)
@@ -360,37 +462,70 @@ func (self *StateDB) AddLog(log *web3.StorageLog) {
 	self.logs = append(self.logs, log)
 }
 
-func (self *StateDB) AddPreimage(web3.Hash, []byte) {
-	// todo
-}
-
-func (self *StateDB) ForEachStorage(web3.Address, func(web3.Hash, web3.Hash) bool) error {
-	panic("todo")
+// AddPreimage records the SHA3 preimage, it is a no-op if Preimages is not set
+func (self *StateDB) AddPreimage(hash web3.Hash, preimage []byte) {
+	if self.Preimages != nil {
+		self.Preimages.Add(hash, preimage)
+	}
 }
 
-func (self *StateDB) CreateAccount(web3.Address) {
-	return
+// ForEachStorage calls cb with the non-zero storage slots of the contract, merged over the cache,
+// the overlay and the backend, until cb returns false. Only the slots known to the backend are
+// iterated, see the NewIterator of the backend store.
+func (self *StateDB) ForEachStorage(addr web3.Address, cb func(key, value web3.Hash) bool) error {
+	iter := self.cacheDB.NewIterator(addr[:])
+	defer iter.Release()
+	for has := iter.First(); has; has = iter.Next() {
+		value := web3.BytesToHash(iter.Value())
+		if value == (web3.Hash{}) {
+			continue
+		}
+		if !cb(web3.BytesToHash(iter.Key()[web3.AddressLength:]), value) {
+			break
+		}
+	}
+	return iter.Error()
 }
 
-func (self *StateDB) Snapshot() int {
-	changes := self.cacheDB.memdb.DeepClone()
-	suicided := make(map[web3.Address]bool)
-	for k, v := range self.Suicided {
-		suicided[k] = v
+// CreateAccount creates a fresh account at the address, only the balance of an existing account
+// is kept and its storage is cleared.
+func (self *StateDB) CreateAccount(addr web3.Address) {
+	if !self.created[addr] {
+		self.journal.append(createChange{addr: addr})
+		self.created[addr] = true
+	}
+	acct := self.getEthAccount(addr)
+	if acct.Nonce == 0 && len(acct.Code) == 0 {
+		// the evm only creates accounts without nonce and code, whose storage is empty
+		return
 	}
+	if err := self.cacheDB.CleanContractStorageData(addr); err != nil {
+		self.cacheDB.SetDbErr(err)
+		return
+	}
+	self.cacheDB.PutEthAccount(addr, EthAccount{Balance: acct.Balance})
+}
 
-	sn := &snapshot{
-		changes:  changes,
-		suicided: suicided,
-		logsSize: len(self.logs),
-		refund:   self.refund,
+func (self *StateDB) Selfdestruct6780(addr web3.Address) {
+	if self.created[addr] {
+		self.Suicide(addr)
 	}
+}
 
-	self.snapshots = append(self.snapshots, sn)
+// Snapshot records the positions of the state journals, its cost does not depend on the size of
+// the modified state.
+func (self *StateDB) Snapshot() int {
+	self.snapshots = append(self.snapshots, snapshot{
+		journalSize: self.journal.length(),
+		cacheSize:   self.cacheDB.snapshot(),
+		logsSize:    len(self.logs),
+		refund:      self.refund,
+	})
 
 	return len(self.snapshots) - 1
 }
 
+// RevertToSnapshot undoes the state modifications made after the snapshot was taken
 func (self *StateDB) RevertToSnapshot(idx int) {
 	if idx+1 > len(self.snapshots) {
 		panic("can not to revert snapshot")
@@ -399,8 +534,8 @@ func (self *StateDB) RevertToSnapshot(idx int) {
 	sn := self.snapshots[idx]
 
 	self.snapshots = self.snapshots[:idx]
-	self.cacheDB.memdb = sn.changes
-	self.Suicided = sn.suicided
+	self.journal.revert(self, sn.journalSize)
+	self.cacheDB.revertTo(sn.cacheSize)
 	self.refund = sn.refund
 	self.logs = self.logs[:sn.logsSize]
 }
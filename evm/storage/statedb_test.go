@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/laizy/web3/evm/params"
+	"github.com/laizy/web3/evm/storage/overlaydb"
+	"github.com/laizy/web3/evm/storage/schema"
+	"github.com/laizy/web3/trie"
+	"github.com/laizy/web3/utils/codec"
+	"github.com/laizy/web3/utils/common/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/fastrlp"
+)
+
+func newTestStateDB(accounts ...web3.Address) *StateDB {
+	store := overlaydb.NewMemStore()
+	for _, addr := range accounts {
+		acct := &EthAccount{Nonce: 1, Balance: uint256.NewInt()}
+		store.BatchPut(append([]byte{byte(schema.ST_ETH_ACCOUNT)}, addr[:]...), codec.SerializeToBytes(acct))
+	}
+	return NewStateDB(NewCacheDB(overlaydb.NewOverlayDB(store)), web3.Hash{}, web3.Hash{})
+}
+
+func TestSnapshotRevert(t *testing.T) {
+	addr := web3.BytesToAddress([]byte{1})
+	other := web3.BytesToAddress([]byte{2})
+	statedb := newTestStateDB(addr, other)
+	slot1, slot2 := web3.BytesToHash([]byte{1}), web3.BytesToHash([]byte{2})
+	val1, val2 := web3.BytesToHash([]byte{10}), web3.BytesToHash([]byte{20})
+
+	statedb.PrepareAccessList(params.Rules{IsBerlin: true}, addr, web3.Address{}, nil, nil, nil)
+	statedb.AddBalance(addr, big.NewInt(100))
+	statedb.SetNonce(addr, 2)
+	statedb.SetState(addr, slot1, val1)
+	statedb.AddRefund(10)
+
+	outer := statedb.Snapshot()
+	statedb.SetState(addr, slot1, val2)
+	statedb.SetState(addr, slot2, val2)
+	statedb.AddSlotToAccessList(addr, slot1)
+	statedb.AddAddressToAccessList(other)
+	statedb.SetTransientState(addr, slot1, val1)
+	statedb.AddLog(&web3.StorageLog{Address: addr})
+
+	inner := statedb.Snapshot()
+	statedb.CreateAccount(other)
+	statedb.AddBalance(other, big.NewInt(5))
+	statedb.SetCode(other, []byte{0x60, 0x00})
+	assert.True(t, statedb.Suicide(other))
+	statedb.AddRefund(5)
+
+	statedb.RevertToSnapshot(inner)
+	assert.False(t, statedb.HasSuicided(other))
+	assert.Equal(t, 0, statedb.GetBalance(other).Sign())
+	assert.Equal(t, 0, statedb.GetCodeSize(other))
+	assert.False(t, statedb.created[other])
+	assert.Equal(t, uint64(10), statedb.GetRefund())
+	assert.Equal(t, val2, statedb.GetState(addr, slot2))
+	assert.True(t, statedb.AddressInAccessList(other))
+	assert.Len(t, statedb.GetLogs(), 1)
+
+	statedb.RevertToSnapshot(outer)
+	assert.Equal(t, val1, statedb.GetState(addr, slot1))
+	assert.Equal(t, web3.Hash{}, statedb.GetState(addr, slot2))
+	assert.Equal(t, int64(100), statedb.GetBalance(addr).Int64())
+	assert.Equal(t, uint64(2), statedb.GetNonce(addr))
+	assert.False(t, statedb.AddressInAccessList(other))
+	_, slotPresent := statedb.SlotInAccessList(addr, slot1)
+	assert.False(t, slotPresent)
+	assert.True(t, statedb.AddressInAccessList(addr))
+	assert.Equal(t, web3.Hash{}, statedb.GetTransientState(addr, slot1))
+	assert.Len(t, statedb.GetLogs(), 0)
+
+	// slot2 was never written before the snapshot, so it is unknown to the cache again
+	_, unknown := statedb.cacheDB.memdb.Get(statedb.cacheDB.GenAccountStateKey(addr, slot2[:]))
+	assert.True(t, unknown)
+}
+
+func TestStateRoot(t *testing.T) {
+	statedb := newTestStateDB()
+	root, err := statedb.cacheDB.StateRoot()
+	assert.Nil(t, err)
+	assert.Equal(t, trie.EmptyRoot, root)
+
+	eoa := web3.BytesToAddress([]byte{1})
+	contract := web3.BytesToAddress([]byte{2})
+	slot := web3.BytesToHash([]byte{1})
+	statedb.AddBalance(eoa, big.NewInt(1000))
+	statedb.SetNonce(contract, 1)
+	statedb.SetCode(contract, []byte{0x60, 0x00})
+	statedb.SetState(contract, slot, web3.BytesToHash([]byte{0x12, 0x34}))
+	statedb.SetState(contract, web3.BytesToHash([]byte{2}), web3.Hash{})
+	assert.Nil(t, statedb.Commit())
+
+	storage := trie.NewSecureTrie()
+	storage.Update(slot[:], []byte{0x82, 0x12, 0x34})
+	storageRoot, err := statedb.cacheDB.StorageRoot(contract)
+	assert.Nil(t, err)
+	assert.Equal(t, storage.Hash(), storageRoot)
+
+	encode := func(nonce uint64, balance *big.Int, root, codeHash web3.Hash) []byte {
+		ar := &fastrlp.Arena{}
+		vv := ar.NewArray()
+		vv.Set(ar.NewUint(nonce))
+		vv.Set(ar.NewBigInt(balance))
+		vv.Set(ar.NewBytes(root[:]))
+		vv.Set(ar.NewBytes(codeHash[:]))
+		return vv.MarshalTo(nil)
+	}
+	accounts := trie.NewSecureTrie()
+	accounts.Update(eoa[:], encode(0, big.NewInt(1000), trie.EmptyRoot, crypto.Keccak256Hash(nil)))
+	accounts.Update(contract[:], encode(1, big.NewInt(0), storage.Hash(), crypto.Keccak256Hash([]byte{0x60, 0x00})))
+	root, err = statedb.cacheDB.StateRoot()
+	assert.Nil(t, err)
+	assert.Equal(t, accounts.Hash(), root)
+}
+
+func TestStateRootEmptyAccount(t *testing.T) {
+	// a remote backend caches the accounts missing on the node as empty accounts
+	missing := web3.BytesToAddress([]byte{3})
+	store := overlaydb.NewMemStore()
+	store.BatchPut(append([]byte{byte(schema.ST_ETH_ACCOUNT)}, missing[:]...), codec.SerializeToBytes(&EthAccount{Balance: uint256.NewInt()}))
+	statedb := NewStateDB(NewCacheDB(overlaydb.NewOverlayDB(store)), web3.Hash{}, web3.Hash{})
+
+	assert.False(t, statedb.Exist(missing))
+	assert.Equal(t, 0, statedb.GetBalance(missing).Sign())
+	root, err := statedb.cacheDB.StateRoot()
+	assert.Nil(t, err)
+	assert.Equal(t, trie.EmptyRoot, root)
+
+	// an account with storage is kept
+	statedb.SetState(missing, web3.BytesToHash([]byte{1}), web3.BytesToHash([]byte{1}))
+	assert.Nil(t, statedb.Commit())
+	root, err = statedb.cacheDB.StateRoot()
+	assert.Nil(t, err)
+	assert.NotEqual(t, trie.EmptyRoot, root)
+}
+
+func TestForEachStorage(t *testing.T) {
+	addr := web3.BytesToAddress([]byte{1})
+	statedb := newTestStateDB(addr)
+	slot := func(i byte) web3.Hash { return web3.BytesToHash([]byte{i}) }
+	statedb.SetState(addr, slot(1), slot(0x11))
+	statedb.SetState(addr, slot(2), slot(0x22))
+	statedb.SetState(addr, slot(3), slot(0x33))
+	statedb.SetState(web3.BytesToAddress([]byte{2}), slot(1), slot(0x44))
+	assert.Nil(t, statedb.Commit())
+	statedb.SetState(addr, slot(2), web3.Hash{})
+	statedb.SetState(addr, slot(4), slot(0x55))
+
+	storage := make(map[web3.Hash]web3.Hash)
+	assert.Nil(t, statedb.ForEachStorage(addr, func(key, value web3.Hash) bool {
+		storage[key] = value
+		return true
+	}))
+	assert.Equal(t, map[web3.Hash]web3.Hash{slot(1): slot(0x11), slot(3): slot(0x33), slot(4): slot(0x55)}, storage)
+
+	count := 0
+	assert.Nil(t, statedb.ForEachStorage(addr, func(key, value web3.Hash) bool {
+		count++
+		return false
+	}))
+	assert.Equal(t, 1, count)
+}
+
+// BenchmarkSnapshotRevert measures a call tree of the given depth on top of a large dirty state,
+// each call frame takes a snapshot, writes a slot and is reverted. Compare with BenchmarkDeepClone
+// in the overlaydb package, which is the cost per snapshot of copying the dirty state.
+func BenchmarkSnapshotRevert(b *testing.B) {
+	const dirty = 10000
+	const depth = 64
+	addr := web3.BytesToAddress([]byte{1})
+	statedb := newTestStateDB(addr)
+	for i := 0; i < dirty; i++ {
+		statedb.SetState(addr, web3.BytesToHash(big.NewInt(int64(i)).Bytes()), web3.Hash{1})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		snapshots := make([]int, depth)
+		for d := 0; d < depth; d++ {
+			snapshots[d] = statedb.Snapshot()
+			statedb.SetState(addr, web3.BytesToHash(big.NewInt(int64(d)).Bytes()), web3.Hash{2})
+		}
+		for d := depth - 1; d >= 0; d-- {
+			statedb.RevertToSnapshot(snapshots[d])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/trie"
+)
+
+// BlockResult is the outcome of executing a list of transactions as one block
+type BlockResult struct {
+	Results   []*web3.ExecutionResult
+	Receipts  []*web3.Receipt
+	GasUsed   uint64
+	LogsBloom web3.Bloom
+	// TransactionsRoot and ReceiptsRoot are the roots of the tries of the transactions and receipts
+	TransactionsRoot web3.Hash
+	ReceiptsRoot     web3.Hash
+	// StateDiff is the state changed by the whole block
+	StateDiff StateDiff
+}
+
+// ExecuteBlock executes the transactions in order as a single block with the given context, the
+// TxIndex of the context is ignored. The receipts carry the cumulative gas used, the block wide log
+// indexes and the logs bloom. If a transaction fails to apply, the state is rolled back to the
+// state before the block and the error is returned.
+func (self *Executor) ExecuteBlock(txs []*web3.Transaction, ctx Eip155Context) (*BlockResult, error) {
+	config, err := self.chainConfig()
+	if err != nil {
+		return nil, err
+	}
+	evmConf := self.evmConfig()
+
+	before := self.overlayDB.GetWriteSet().DeepClone()
+	rollback := func() {
+		self.cacheDB.Reset()
+		self.overlayDB.SetWriteSet(before)
+		self.overlayDB.SetError(nil)
+	}
+
+	res := &BlockResult{}
+	logIndex := uint64(0)
+	for i, tx := range txs {
+		if ctx.GasLimit != 0 && res.GasUsed+tx.Gas > ctx.GasLimit {
+			rollback()
+			return nil, fmt.Errorf("transaction %d: %w", i, ErrGasLimitReached)
+		}
+		ctx.TxIndex = uint64(i)
+		statedb := self.newStateDB(tx.Hash(), ctx.BlockHash)
+		result, receipt, err := ApplyTransaction(config, self.db, statedb, ctx, tx, &res.GasUsed, evmConf, false)
+		if err != nil {
+			rollback()
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		receipt.BlockHash = ctx.BlockHash
+		receipt.BlockNumber = ctx.Height
+		for _, log := range receipt.Logs {
+			log.LogIndex = logIndex
+			log.BlockHash = ctx.BlockHash
+			log.BlockNumber = ctx.Height
+			logIndex++
+		}
+		res.LogsBloom.Or(web3.LogsBloom(receipt.Logs))
+		res.Results = append(res.Results, result)
+		res.Receipts = append(res.Receipts, receipt)
+	}
+
+	diff, err := diffWriteSet(before, self.overlayDB.GetWriteSet(), self.db)
+	if err != nil {
+		return nil, err
+	}
+	res.StateDiff = diff
+	res.TransactionsRoot = trie.DeriveTransactionsRoot(txs)
+	res.ReceiptsRoot = trie.DeriveReceiptsRoot(res.Receipts)
+	return res, nil
+}
+
+// CompareHeader returns the fields of the block header which differ from the executed block,
+// each formatted as "field: got <local>, want <expected>". The state root is not compared, see
+// Executor.StateRoot.
+func (self *BlockResult) CompareHeader(block *web3.Block) []string {
+	var mismatches []string
+	mismatch := func(field string, got, want interface{}) {
+		mismatches = append(mismatches, fmt.Sprintf("%s: got %v, want %v", field, got, want))
+	}
+	if self.GasUsed != block.GasUsed {
+		mismatch("gasUsed", self.GasUsed, block.GasUsed)
+	}
+	if self.TransactionsRoot != block.TransactionsRoot {
+		mismatch("transactionsRoot", self.TransactionsRoot, block.TransactionsRoot)
+	}
+	if self.ReceiptsRoot != block.ReceiptsRoot {
+		mismatch("receiptsRoot", self.ReceiptsRoot, block.ReceiptsRoot)
+	}
+	return mismatches
+}
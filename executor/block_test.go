@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteBlock(t *testing.T) {
+	from, to := web3.BytesToAddress([]byte{0xaa}), web3.BytesToAddress([]byte{0xbb})
+	emitter, coinbase := web3.BytesToAddress([]byte{0xcc}), web3.BytesToAddress([]byte{0xdd})
+	topic := web3.BytesToHash([]byte{0x11})
+	// LOG1 of the word 42 with the topic
+	code, _ := hex.DecodeString("602a600052" + "7f" + hex.EncodeToString(topic[:]) + "60206000a100")
+	node := newTestNode(map[web3.Address]*testAccount{
+		from:    {balance: big.NewInt(10000000)},
+		emitter: {code: code},
+	})
+	exec, closeFn := newTestExecutor(t, node, 10)
+	defer closeFn()
+
+	ctx := Eip155Context{BlockHash: web3.BytesToHash([]byte{0x99}), Height: 11, Coinbase: coinbase, BaseFee: big.NewInt(1), GasLimit: 1000000}
+	txs := []*web3.Transaction{
+		{From: from, To: &emitter, Gas: 100000, GasPrice: 2},
+		{From: from, To: &to, Gas: 21000, GasPrice: 2, Value: big.NewInt(5), Nonce: 1},
+		{From: from, To: &emitter, Gas: 100000, GasPrice: 2, Nonce: 2},
+	}
+	res, err := exec.ExecuteBlock(txs, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(res.Receipts))
+
+	cumulative := uint64(0)
+	for i, receipt := range res.Receipts {
+		cumulative += receipt.GasUsed
+		assert.Equal(t, cumulative, receipt.CumulativeGasUsed)
+		assert.Equal(t, uint64(i), receipt.TransactionIndex)
+		assert.Equal(t, ctx.BlockHash, receipt.BlockHash)
+		assert.Equal(t, uint64(11), receipt.BlockNumber)
+	}
+	assert.Equal(t, cumulative, res.GasUsed)
+	assert.Equal(t, uint64(21000), res.Receipts[1].GasUsed)
+
+	// log indexes are counted over the whole block
+	assert.Equal(t, 1, len(res.Receipts[0].Logs))
+	assert.Equal(t, 1, len(res.Receipts[2].Logs))
+	assert.Equal(t, uint64(0), res.Receipts[0].Logs[0].LogIndex)
+	assert.Equal(t, uint64(1), res.Receipts[2].Logs[0].LogIndex)
+	assert.Equal(t, ctx.BlockHash, res.Receipts[2].Logs[0].BlockHash)
+	assert.True(t, res.LogsBloom.Test(emitter[:]))
+	assert.True(t, res.LogsBloom.Test(topic[:]))
+	assert.False(t, res.LogsBloom.Test(to[:]))
+
+	// only the tip goes to the coinbase
+	assert.Equal(t, new(big.Int).SetUint64(cumulative), exec.testBalance(coinbase))
+	assert.Equal(t, big.NewInt(5), exec.testBalance(to))
+}
+
+func TestExecuteBlockRollback(t *testing.T) {
+	from, to := web3.BytesToAddress([]byte{0xaa}), web3.BytesToAddress([]byte{0xbb})
+	node := newTestNode(map[web3.Address]*testAccount{from: {balance: big.NewInt(100000)}})
+	exec, closeFn := newTestExecutor(t, node, 10)
+	defer closeFn()
+
+	// the first transaction moves the state, then the block fails
+	_, err := exec.ExecuteBlock([]*web3.Transaction{
+		{From: from, To: &to, Gas: 21000, Value: big.NewInt(5)},
+		{From: from, To: &to, Gas: 21000, GasPrice: 10, Nonce: 1},
+	}, Eip155Context{Height: 11})
+	assert.True(t, errors.Is(err, ErrInsufficientFunds))
+	assert.Equal(t, big.NewInt(100000), exec.testBalance(from))
+	assert.Equal(t, 0, exec.testBalance(to).Sign())
+
+	_, err = exec.ExecuteBlock([]*web3.Transaction{
+		{From: from, To: &to, Gas: 21000, Value: big.NewInt(5)},
+		{From: from, To: &to, Gas: 21000, Value: big.NewInt(5), Nonce: 1},
+	}, Eip155Context{Height: 11, GasLimit: 30000})
+	assert.True(t, errors.Is(err, ErrGasLimitReached))
+	assert.Equal(t, big.NewInt(100000), exec.testBalance(from))
+	assert.Equal(t, 0, exec.testBalance(to).Sign())
+
+	// the executor is still usable after a rollback
+	res, err := exec.ExecuteBlock([]*web3.Transaction{
+		{From: from, To: &to, Gas: 21000, Value: big.NewInt(5)},
+	}, Eip155Context{Height: 11, GasLimit: 30000})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(21000), res.GasUsed)
+	assert.Equal(t, big.NewInt(5), exec.testBalance(to))
+}
@@ -23,28 +23,70 @@ import (
 
 	"github.com/laizy/web3"
 	"github.com/laizy/web3/evm"
+	"github.com/laizy/web3/evm/params"
 )
 
-// NewEVMBlockContext creates a new context for use in the EVM.
-func NewEVMBlockContext(height, timestamp uint64, hashFn evm.GetHashFunc) evm.BlockContext {
-	return evm.BlockContext{
+// NewEVMBlockContext creates a new context for use in the EVM from the block fields of the
+// context.
+func NewEVMBlockContext(ctx Eip155Context, hashFn evm.GetHashFunc) evm.BlockContext {
+	blockCtx := evm.BlockContext{
 		CanTransfer: CanTransfer,
 		Transfer:    Transfer,
 		GetHash:     hashFn,
-		Coinbase:    web3.Address{}, // todo
-		BlockNumber: big.NewInt(int64(height)),
-		Time:        big.NewInt(int64(timestamp)),
+		Coinbase:    ctx.Coinbase,
+		BlockNumber: new(big.Int).SetUint64(ctx.Height),
+		Time:        new(big.Int).SetUint64(ctx.Timestamp),
 		Difficulty:  big.NewInt(0),
-		GasLimit:    math.MaxUint64, // todo
+		GasLimit:    math.MaxUint64,
 	}
+	if ctx.GasLimit != 0 {
+		blockCtx.GasLimit = ctx.GasLimit
+	}
+	if ctx.Difficulty != nil {
+		blockCtx.Difficulty = new(big.Int).Set(ctx.Difficulty)
+	}
+	if ctx.BaseFee != nil {
+		blockCtx.BaseFee = new(big.Int).Set(ctx.BaseFee)
+	}
+	if ctx.Random != nil {
+		random := *ctx.Random
+		blockCtx.Random = &random
+	}
+	if ctx.ExcessBlobGas != nil {
+		blockCtx.BlobBaseFee = CalcBlobFee(*ctx.ExcessBlobGas)
+	}
+	return blockCtx
 }
 
 // NewEVMTxContext creates a new transaction context for a single transaction.
 func NewEVMTxContext(msg Message) evm.TxContext {
 	return evm.TxContext{
-		Origin:   msg.From(),
-		GasPrice: new(big.Int).Set(msg.GasPrice()),
+		Origin:     msg.From(),
+		GasPrice:   new(big.Int).Set(msg.GasPrice()),
+		BlobHashes: msg.BlobHashes(),
+	}
+}
+
+// CalcBlobFee calculates the blob gas price of a block from its excess blob gas (EIP-4844).
+func CalcBlobFee(excessBlobGas uint64) *big.Int {
+	return fakeExponential(big.NewInt(params.BlobTxMinBlobGasprice), new(big.Int).SetUint64(excessBlobGas),
+		big.NewInt(params.BlobTxBlobGaspriceUpdateFraction))
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) using Taylor expansion.
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	var (
+		output = new(big.Int)
+		accum  = new(big.Int).Mul(factor, denominator)
+	)
+	for i := 1; accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+
+		accum.Mul(accum, numerator)
+		accum.Div(accum, denominator)
+		accum.Div(accum, big.NewInt(int64(i)))
 	}
+	return output.Div(output, denominator)
 }
 
 // CanTransfer checks whether there are enough funds in the address' account to make a transfer.
@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalcBlobFee(t *testing.T) {
+	tests := []struct {
+		excessBlobGas uint64
+		blobFee       int64
+	}{
+		{0, 1},
+		{2314057, 1},
+		{2314058, 2},
+		{10 * 1024 * 1024, 23},
+	}
+	for _, test := range tests {
+		assert.Equal(t, big.NewInt(test.blobFee), CalcBlobFee(test.excessBlobGas), test.excessBlobGas)
+	}
+}
+
+func TestNewEVMBlockContext(t *testing.T) {
+	random := web3.BytesToHash([]byte{1})
+	excessBlobGas := uint64(2314058)
+	ctx := NewEVMBlockContext(Eip155Context{
+		Height:        10,
+		Timestamp:     20,
+		BaseFee:       big.NewInt(7),
+		Random:        &random,
+		ExcessBlobGas: &excessBlobGas,
+	}, nil)
+	assert.Equal(t, big.NewInt(10), ctx.BlockNumber)
+	assert.Equal(t, big.NewInt(20), ctx.Time)
+	assert.Equal(t, big.NewInt(7), ctx.BaseFee)
+	assert.Equal(t, random, *ctx.Random)
+	assert.Equal(t, big.NewInt(2), ctx.BlobBaseFee)
+	assert.Equal(t, big.NewInt(0), ctx.Difficulty)
+
+	ctx = NewEVMBlockContext(Eip155Context{Difficulty: big.NewInt(5), GasLimit: 30}, nil)
+	assert.Nil(t, ctx.Random)
+	assert.Nil(t, ctx.BlobBaseFee)
+	assert.Equal(t, big.NewInt(5), ctx.Difficulty)
+	assert.Equal(t, uint64(30), ctx.GasLimit)
+
+	blobHashes := []web3.Hash{web3.BytesToHash([]byte{2})}
+	txCtx := NewEVMTxContext(&message{gasPrice: big.NewInt(1), blobHashes: blobHashes})
+	assert.Equal(t, blobHashes, txCtx.BlobHashes)
+}
+
+func TestBlockContext(t *testing.T) {
+	mixHash := web3.BytesToHash([]byte{3})
+	block := &web3.Block{Number: 1, Difficulty: big.NewInt(100), MixHash: mixHash}
+	ctx := BlockContext(block)
+	assert.Nil(t, ctx.Random)
+	assert.Equal(t, big.NewInt(100), ctx.Difficulty)
+
+	// after the merge
+	block.Difficulty = big.NewInt(0)
+	ctx = BlockContext(block)
+	assert.Equal(t, mixHash, *ctx.Random)
+}
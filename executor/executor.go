@@ -1,6 +1,9 @@
 package executor
 
 import (
+	"errors"
+	"fmt"
+	"math/big"
 	"os"
 
 	"github.com/laizy/web3"
@@ -13,26 +16,178 @@ import (
 )
 
 type Executor struct {
+	client    *jsonrpc.Client
 	db        *remotedb.RemoteDB
 	overlayDB *overlaydb.OverlayDB
 	cacheDB   *storage.CacheDB
 	chainID   uint64
+	config    *params.ChainConfig
+	preimages *storage.Preimages
+	profiler  *evm.GasProfiler
 	Trace     bool
+	// Tracer traces the executed transactions when Trace is set, the opcode logs are printed to
+	// stdout in json if it is nil. Use evm.NewCallTracer to get the call frames instead.
+	Tracer evm.Tracer
 }
 
+// NewExecutor creates an executor simulating on top of the latest block. The latest block moves
+// as the chain grows, so state cached from different blocks may be mixed. The chain is queried
+// with eth_chainId on the first execution, see SetChainID.
 func NewExecutor(client *jsonrpc.Client) *Executor {
-	remote := remotedb.NewRemoteDB(client)
+	return newExecutor(client, remotedb.NewRemoteDB(client))
+}
+
+// NewExecutorAt creates an executor simulating on top of the state of the given block, every
+// remote read is served from that block.
+func NewExecutorAt(client *jsonrpc.Client, blockNumber uint64) *Executor {
+	return newExecutor(client, remotedb.NewRemoteDBAt(client, blockNumber))
+}
+
+func newExecutor(client *jsonrpc.Client, remote *remotedb.RemoteDB) *Executor {
 	overlay := overlaydb.NewOverlayDB(remote)
 	cacheDB := storage.NewCacheDB(overlay)
 	//remote.Trace = true
 	return &Executor{
+		client:    client,
 		db:        remote,
 		overlayDB: overlay,
 		cacheDB:   cacheDB,
-		chainID:   1234,
 	}
 }
 
+// SetCache sets the persistent cache of the state fetched from the remote node, it is only used
+// by executors pinned to a block.
+func (self *Executor) SetCache(cache remotedb.Cache, chainID uint64) {
+	self.db.SetCache(cache, chainID)
+}
+
+// SetChainConfig sets the fork schedule used by the executor instead of the one of its chain
+// returned by params.GetChainConfig, e.g. for a devnet which does not run the latest fork.
+func (self *Executor) SetChainConfig(config *params.ChainConfig) {
+	self.config = config
+}
+
+// SetChainID sets the chain executed on, instead of querying it with eth_chainId. The fork
+// schedule and the CHAINID opcode follow the chain.
+func (self *Executor) SetChainID(chainID uint64) {
+	self.chainID = chainID
+}
+
+// resolveChainID returns the chain of the remote node, queried once with eth_chainId
+func (self *Executor) resolveChainID() (uint64, error) {
+	if self.chainID == 0 {
+		chainID, err := self.client.Eth().ChainID()
+		if err != nil {
+			return 0, fmt.Errorf("get chain id: %w", err)
+		}
+		self.chainID = chainID.Uint64()
+	}
+	return self.chainID, nil
+}
+
+// chainConfig returns the fork schedule of the executor, or the one of its chain if it is not set
+func (self *Executor) chainConfig() (*params.ChainConfig, error) {
+	if self.config != nil {
+		return self.config, nil
+	}
+	chainID, err := self.resolveChainID()
+	if err != nil {
+		return nil, err
+	}
+	return params.GetChainConfig(chainID), nil
+}
+
+// RemoteDB returns the db serving the remote state, which holds the retry and timeout options
+// of the remote requests.
+func (self *Executor) RemoteDB() *remotedb.RemoteDB {
+	return self.db
+}
+
+// Prefetch warms the state cache with the accounts and storage slots the transaction is expected
+// to touch, as reported by the eth_createAccessList of the remote node.
+func (self *Executor) Prefetch(tx *web3.Transaction) error {
+	msg := tx.ToCallMsg()
+	list, _, err := self.client.Eth().CreateAccessList(msg, self.db.BlockNumber())
+	if err != nil {
+		return err
+	}
+	list = append(list, web3.AccessEntry{Address: tx.From})
+	if tx.To != nil {
+		list = append(list, web3.AccessEntry{Address: *tx.To})
+	}
+	return self.db.Prefetch(list)
+}
+
+// StateRoot computes the state root of the simulated state. The accounts are enumerated from the
+// local state and the remote entries fetched so far, so the root only matches the chain when the
+// whole state is known, e.g. on a small devnet whose accounts have all been touched. Enable
+// StorageRange of the RemoteDB to load the complete storage of every account.
+func (self *Executor) StateRoot() (web3.Hash, error) {
+	return self.cacheDB.StateRoot()
+}
+
+// EnablePreimages records the preimages of the SHA3 opcode in later executions, they map storage
+// slots back to the mapping keys producing them, see Preimages.ResolveSlot.
+func (self *Executor) EnablePreimages() {
+	if self.preimages == nil {
+		self.preimages = storage.NewPreimages()
+	}
+}
+
+// Preimages returns the recorded preimages, nil if recording is not enabled
+func (self *Executor) Preimages() *storage.Preimages {
+	return self.preimages
+}
+
+// ForEachStorage calls cb with the non-zero storage slots of the contract in the simulated state,
+// until cb returns false. The slots are the ones written by the simulation or read from the remote
+// node so far, enable StorageRange of the RemoteDB to iterate over the complete storage.
+func (self *Executor) ForEachStorage(addr web3.Address, cb func(key, value web3.Hash) bool) error {
+	statedb := storage.NewStateDB(self.cacheDB, web3.Hash{}, web3.Hash{})
+	return statedb.ForEachStorage(addr, cb)
+}
+
+// EnableGasProfiler profiles the gas of the transactions executed afterwards, the profile is
+// accumulated in the returned profiler. Names of contracts and methods are resolved with the
+// resolver, e.g. registry.Instance(), which may be nil.
+func (self *Executor) EnableGasProfiler(resolver evm.NameResolver) *evm.GasProfiler {
+	self.profiler = evm.NewGasProfiler(resolver)
+	return self.profiler
+}
+
+// GasProfiler returns the gas profiler of the executor, nil if it is not enabled
+func (self *Executor) GasProfiler() *evm.GasProfiler {
+	return self.profiler
+}
+
+// newStateDB creates the state db executing a transaction
+func (self *Executor) newStateDB(txHash, blockHash web3.Hash) *storage.StateDB {
+	statedb := storage.NewStateDB(self.cacheDB, txHash, blockHash)
+	statedb.Preimages = self.preimages
+	return statedb
+}
+
+// evmConfig returns the evm config of the executor
+func (self *Executor) evmConfig() evm.Config {
+	cfg := evm.Config{EnablePreimageRecording: self.preimages != nil}
+	if self.Trace {
+		cfg.Debug = true
+		cfg.Tracer = self.Tracer
+		if cfg.Tracer == nil {
+			cfg.Tracer = evm.NewJSONLogger(nil, os.Stdout)
+		}
+	}
+	if self.profiler != nil {
+		cfg.Debug = true
+		if cfg.Tracer == nil {
+			cfg.Tracer = self.profiler
+		} else {
+			cfg.Tracer = evm.NewMultiTracer(cfg.Tracer, self.profiler)
+		}
+	}
+	return cfg
+}
+
 func (self *Executor) ResetOverlay() {
 	self.overlayDB = overlaydb.NewOverlayDB(self.db)
 	self.cacheDB = storage.NewCacheDB(self.overlayDB)
@@ -44,27 +199,49 @@ type Eip155Context struct {
 	Height    uint64
 	Timestamp uint64
 	Coinbase  web3.Address
+	BaseFee   *big.Int // nil for blocks before London
+	GasLimit  uint64   // zero means unlimited
+	// Difficulty is the difficulty of the blocks before the merge, nil means zero
+	Difficulty *big.Int
+	// Random is the prevrandao of the blocks after the merge, returned by PREVRANDAO
+	Random *web3.Hash
+	// ExcessBlobGas gives the blob base fee of the blocks after Cancun, nil before
+	ExcessBlobGas *uint64
 }
 
 func (self *Executor) ExecuteTransaction(tx *web3.Transaction, ctx Eip155Context) (*web3.ExecutionResult, *web3.Receipt, error) {
 	usedGas := uint64(0)
-	config := params.GetChainConfig(self.chainID)
-	statedb := storage.NewStateDB(self.cacheDB, tx.Hash(), ctx.BlockHash)
-	evmConf := evm.Config{}
-	if self.Trace {
-		evmConf.Debug = true
-		evmConf.Tracer = evm.NewJSONLogger(nil, os.Stdout)
-	}
-	result, receipt, err := ApplyTransaction(config, self.db, statedb, ctx.Height, ctx.Timestamp, tx, &usedGas,
-		ctx.Coinbase, evmConf, false)
-
+	config, err := self.chainConfig()
 	if err != nil {
 		return nil, nil, err
 	}
-	if err = statedb.DbErr(); err != nil {
+	statedb := self.newStateDB(tx.Hash(), ctx.BlockHash)
+	result, receipt, err := ApplyTransaction(config, self.db, statedb, ctx, tx, &usedGas, self.evmConfig(), false)
+
+	if err != nil {
+		var readErr *StateReadError
+		if errors.As(err, &readErr) {
+			// drop the partial writes and the read error, so later transactions can be executed
+			self.cacheDB.Reset()
+			self.overlayDB.SetError(nil)
+		}
 		return nil, nil, err
 	}
-	receipt.TransactionIndex = ctx.TxIndex
 
 	return result, receipt, nil
 }
+
+// ExecuteTransactionDiff executes the transaction like ExecuteTransaction and also returns the
+// state changed by it.
+func (self *Executor) ExecuteTransactionDiff(tx *web3.Transaction, ctx Eip155Context) (*web3.ExecutionResult, *web3.Receipt, StateDiff, error) {
+	before := self.overlayDB.GetWriteSet().DeepClone()
+	result, receipt, err := self.ExecuteTransaction(tx, ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	diff, err := diffWriteSet(before, self.overlayDB.GetWriteSet(), self.db)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return result, receipt, diff, nil
+}
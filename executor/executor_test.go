@@ -0,0 +1,221 @@
+package executor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAccount struct {
+	nonce   uint64
+	balance *big.Int
+	code    []byte
+}
+
+// testNode is a json rpc node serving the state of the remote db from memory
+type testNode struct {
+	lock     sync.Mutex
+	accounts map[web3.Address]*testAccount
+	chainID  uint64
+	replies  map[string]interface{} // canned results by method
+	blocks   map[string]int         // block parameter of the state reads to their count
+	hashReqs []uint64               // heights of the requested block hashes
+}
+
+func newTestNode(accounts map[web3.Address]*testAccount) *testNode {
+	for _, acct := range accounts {
+		if acct.balance == nil {
+			acct.balance = big.NewInt(0)
+		}
+	}
+	// every fork is active on an unknown chain
+	return &testNode{accounts: accounts, chainID: 1337, blocks: map[string]int{}}
+}
+
+func testBlockHash(height uint64) web3.Hash {
+	return web3.BytesToHash(new(big.Int).SetUint64(height + 1).Bytes())
+}
+
+func (self *testNode) handle(method string, params []json.RawMessage) (interface{}, error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	var addr web3.Address
+	if len(params) > 0 {
+		json.Unmarshal(params[0], &addr)
+	}
+	var block string
+	if len(params) > 1 {
+		json.Unmarshal(params[len(params)-1], &block)
+	}
+	acct := self.accounts[addr]
+	if acct == nil {
+		acct = &testAccount{balance: big.NewInt(0)}
+	}
+	if res, ok := self.replies[method]; ok {
+		return res, nil
+	}
+	switch method {
+	case "eth_chainId":
+		return fmt.Sprintf("0x%x", self.chainID), nil
+	case "eth_getTransactionCount":
+		self.blocks[block]++
+		return fmt.Sprintf("0x%x", acct.nonce), nil
+	case "eth_getBalance":
+		self.blocks[block]++
+		return fmt.Sprintf("0x%x", acct.balance), nil
+	case "eth_getCode":
+		self.blocks[block]++
+		return "0x" + hex.EncodeToString(acct.code), nil
+	case "eth_getStorageAt":
+		self.blocks[block]++
+		return web3.Hash{}.String(), nil
+	case "eth_getBlockByNumber":
+		var number string
+		json.Unmarshal(params[0], &number)
+		height, ok := new(big.Int).SetString(number[2:], 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid block number %s", number)
+		}
+		self.hashReqs = append(self.hashReqs, height.Uint64())
+		return &web3.Block{Number: height.Uint64(), Hash: testBlockHash(height.Uint64()), Difficulty: big.NewInt(0)}, nil
+	}
+	return nil, fmt.Errorf("method %s not found", method)
+}
+
+func (self *testNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	respond := func(req request) map[string]interface{} {
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		result, err := self.handle(req.Method, req.Params)
+		if err != nil {
+			res["error"] = map[string]interface{}{"code": -32601, "message": err.Error()}
+		} else {
+			res["result"] = result
+		}
+		return res
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	var batch []request
+	if err := json.Unmarshal(body, &batch); err == nil {
+		var res []interface{}
+		for _, req := range batch {
+			res = append(res, respond(req))
+		}
+		json.NewEncoder(w).Encode(res)
+		return
+	}
+	var req request
+	json.Unmarshal(body, &req)
+	json.NewEncoder(w).Encode(respond(req))
+}
+
+func newTestExecutor(t *testing.T, node *testNode, blockNumber uint64) (*Executor, func()) {
+	srv := httptest.NewServer(node)
+	client, err := jsonrpc.NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewExecutorAt(client, blockNumber), srv.Close
+}
+
+func (self *Executor) testBalance(addr web3.Address) *big.Int {
+	return storage.NewStateDB(self.cacheDB, web3.Hash{}, web3.Hash{}).GetBalance(addr)
+}
+
+func TestExecutorAt(t *testing.T) {
+	from, to := web3.BytesToAddress([]byte{0xaa}), web3.BytesToAddress([]byte{0xbb})
+	node := newTestNode(map[web3.Address]*testAccount{from: {nonce: 3, balance: big.NewInt(1000000)}})
+	exec, closeFn := newTestExecutor(t, node, 10)
+	defer closeFn()
+
+	tx := &web3.Transaction{From: from, To: &to, Gas: 21000, Value: big.NewInt(7), Nonce: 3}
+	result, receipt, err := exec.ExecuteTransaction(tx, Eip155Context{Height: 11})
+	assert.NoError(t, err)
+	assert.False(t, result.Failed())
+	assert.Equal(t, uint64(11), receipt.BlockNumber)
+	assert.Equal(t, big.NewInt(999993), exec.testBalance(from))
+	assert.Equal(t, big.NewInt(7), exec.testBalance(to))
+
+	// every state read is served from the pinned block
+	assert.NotEmpty(t, node.blocks)
+	for block := range node.blocks {
+		assert.Equal(t, "0xa", block)
+	}
+}
+
+func TestExecutorBlockHashWindow(t *testing.T) {
+	from, contract := web3.BytesToAddress([]byte{0xaa}), web3.BytesToAddress([]byte{0xbb})
+	// returns the BLOCKHASH of the height given in the calldata
+	code, _ := hex.DecodeString("600035406000526020" + "6000f3")
+	node := newTestNode(map[web3.Address]*testAccount{contract: {code: code}})
+	exec, closeFn := newTestExecutor(t, node, 300)
+	defer closeFn()
+
+	blockHash := func(height uint64) web3.Hash {
+		input := web3.BytesToHash(new(big.Int).SetUint64(height).Bytes())
+		tx := &web3.Transaction{From: from, To: &contract, Gas: 100000, Input: input[:]}
+		result, _, err := exec.ExecuteTransaction(tx, Eip155Context{Height: 301})
+		assert.NoError(t, err)
+		assert.False(t, result.Failed())
+		return web3.BytesToHash(result.ReturnData)
+	}
+
+	assert.Equal(t, testBlockHash(300), blockHash(300))
+	assert.Equal(t, testBlockHash(45), blockHash(45))
+	// out of the 256 blocks window of the pinned block
+	assert.Equal(t, web3.Hash{}, blockHash(44))
+	assert.Equal(t, web3.Hash{}, blockHash(301))
+	// the hashes are fetched once and only within the window
+	assert.Equal(t, testBlockHash(300), blockHash(300))
+	assert.Equal(t, []uint64{300, 45}, node.hashReqs)
+}
+
+func TestExecutorChainID(t *testing.T) {
+	from, contract := web3.BytesToAddress([]byte{0xaa}), web3.BytesToAddress([]byte{0xbb})
+	// returns CHAINID, then a contract using PUSH0 which only exists since Shanghai
+	chainID, _ := hex.DecodeString("4660005260206000f3")
+	push0, _ := hex.DecodeString("5f00")
+	other := web3.BytesToAddress([]byte{0xcc})
+	execute := func(exec *Executor, to web3.Address) *web3.ExecutionResult {
+		tx := &web3.Transaction{From: from, To: &to, Gas: 100000}
+		result, _, err := exec.ExecuteTransaction(tx, Eip155Context{Height: 11})
+		assert.NoError(t, err)
+		return result
+	}
+
+	node := newTestNode(map[web3.Address]*testAccount{contract: {code: chainID}, other: {code: push0}})
+	exec, closeFn := newTestExecutor(t, node, 10)
+	defer closeFn()
+	assert.Equal(t, web3.BytesToHash([]byte{0x05, 0x39}), web3.BytesToHash(execute(exec, contract).ReturnData))
+	assert.False(t, execute(exec, other).Failed())
+
+	// the early blocks of mainnet run frontier
+	node = newTestNode(map[web3.Address]*testAccount{contract: {code: chainID}, other: {code: push0}})
+	node.chainID = 1
+	exec, closeFn = newTestExecutor(t, node, 10)
+	defer closeFn()
+	assert.True(t, execute(exec, contract).Failed())
+	assert.True(t, execute(exec, other).Failed())
+
+	// the chain may be set instead of queried
+	exec, closeFn = newTestExecutor(t, newTestNode(map[web3.Address]*testAccount{contract: {code: chainID}}), 10)
+	defer closeFn()
+	exec.SetChainID(7)
+	assert.Equal(t, web3.BytesToHash([]byte{7}), web3.BytesToHash(execute(exec, contract).ReturnData))
+}
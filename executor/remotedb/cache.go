@@ -0,0 +1,193 @@
+package remotedb
+
+import (
+	"encoding/binary"
+
+	"github.com/boltdb/bolt"
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/utils/codec"
+)
+
+// Cache is a persistent store of the state fetched from the remote node. Entries are keyed by
+// chain id and block number, so only the state of pinned blocks is ever cached.
+type Cache interface {
+	GetAccount(chainID, block uint64, addr web3.Address) (*storage.EthAccount, error)
+	PutAccount(chainID, block uint64, addr web3.Address, acct *storage.EthAccount) error
+	GetStorage(chainID, block uint64, addr web3.Address, slot web3.Hash) (*web3.Hash, error)
+	PutStorage(chainID, block uint64, addr web3.Address, slot web3.Hash, val web3.Hash) error
+	GetBlockHash(chainID, height uint64) (*web3.Hash, error)
+	PutBlockHash(chainID, height uint64, hash web3.Hash) error
+	// PutBatch writes all the accounts and storage slots of the batch at once
+	PutBatch(chainID, block uint64, batch *CacheBatch) error
+	Close() error
+}
+
+// CacheBatch is a set of accounts and storage slots of a block written to the cache together
+type CacheBatch struct {
+	Accounts []CacheAccount
+	Storage  []CacheSlot
+}
+
+// CacheAccount is an account entry of a CacheBatch
+type CacheAccount struct {
+	Addr    web3.Address
+	Account *storage.EthAccount
+}
+
+// CacheSlot is a storage slot entry of a CacheBatch
+type CacheSlot struct {
+	Addr  web3.Address
+	Slot  web3.Hash
+	Value web3.Hash
+}
+
+// PutAccount adds an account to the batch
+func (self *CacheBatch) PutAccount(addr web3.Address, acct *storage.EthAccount) {
+	self.Accounts = append(self.Accounts, CacheAccount{Addr: addr, Account: acct})
+}
+
+// PutStorage adds a storage slot to the batch
+func (self *CacheBatch) PutStorage(addr web3.Address, slot web3.Hash, val web3.Hash) {
+	self.Storage = append(self.Storage, CacheSlot{Addr: addr, Slot: slot, Value: val})
+}
+
+// Len returns the number of entries of the batch
+func (self *CacheBatch) Len() int {
+	return len(self.Accounts) + len(self.Storage)
+}
+
+var _ Cache = (*BoltCache)(nil)
+
+var (
+	dbAccounts    = []byte("accounts")
+	dbStorage     = []byte("storage")
+	dbBlockHashes = []byte("blockhashes")
+)
+
+// BoltCache is a Cache implementation backed by boltdb
+type BoltCache struct {
+	conn *bolt.DB
+}
+
+// NewBoltCache opens or creates the boltdb cache at the given path
+func NewBoltCache(path string) (*BoltCache, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(txn *bolt.Tx) error {
+		for _, name := range [][]byte{dbAccounts, dbStorage, dbBlockHashes} {
+			if _, err := txn.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltCache{conn: db}, nil
+}
+
+// Close implements the Cache interface
+func (self *BoltCache) Close() error {
+	return self.conn.Close()
+}
+
+// GetAccount implements the Cache interface, it returns nil if the account is not cached
+func (self *BoltCache) GetAccount(chainID, block uint64, addr web3.Address) (*storage.EthAccount, error) {
+	val, err := self.get(dbAccounts, cacheKey(chainID, block, addr[:]))
+	if val == nil || err != nil {
+		return nil, err
+	}
+	acct := &storage.EthAccount{}
+	if err := acct.Deserialization(codec.NewZeroCopySource(val)); err != nil {
+		return nil, err
+	}
+	return acct, nil
+}
+
+// PutAccount implements the Cache interface
+func (self *BoltCache) PutAccount(chainID, block uint64, addr web3.Address, acct *storage.EthAccount) error {
+	return self.put(dbAccounts, cacheKey(chainID, block, addr[:]), codec.SerializeToBytes(acct))
+}
+
+// GetStorage implements the Cache interface, it returns nil if the slot is not cached
+func (self *BoltCache) GetStorage(chainID, block uint64, addr web3.Address, slot web3.Hash) (*web3.Hash, error) {
+	val, err := self.get(dbStorage, cacheKey(chainID, block, addr[:], slot[:]))
+	if val == nil || err != nil {
+		return nil, err
+	}
+	hash := web3.BytesToHash(val)
+	return &hash, nil
+}
+
+// PutStorage implements the Cache interface
+func (self *BoltCache) PutStorage(chainID, block uint64, addr web3.Address, slot web3.Hash, val web3.Hash) error {
+	return self.put(dbStorage, cacheKey(chainID, block, addr[:], slot[:]), val[:])
+}
+
+// GetBlockHash implements the Cache interface, it returns nil if the hash is not cached
+func (self *BoltCache) GetBlockHash(chainID, height uint64) (*web3.Hash, error) {
+	val, err := self.get(dbBlockHashes, cacheKey(chainID, height))
+	if val == nil || err != nil {
+		return nil, err
+	}
+	hash := web3.BytesToHash(val)
+	return &hash, nil
+}
+
+// PutBlockHash implements the Cache interface
+func (self *BoltCache) PutBlockHash(chainID, height uint64, hash web3.Hash) error {
+	return self.put(dbBlockHashes, cacheKey(chainID, height), hash[:])
+}
+
+// PutBatch implements the Cache interface, the batch is written in a single transaction
+func (self *BoltCache) PutBatch(chainID, block uint64, batch *CacheBatch) error {
+	return self.conn.Update(func(txn *bolt.Tx) error {
+		accounts, slots := txn.Bucket(dbAccounts), txn.Bucket(dbStorage)
+		for _, entry := range batch.Accounts {
+			key := cacheKey(chainID, block, entry.Addr[:])
+			if err := accounts.Put(key, codec.SerializeToBytes(entry.Account)); err != nil {
+				return err
+			}
+		}
+		for _, entry := range batch.Storage {
+			key := cacheKey(chainID, block, entry.Addr[:], entry.Slot[:])
+			if err := slots.Put(key, entry.Value.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (self *BoltCache) get(bucket, key []byte) ([]byte, error) {
+	var val []byte
+	err := self.conn.View(func(txn *bolt.Tx) error {
+		// the value is only valid during the transaction
+		if v := txn.Bucket(bucket).Get(key); v != nil {
+			val = append([]byte{}, v...)
+		}
+		return nil
+	})
+	return val, err
+}
+
+func (self *BoltCache) put(bucket, key, val []byte) error {
+	return self.conn.Update(func(txn *bolt.Tx) error {
+		return txn.Bucket(bucket).Put(key, val)
+	})
+}
+
+func cacheKey(chainID, block uint64, parts ...[]byte) []byte {
+	key := make([]byte, 16, 16+20+32)
+	binary.BigEndian.PutUint64(key[:8], chainID)
+	binary.BigEndian.PutUint64(key[8:], block)
+	for _, part := range parts {
+		key = append(key, part...)
+	}
+	return key
+}
@@ -0,0 +1,128 @@
+package remotedb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/utils/common/uint256"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCache(t *testing.T) (*BoltCache, func()) {
+	dir, err := ioutil.TempDir("/tmp", "remotedb-cache-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := NewBoltCache(filepath.Join(dir, "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	close := func() {
+		cache.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cache, close
+}
+
+func TestBoltCache(t *testing.T) {
+	cache, close := setupCache(t)
+	defer close()
+
+	addr := web3.HexToAddress("0x1234567890123456789012345678901234567890")
+	slot := web3.BytesToHash([]byte{1})
+
+	acct, err := cache.GetAccount(1, 100, addr)
+	assert.Nil(t, err)
+	assert.Nil(t, acct)
+
+	expected := &storage.EthAccount{
+		Nonce:    3,
+		Balance:  uint256.NewInt().SetUint64(1000),
+		Code:     []byte{0x60, 0x00},
+		CodeHash: web3.BytesToHash([]byte{2}),
+	}
+	assert.Nil(t, cache.PutAccount(1, 100, addr, expected))
+	acct, err = cache.GetAccount(1, 100, addr)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, acct)
+
+	// other blocks and chains are not affected
+	acct, err = cache.GetAccount(1, 101, addr)
+	assert.Nil(t, err)
+	assert.Nil(t, acct)
+	acct, err = cache.GetAccount(5, 100, addr)
+	assert.Nil(t, err)
+	assert.Nil(t, acct)
+
+	val, err := cache.GetStorage(1, 100, addr, slot)
+	assert.Nil(t, err)
+	assert.Nil(t, val)
+	assert.Nil(t, cache.PutStorage(1, 100, addr, slot, web3.Hash{}))
+	val, err = cache.GetStorage(1, 100, addr, slot)
+	assert.Nil(t, err)
+	assert.Equal(t, web3.Hash{}, *val)
+
+	hash, err := cache.GetBlockHash(1, 99)
+	assert.Nil(t, err)
+	assert.Nil(t, hash)
+	assert.Nil(t, cache.PutBlockHash(1, 99, slot))
+	hash, err = cache.GetBlockHash(1, 99)
+	assert.Nil(t, err)
+	assert.Equal(t, slot, *hash)
+}
+
+func TestBoltCacheBatch(t *testing.T) {
+	cache, close := setupCache(t)
+	defer close()
+
+	addr := web3.HexToAddress("0x1234567890123456789012345678901234567890")
+	acct := &storage.EthAccount{Nonce: 1, Balance: uint256.NewInt().SetUint64(5), CodeHash: web3.BytesToHash([]byte{2})}
+	batch := &CacheBatch{}
+	batch.PutAccount(addr, acct)
+	batch.PutStorage(addr, web3.BytesToHash([]byte{1}), web3.BytesToHash([]byte{0x11}))
+	batch.PutStorage(addr, web3.BytesToHash([]byte{2}), web3.Hash{})
+	assert.Equal(t, 3, batch.Len())
+	assert.Nil(t, cache.PutBatch(1, 100, batch))
+
+	cached, err := cache.GetAccount(1, 100, addr)
+	assert.Nil(t, err)
+	assert.Equal(t, acct, cached)
+	for _, entry := range batch.Storage {
+		val, err := cache.GetStorage(1, 100, addr, entry.Slot)
+		assert.Nil(t, err)
+		assert.Equal(t, entry.Value, *val)
+	}
+	val, err := cache.GetStorage(1, 101, addr, web3.BytesToHash([]byte{1}))
+	assert.Nil(t, err)
+	assert.Nil(t, val)
+}
+
+// countingCache counts the writes to the cache
+type countingCache struct {
+	*BoltCache
+	puts    int
+	batches int
+}
+
+func (self *countingCache) PutAccount(chainID, block uint64, addr web3.Address, acct *storage.EthAccount) error {
+	self.puts++
+	return self.BoltCache.PutAccount(chainID, block, addr, acct)
+}
+
+func (self *countingCache) PutStorage(chainID, block uint64, addr web3.Address, slot web3.Hash, val web3.Hash) error {
+	self.puts++
+	return self.BoltCache.PutStorage(chainID, block, addr, slot, val)
+}
+
+func (self *countingCache) PutBatch(chainID, block uint64, batch *CacheBatch) error {
+	self.batches++
+	return self.BoltCache.PutBatch(chainID, block, batch)
+}
@@ -0,0 +1,207 @@
+package remotedb
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/jsonrpc"
+	"github.com/laizy/web3/utils/common/hexutil"
+	"github.com/laizy/web3/utils/common/uint256"
+)
+
+const defaultBatchSize = 100
+
+// accountQuery holds the batch requests loading the nonce, balance and code of an account
+type accountQuery struct {
+	nonce   hexutil.Uint64
+	balance hexutil.Big
+	code    hexutil.Bytes
+	elems   []jsonrpc.BatchElem
+}
+
+func newAccountQuery(addr web3.Address, block web3.BlockNumber) *accountQuery {
+	query := &accountQuery{}
+	query.elems = []jsonrpc.BatchElem{
+		{Method: "eth_getTransactionCount", Params: []interface{}{addr, block.String()}, Result: &query.nonce},
+		{Method: "eth_getBalance", Params: []interface{}{addr, block.String()}, Result: &query.balance},
+		{Method: "eth_getCode", Params: []interface{}{addr, block.String()}, Result: &query.code},
+	}
+	return query
+}
+
+func (self *accountQuery) account() (*storage.EthAccount, error) {
+	for _, elem := range self.elems {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("%s: %w", elem.Method, elem.Error)
+		}
+	}
+	balance, overflow := uint256.FromBig(self.balance.ToInt())
+	if overflow {
+		return nil, fmt.Errorf("balance overflow: %s", self.balance.String())
+	}
+	return &storage.EthAccount{
+		Nonce:    uint64(self.nonce),
+		Balance:  balance,
+		Code:     self.code,
+		CodeHash: crypto.Keccak256Hash(self.code),
+	}, nil
+}
+
+func newStorageElem(skey storageKey, block web3.BlockNumber, val *web3.Hash) jsonrpc.BatchElem {
+	slot := fmt.Sprintf("0x%x", new(big.Int).SetBytes(skey.Key[:]))
+	return jsonrpc.BatchElem{
+		Method: "eth_getStorageAt",
+		Params: []interface{}{skey.Addr, slot, block.String()},
+		Result: val,
+	}
+}
+
+// Prefetch loads the accounts and storage slots of the access list with batched requests, so that
+// later reads are served from memory. Entries already loaded are skipped, the batches are sent
+// concurrently with at most BatchSize requests each. In verified mode the entries are loaded with
+// eth_getProof instead.
+func (self *RemoteDB) Prefetch(list web3.AccessList) error {
+	var addrs []web3.Address
+	var skeys []storageKey
+	seenAddr := make(map[web3.Address]bool)
+	seenKey := make(map[storageKey]bool)
+	for _, entry := range list {
+		if !seenAddr[entry.Address] {
+			seenAddr[entry.Address] = true
+			acct, err := self.lookupAccount(entry.Address)
+			if err != nil {
+				return err
+			}
+			if acct == nil {
+				addrs = append(addrs, entry.Address)
+			}
+		}
+		for _, key := range entry.Storage {
+			skey := storageKey{Addr: entry.Address, Key: key}
+			if seenKey[skey] {
+				continue
+			}
+			seenKey[skey] = true
+			_, ok, err := self.lookupStorage(skey)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				skeys = append(skeys, skey)
+			}
+		}
+	}
+
+	size := self.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	if self.verified() {
+		return self.prefetchVerified(addrs, skeys, size)
+	}
+	// an account takes three requests
+	var batches []*prefetchBatch
+	batch := &prefetchBatch{}
+	for _, addr := range addrs {
+		if batch.size()+3 > size && batch.size() != 0 {
+			batches, batch = append(batches, batch), &prefetchBatch{}
+		}
+		batch.addrs = append(batch.addrs, addr)
+	}
+	for _, skey := range skeys {
+		if batch.size()+1 > size && batch.size() != 0 {
+			batches, batch = append(batches, batch), &prefetchBatch{}
+		}
+		batch.skeys = append(batch.skeys, skey)
+	}
+	if batch.size() != 0 {
+		batches = append(batches, batch)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(batches))
+	for i, batch := range batches {
+		wg.Add(1)
+		go func(i int, batch *prefetchBatch) {
+			defer wg.Done()
+			errs[i] = batch.load(self)
+		}(i, batch)
+	}
+	wg.Wait()
+
+	loaded := &CacheBatch{}
+	for i, batch := range batches {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		for j, addr := range batch.addrs {
+			loaded.PutAccount(addr, batch.accounts[j])
+		}
+		for j, skey := range batch.skeys {
+			loaded.PutStorage(skey.Addr, skey.Key, batch.values[j])
+		}
+	}
+	return self.storeBatch(loaded)
+}
+
+// prefetchBatch is a set of accounts and storage slots loaded in a single batch call
+type prefetchBatch struct {
+	addrs    []web3.Address
+	skeys    []storageKey
+	accounts []*storage.EthAccount
+	values   []web3.Hash
+}
+
+func (self *prefetchBatch) size() int {
+	return 3*len(self.addrs) + len(self.skeys)
+}
+
+func (self *prefetchBatch) load(db *RemoteDB) error {
+	type loaded struct {
+		accounts []*storage.EthAccount
+		values   []web3.Hash
+	}
+	res, err := db.withRetry(func(ctx context.Context) (interface{}, error) {
+		queries := make([]*accountQuery, len(self.addrs))
+		values := make([]web3.Hash, len(self.skeys))
+		var elems []jsonrpc.BatchElem
+		for i, addr := range self.addrs {
+			queries[i] = newAccountQuery(addr, db.block)
+			elems = append(elems, queries[i].elems...)
+		}
+		for i, skey := range self.skeys {
+			elems = append(elems, newStorageElem(skey, db.block, &values[i]))
+		}
+		if err := db.client.BatchCallContext(ctx, elems); err != nil {
+			return nil, err
+		}
+
+		res := &loaded{values: values}
+		for i, query := range queries {
+			// the requests were sent as copies in elems, collect their errors back
+			copy(query.elems, elems[3*i:3*i+3])
+			acct, err := query.account()
+			if err != nil {
+				return nil, err
+			}
+			res.accounts = append(res.accounts, acct)
+		}
+		for i := 3 * len(queries); i < len(elems); i++ {
+			if err := elems[i].Error; err != nil {
+				return nil, fmt.Errorf("eth_getStorageAt: %w", err)
+			}
+		}
+		return res, nil
+	})
+	if err != nil {
+		return fmt.Errorf("prefetch: %w", err)
+	}
+	self.accounts = res.(*loaded).accounts
+	self.values = res.(*loaded).values
+	return nil
+}
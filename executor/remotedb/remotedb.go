@@ -1,117 +1,338 @@
 package remotedb
 
 import (
-	"encoding/hex"
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/laizy/web3"
-	"github.com/laizy/web3/crypto"
 	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/evm/storage/overlaydb"
 	"github.com/laizy/web3/evm/storage/schema"
 	"github.com/laizy/web3/jsonrpc"
+	rpccodec "github.com/laizy/web3/jsonrpc/codec"
 	"github.com/laizy/web3/utils"
 	"github.com/laizy/web3/utils/codec"
-	"github.com/laizy/web3/utils/common/uint256"
 )
 
+// blockHashWindow is the number of recent block hashes available to the BLOCKHASH opcode
+const blockHashWindow = 256
+
 type RemoteDB struct {
-	Trace    bool
-	client   *jsonrpc.Client
-	Accounts map[web3.Address]*storage.EthAccount
-	Storage  map[storageKey]web3.Hash
+	Trace       bool
+	client      *jsonrpc.Client
+	block       web3.BlockNumber
+	cache       Cache
+	chainID     uint64
+	Accounts    map[web3.Address]*storage.EthAccount
+	Storage     map[storageKey]web3.Hash
+	BlockHashes map[uint64]web3.Hash
+	// BatchSize is the maximum number of requests sent in one batch by Prefetch
+	BatchSize int
+	// Retries is the number of times a failed remote request is retried
+	Retries int
+	// RetryDelay is the delay before retrying a failed remote request
+	RetryDelay time.Duration
+	// Timeout is the timeout of a single remote request, zero means no timeout
+	Timeout time.Duration
+	// StorageRange enables loading the whole storage of a contract with debug_storageRangeAt
+	// when it is iterated, otherwise only the slots read so far are iterated
+	StorageRange bool
+
+	// state root the remote reads are verified against, nil if not verified
+	stateRoot *web3.Hash
+	// local write layer on top of the remote state
+	writes *overlaydb.MemDB
 }
 
+// NewRemoteDB creates a remote db reading the state of the latest block
 func NewRemoteDB(client *jsonrpc.Client) *RemoteDB {
+	return newRemoteDB(client, web3.Latest)
+}
+
+// NewRemoteDBAt creates a remote db reading the state pinned at the given block
+func NewRemoteDBAt(client *jsonrpc.Client, blockNumber uint64) *RemoteDB {
+	return newRemoteDB(client, web3.BlockNumber(blockNumber))
+}
+
+func newRemoteDB(client *jsonrpc.Client, block web3.BlockNumber) *RemoteDB {
 	return &RemoteDB{
-		client:   client,
-		Accounts: make(map[web3.Address]*storage.EthAccount),
-		Storage:  make(map[storageKey]web3.Hash),
+		client:      client,
+		block:       block,
+		Accounts:    make(map[web3.Address]*storage.EthAccount),
+		Storage:     make(map[storageKey]web3.Hash),
+		BlockHashes: make(map[uint64]web3.Hash),
+		BatchSize:   defaultBatchSize,
+		writes:      overlaydb.NewMemDB(0, 0),
 	}
 }
 
+// SetCache sets the persistent cache of fetched state. The cache is only used when the db is pinned
+// to a block, since the state of the latest block keeps changing.
+func (self *RemoteDB) SetCache(cache Cache, chainID uint64) {
+	self.cache = cache
+	self.chainID = chainID
+}
+
+// Cache returns the persistent cache, nil if not set
+func (self *RemoteDB) Cache() Cache {
+	return self.cache
+}
+
+// cached reports whether the persistent cache is used
+func (self *RemoteDB) cached() bool {
+	return self.cache != nil && self.block != web3.Latest
+}
+
+// BlockNumber returns the block all remote reads are served from, web3.Latest if not pinned
+func (self *RemoteDB) BlockNumber() web3.BlockNumber {
+	return self.block
+}
+
+// ErrTimeout is returned when a remote request does not complete within the timeout
+var ErrTimeout = errors.New("remote request timeout")
+
+// withRetry runs the remote request, retrying it on failure. Errors returned by the node
+// itself are not retried since they are not transient.
+func (self *RemoteDB) withRetry(request func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	var err error
+	for attempt := 0; attempt <= self.Retries; attempt++ {
+		if attempt > 0 && self.RetryDelay > 0 {
+			time.Sleep(self.RetryDelay)
+		}
+		var res interface{}
+		res, err = self.withTimeout(request)
+		if err == nil {
+			return res, nil
+		}
+		var rpcErr *rpccodec.ErrorObject
+		if errors.As(err, &rpcErr) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// withTimeout runs the remote request with a context bounded by the timeout, so that a request
+// which times out is aborted instead of being left running.
+func (self *RemoteDB) withTimeout(request func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if self.Timeout <= 0 {
+		return request(context.Background())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), self.Timeout)
+	defer cancel()
+	res, err := request(ctx)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	return res, err
+}
+
 type storageKey struct {
 	Addr web3.Address
 	Key  web3.Hash
 }
 
-func (self *RemoteDB) GetAccount(addr web3.Address) *storage.EthAccount {
-	if acc := self.Accounts[addr]; acc != nil {
-		return acc
+func (self *RemoteDB) GetAccount(addr web3.Address) (*storage.EthAccount, error) {
+	acct, err := self.lookupAccount(addr)
+	if acct != nil || err != nil {
+		return acct, err
+	}
+	if self.verified() {
+		if err := self.loadVerified([]*proofQuery{newProofQuery(addr, nil, self.block, true)}); err != nil {
+			return nil, fmt.Errorf("get account %s: %w", addr, err)
+		}
+		return self.Accounts[addr], nil
 	}
 
-	nonce, err := self.client.Eth().GetNonce(addr, web3.Latest)
-	utils.Ensure(err)
-	balance, err := self.client.Eth().GetBalance(addr, web3.Latest)
-	utils.Ensure(err)
-	code, err := self.client.Eth().GetCode(addr)
-	utils.Ensure(err)
-	codeRaw, err := hex.DecodeString(code[2:])
-	utils.Ensure(err)
-	hash := crypto.Keccak256Hash(codeRaw)
-	bal, _ := uint256.FromBig(balance)
-	acct := &storage.EthAccount{
-		Nonce:    nonce,
-		Balance:  bal,
-		Code:     codeRaw,
-		CodeHash: hash,
+	res, err := self.withRetry(func(ctx context.Context) (interface{}, error) {
+		query := newAccountQuery(addr, self.block)
+		if err := self.client.BatchCallContext(ctx, query.elems); err != nil {
+			return nil, err
+		}
+		return query.account()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get account %s: %w", addr, err)
+	}
+	acct = res.(*storage.EthAccount)
+	if err := self.storeAccount(addr, acct); err != nil {
+		return nil, err
 	}
 
-	if self.Trace {
-		fmt.Printf("[remotedb] get account %s: %s\n", addr, utils.JsonString(acct))
+	return acct, nil
+}
+
+// lookupAccount returns the account if it is already loaded or cached, nil otherwise
+func (self *RemoteDB) lookupAccount(addr web3.Address) (*storage.EthAccount, error) {
+	if acct := self.Accounts[addr]; acct != nil {
+		return acct, nil
+	}
+	if self.cached() {
+		acct, err := self.cache.GetAccount(self.chainID, uint64(self.block), addr)
+		if err != nil {
+			return nil, err
+		}
+		if acct != nil {
+			self.Accounts[addr] = acct
+			return acct, nil
+		}
 	}
+	return nil, nil
+}
 
-	self.Accounts[addr] = acct
-	return acct
+func (self *RemoteDB) storeAccount(addr web3.Address, acct *storage.EthAccount) error {
+	batch := &CacheBatch{}
+	batch.PutAccount(addr, acct)
+	return self.storeBatch(batch)
 }
 
-func (self *RemoteDB) GetStorage(addr web3.Address, key web3.Hash) web3.Hash {
+func (self *RemoteDB) GetStorage(addr web3.Address, key web3.Hash) (web3.Hash, error) {
 	skey := storageKey{Addr: addr, Key: key}
+	val, ok, err := self.lookupStorage(skey)
+	if ok || err != nil {
+		return val, err
+	}
+	if self.verified() {
+		acct, err := self.lookupAccount(addr)
+		if err != nil {
+			return web3.Hash{}, err
+		}
+		query := newProofQuery(addr, []web3.Hash{key}, self.block, acct == nil)
+		if err := self.loadVerified([]*proofQuery{query}); err != nil {
+			return web3.Hash{}, fmt.Errorf("get storage %s %s: %w", addr, key, err)
+		}
+		return self.Storage[skey], nil
+	}
+
+	res, err := self.withRetry(func(ctx context.Context) (interface{}, error) {
+		return self.client.Eth().GetStorageContext(ctx, addr, key, self.block)
+	})
+	if err != nil {
+		return web3.Hash{}, fmt.Errorf("get storage %s %s: %w", addr, key, err)
+	}
+	val = res.(web3.Hash)
+	if err := self.storeStorage(skey, val); err != nil {
+		return web3.Hash{}, err
+	}
+
+	return val, nil
+}
+
+// lookupStorage returns the storage value if it is already loaded or cached
+func (self *RemoteDB) lookupStorage(skey storageKey) (web3.Hash, bool, error) {
 	if val, ok := self.Storage[skey]; ok {
-		return val
+		return val, true, nil
+	}
+	if self.cached() {
+		val, err := self.cache.GetStorage(self.chainID, uint64(self.block), skey.Addr, skey.Key)
+		if err != nil {
+			return web3.Hash{}, false, err
+		}
+		if val != nil {
+			self.Storage[skey] = *val
+			return *val, true, nil
+		}
 	}
+	return web3.Hash{}, false, nil
+}
 
-	val, err := self.client.Eth().GetStorage(addr, key, web3.Latest)
-	utils.Ensure(err)
-	self.Storage[skey] = val
+func (self *RemoteDB) storeStorage(skey storageKey, val web3.Hash) error {
+	batch := &CacheBatch{}
+	batch.PutStorage(skey.Addr, skey.Key, val)
+	return self.storeBatch(batch)
+}
 
+// storeBatch keeps the loaded accounts and storage slots in memory, writing them to the cache in
+// a single batch
+func (self *RemoteDB) storeBatch(batch *CacheBatch) error {
 	if self.Trace {
-		fmt.Printf("[remotedb] get storage, contract: %s, key: %s, value:%s\n", addr, key, val)
+		for _, entry := range batch.Accounts {
+			fmt.Printf("[remotedb] get account %s: %s\n", entry.Addr, utils.JsonString(entry.Account))
+		}
+		for _, entry := range batch.Storage {
+			fmt.Printf("[remotedb] get storage, contract: %s, key: %s, value:%s\n", entry.Addr, entry.Slot, entry.Value)
+		}
 	}
-
-	return val
+	if self.cached() && batch.Len() != 0 {
+		if err := self.cache.PutBatch(self.chainID, uint64(self.block), batch); err != nil {
+			return err
+		}
+	}
+	for _, entry := range batch.Accounts {
+		self.Accounts[entry.Addr] = entry.Account
+	}
+	for _, entry := range batch.Storage {
+		self.Storage[storageKey{Addr: entry.Addr, Key: entry.Slot}] = entry.Value
+	}
+	return nil
 }
 
 func (self *RemoteDB) Get(key []byte) ([]byte, error) {
+	if val, unknown := self.writes.Get(key); !unknown {
+		return val, nil
+	}
 	switch schema.DataEntryPrefix(key[0]) {
 	case schema.ST_ETH_ACCOUNT:
 		addr := web3.BytesToAddress(key[1:])
-		acct := self.GetAccount(addr)
+		acct, err := self.GetAccount(addr)
+		if err != nil {
+			return nil, err
+		}
 		return codec.SerializeToBytes(acct), nil
 	case schema.ST_STORAGE:
 		addr := web3.BytesToAddress(key[1:21])
 		key := web3.BytesToHash(key[21:])
 
-		val := self.GetStorage(addr, key)
+		val, err := self.GetStorage(addr, key)
+		if err != nil {
+			return nil, err
+		}
 		return val.Bytes(), nil
 	default:
-		panic("unkown key prefix")
+		return nil, fmt.Errorf("unknown key prefix: %d", key[0])
 	}
 }
 
-func (self *RemoteDB) BatchPut(key []byte, value []byte) {
-	panic("todo")
-}
-
-func (self *RemoteDB) BatchDelete(key []byte) {
-	panic("todo")
-}
-
-func (self *RemoteDB) NewIterator(prefix []byte) schema.StoreIterator {
-	panic("todo")
-}
-
-func (self *RemoteDB) GetBlockHash(height uint64) web3.Hash {
-	block, err := self.client.Eth().GetBlockByNumber(web3.BlockNumber(height), false)
-	utils.Ensure(err)
-	return block.Hash
+// GetBlockHash returns the hash of the block at the given height. When the db is pinned, only
+// the 256 blocks up to and including the pinned block are served, as seen by the BLOCKHASH
+// opcode of the block following it, other heights return the empty hash.
+func (self *RemoteDB) GetBlockHash(height uint64) (web3.Hash, error) {
+	if self.block != web3.Latest {
+		pinned := uint64(self.block)
+		if height > pinned || height+blockHashWindow <= pinned {
+			return web3.Hash{}, nil
+		}
+	}
+	if hash, ok := self.BlockHashes[height]; ok {
+		return hash, nil
+	}
+	if self.cached() {
+		hash, err := self.cache.GetBlockHash(self.chainID, height)
+		if err != nil {
+			return web3.Hash{}, err
+		}
+		if hash != nil {
+			self.BlockHashes[height] = *hash
+			return *hash, nil
+		}
+	}
+	res, err := self.withRetry(func(ctx context.Context) (interface{}, error) {
+		return self.client.Eth().GetBlockByNumberContext(ctx, web3.BlockNumber(height), false)
+	})
+	if err != nil {
+		return web3.Hash{}, fmt.Errorf("get block hash %d: %w", height, err)
+	}
+	block := res.(*web3.Block)
+	if block == nil {
+		return web3.Hash{}, nil
+	}
+	if self.cached() {
+		if err := self.cache.PutBlockHash(self.chainID, height, block.Hash); err != nil {
+			return web3.Hash{}, err
+		}
+	}
+	self.BlockHashes[height] = block.Hash
+	return block.Hash, nil
 }
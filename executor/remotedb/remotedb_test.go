@@ -0,0 +1,192 @@
+package remotedb
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/evm/storage/overlaydb"
+	"github.com/laizy/web3/evm/storage/schema"
+	"github.com/laizy/web3/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const storageValue = "0x0000000000000000000000000000000000000000000000000000000000000007"
+
+func newTestRemoteDB(t *testing.T, handler http.HandlerFunc) (*RemoteDB, func()) {
+	srv := httptest.NewServer(handler)
+	client, err := jsonrpc.NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRemoteDBAt(client, 10), srv.Close
+}
+
+func TestRemoteDBRetry(t *testing.T) {
+	var calls int32
+	db, close := newTestRemoteDB(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":3,"result":"` + storageValue + `"}`))
+	})
+	defer close()
+
+	addr, slot := web3.Address{0x1}, web3.Hash{0x2}
+	_, err := db.GetStorage(addr, slot)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	db.Retries = 2
+	val, err := db.GetStorage(addr, slot)
+	assert.NoError(t, err)
+	assert.Equal(t, web3.HexToHash(storageValue), val)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// served from memory
+	_, err = db.GetStorage(addr, slot)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRemoteDBNodeErrorNotRetried(t *testing.T) {
+	var calls int32
+	db, close := newTestRemoteDB(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"missing trie node"}}`))
+	})
+	defer close()
+
+	db.Retries = 3
+	_, err := db.Get(append([]byte{0x05}, make([]byte, 52)...))
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRemoteDBTimeout(t *testing.T) {
+	aborted := make(chan struct{})
+	db, close := newTestRemoteDB(t, func(w http.ResponseWriter, r *http.Request) {
+		// the server notices the client going away only once the body is read
+		ioutil.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+			close(aborted)
+		case <-time.After(2 * time.Second):
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + storageValue + `"}`))
+		}
+	})
+	defer close()
+
+	db.Timeout = 20 * time.Millisecond
+	_, err := db.GetStorage(web3.Address{0x1}, web3.Hash{0x2})
+	assert.True(t, errors.Is(err, ErrTimeout))
+
+	// the request is aborted rather than left running
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("request not aborted")
+	}
+}
+
+func TestRemoteDBIterator(t *testing.T) {
+	db := NewRemoteDBAt(nil, 10)
+	addr, other := web3.Address{0x1}, web3.Address{0x2}
+	db.Storage[storageKey{Addr: addr, Key: web3.Hash{0x1}}] = web3.Hash{0x11}
+	db.Storage[storageKey{Addr: addr, Key: web3.Hash{0x2}}] = web3.Hash{0x22}
+	db.Storage[storageKey{Addr: addr, Key: web3.Hash{0x3}}] = web3.Hash{}
+	db.Storage[storageKey{Addr: other, Key: web3.Hash{0x1}}] = web3.Hash{0x33}
+
+	storageKeyOf := func(addr web3.Address, key web3.Hash) []byte {
+		return append(append([]byte{byte(schema.ST_STORAGE)}, addr[:]...), key[:]...)
+	}
+	db.BatchPut(storageKeyOf(addr, web3.Hash{0x4}), web3.Hash{0x44}.Bytes())
+	db.BatchDelete(storageKeyOf(addr, web3.Hash{0x2}))
+
+	val, err := db.Get(storageKeyOf(addr, web3.Hash{0x2}))
+	assert.NoError(t, err)
+	assert.Empty(t, val)
+
+	var keys []web3.Hash
+	iter := db.NewIterator(append([]byte{byte(schema.ST_STORAGE)}, addr[:]...))
+	for has := iter.First(); has; has = iter.Next() {
+		keys = append(keys, web3.BytesToHash(iter.Key()[1+web3.AddressLength:]))
+	}
+	iter.Release()
+	assert.NoError(t, iter.Error())
+	assert.Equal(t, []web3.Hash{{0x1}, {0x4}}, keys)
+
+	// cleaning the storage of a self destructed contract
+	cache := storage.NewCacheDB(overlaydb.NewOverlayDB(db))
+	assert.NoError(t, cache.CleanContractStorageData(addr))
+	cache.Commit()
+	statedb := storage.NewStateDB(cache, web3.Hash{}, web3.Hash{})
+	assert.Equal(t, web3.Hash{}, statedb.GetState(addr, web3.Hash{0x1}))
+	assert.Equal(t, web3.Hash{}, statedb.GetState(addr, web3.Hash{0x4}))
+	assert.Equal(t, web3.Hash{0x33}, statedb.GetState(other, web3.Hash{0x1}))
+}
+
+func TestRemoteDBPrefetchCache(t *testing.T) {
+	db, close := newTestRemoteDB(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqs []struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &reqs)
+		var res []string
+		for _, req := range reqs {
+			result := `"0x1"`
+			switch req.Method {
+			case "eth_getCode":
+				result = `"0x"`
+			case "eth_getStorageAt":
+				result = `"` + storageValue + `"`
+			}
+			res = append(res, `{"jsonrpc":"2.0","id":`+string(req.ID)+`,"result":`+result+`}`)
+		}
+		w.Write([]byte("[" + strings.Join(res, ",") + "]"))
+	})
+	defer close()
+	bolt, closeCache := setupCache(t)
+	defer closeCache()
+	cache := &countingCache{BoltCache: bolt}
+	db.SetCache(cache, 1)
+
+	// spread over several concurrent batches
+	db.BatchSize = 4
+	addrs := []web3.Address{{0x1}, {0x2}}
+	list := web3.AccessList{
+		{Address: addrs[0], Storage: []web3.Hash{{0x1}, {0x2}, {0x3}}},
+		{Address: addrs[1], Storage: []web3.Hash{{0x4}}},
+	}
+	assert.NoError(t, db.Prefetch(list))
+	assert.Equal(t, 1, cache.batches)
+	assert.Equal(t, 0, cache.puts)
+
+	// every entry is served from the cache by a new db
+	other, closeOther := newTestRemoteDB(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("unexpected remote request")
+	})
+	defer closeOther()
+	other.SetCache(cache, 1)
+	for _, entry := range list {
+		acct, err := other.GetAccount(entry.Address)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), acct.Nonce)
+		for _, slot := range entry.Storage {
+			val, err := other.GetStorage(entry.Address, slot)
+			assert.NoError(t, err)
+			assert.Equal(t, web3.HexToHash(storageValue), val)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package remotedb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/storage/overlaydb"
+	"github.com/laizy/web3/evm/storage/schema"
+	"github.com/laizy/web3/jsonrpc"
+	"github.com/laizy/web3/utils/codec"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// storageRangePageSize is the number of slots requested per debug_storageRangeAt call
+const storageRangePageSize = 1024
+
+// BatchPut implements the schema.PersistStore interface, the value is written to the local
+// write layer and the remote state is left untouched.
+func (self *RemoteDB) BatchPut(key []byte, value []byte) {
+	self.writes.Put(key, value)
+}
+
+// BatchDelete implements the schema.PersistStore interface, the key is deleted in the local
+// write layer only.
+func (self *RemoteDB) BatchDelete(key []byte) {
+	self.writes.Delete(key)
+}
+
+// NewIterator implements the schema.PersistStore interface. It iterates over the remote entries
+// known so far merged with the local writes. When StorageRange is enabled, iterating over the
+// storage of a contract first loads all of its slots from the remote node.
+func (self *RemoteDB) NewIterator(prefix []byte) schema.StoreIterator {
+	remote := overlaydb.NewMemDB(0, 0)
+	var err error
+	if self.StorageRange && len(prefix) == 1+web3.AddressLength && schema.DataEntryPrefix(prefix[0]) == schema.ST_STORAGE {
+		err = self.loadStorageRange(web3.BytesToAddress(prefix[1:]))
+	}
+
+	for addr, acct := range self.Accounts {
+		key := append([]byte{byte(schema.ST_ETH_ACCOUNT)}, addr[:]...)
+		if bytes.HasPrefix(key, prefix) {
+			remote.Put(key, codec.SerializeToBytes(acct))
+		}
+	}
+	for skey, val := range self.Storage {
+		if val == (web3.Hash{}) {
+			continue
+		}
+		key := make([]byte, 0, 1+web3.AddressLength+web3.HashLength)
+		key = append(key, byte(schema.ST_STORAGE))
+		key = append(key, skey.Addr[:]...)
+		key = append(key, skey.Key[:]...)
+		if bytes.HasPrefix(key, prefix) {
+			remote.Put(key, val[:])
+		}
+	}
+
+	prefixRange := util.BytesPrefix(prefix)
+	iter := overlaydb.NewJoinIter(self.writes.NewIterator(prefixRange), remote.NewIterator(prefixRange))
+	if err != nil {
+		return &errIterator{iter, err}
+	}
+	return iter
+}
+
+// loadStorageRange loads all the storage slots of the contract with debug_storageRangeAt. The
+// state of the pinned block is the state before the first transaction of the next block. Slots
+// whose key preimage is unknown to the node can not be loaded and are skipped.
+func (self *RemoteDB) loadStorageRange(addr web3.Address) error {
+	if self.block == web3.Latest {
+		return fmt.Errorf("debug_storageRangeAt requires a pinned block")
+	}
+	res, err := self.withRetry(func(ctx context.Context) (interface{}, error) {
+		return self.client.Eth().GetBlockByNumberContext(ctx, self.block+1, false)
+	})
+	if err != nil {
+		return err
+	}
+	next := res.(*web3.Block)
+	if next == nil {
+		return fmt.Errorf("block %d not found, debug_storageRangeAt needs the block after the pinned one", self.block+1)
+	}
+
+	start := web3.Hash{}
+	for {
+		res, err := self.withRetry(func(ctx context.Context) (interface{}, error) {
+			return self.client.Debug().StorageRangeAtContext(ctx, next.Hash, 0, addr, start, storageRangePageSize)
+		})
+		if err != nil {
+			return fmt.Errorf("debug_storageRangeAt %s: %w", addr, err)
+		}
+		page := res.(*jsonrpc.StorageRangeResult)
+		var unverified []web3.Hash
+		batch := &CacheBatch{}
+		for _, entry := range page.Storage {
+			if entry.Key == nil {
+				continue
+			}
+			skey := storageKey{Addr: addr, Key: *entry.Key}
+			if _, ok := self.Storage[skey]; ok {
+				continue
+			}
+			if self.verified() {
+				unverified = append(unverified, skey.Key)
+				continue
+			}
+			batch.PutStorage(addr, skey.Key, entry.Value)
+		}
+		if err := self.storeBatch(batch); err != nil {
+			return err
+		}
+		if len(unverified) != 0 {
+			// the slot keys come from the debug api, the values are fetched again with their proofs
+			if err := self.loadVerified([]*proofQuery{newProofQuery(addr, unverified, self.block, false)}); err != nil {
+				return err
+			}
+		}
+		if page.NextKey == nil {
+			return nil
+		}
+		start = *page.NextKey
+	}
+}
+
+// errIterator is an iterator which reports an error met while loading its entries
+type errIterator struct {
+	schema.StoreIterator
+	err error
+}
+
+func (self *errIterator) Error() error {
+	return self.err
+}
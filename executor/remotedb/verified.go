@@ -0,0 +1,230 @@
+package remotedb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/jsonrpc"
+	"github.com/laizy/web3/trie"
+	"github.com/laizy/web3/utils/common/hexutil"
+	"github.com/laizy/web3/utils/common/uint256"
+)
+
+// SetStateRoot enables the verified mode, accounts and storage slots are then fetched with
+// eth_getProof and checked against the state root, and contract code is checked against the
+// proven code hash. The root must come from a trusted source, e.g. a block header checked by a
+// light client, since the remote node is not trusted. The db must be pinned to the block of the
+// state root. Entries already loaded or served by the persistent cache are not verified again.
+func (self *RemoteDB) SetStateRoot(root web3.Hash) error {
+	if self.block == web3.Latest {
+		return errors.New("verified mode requires a pinned block")
+	}
+	self.stateRoot = &root
+	return nil
+}
+
+// verified reports whether the remote reads are checked against a state root
+func (self *RemoteDB) verified() bool {
+	return self.stateRoot != nil
+}
+
+// proofQuery holds the batch requests loading the proof of an account and some of its storage
+// slots, and the code of the account if it is not loaded yet
+type proofQuery struct {
+	addr  web3.Address
+	slots []web3.Hash
+	proof *web3.AccountProof
+	code  hexutil.Bytes
+	elems []jsonrpc.BatchElem
+}
+
+func newProofQuery(addr web3.Address, slots []web3.Hash, block web3.BlockNumber, withCode bool) *proofQuery {
+	query := &proofQuery{addr: addr, slots: slots}
+	params := slots
+	if params == nil {
+		params = []web3.Hash{}
+	}
+	query.elems = []jsonrpc.BatchElem{
+		{Method: "eth_getProof", Params: []interface{}{addr, params, block.String()}, Result: &query.proof},
+	}
+	if withCode {
+		query.elems = append(query.elems, jsonrpc.BatchElem{
+			Method: "eth_getCode", Params: []interface{}{addr, block.String()}, Result: &query.code,
+		})
+	}
+	return query
+}
+
+// verify checks the proof against the state root, it returns the account, nil if the code was
+// not requested, and the values of the slots.
+func (self *proofQuery) verify(root web3.Hash) (*storage.EthAccount, []web3.Hash, error) {
+	for _, elem := range self.elems {
+		if elem.Error != nil {
+			return nil, nil, fmt.Errorf("%s: %w", elem.Method, elem.Error)
+		}
+	}
+	proof := self.proof
+	if proof == nil || proof.Address != self.addr || len(proof.StorageProof) != len(self.slots) {
+		return nil, nil, fmt.Errorf("account %s: %w: proof does not match the request", self.addr, trie.ErrInvalidProof)
+	}
+	for i, slot := range self.slots {
+		if proof.StorageProof[i].Key != slot {
+			return nil, nil, fmt.Errorf("account %s: %w: proof does not match the request", self.addr, trie.ErrInvalidProof)
+		}
+	}
+	if err := trie.VerifyAccountProof(root, proof); err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]web3.Hash, len(self.slots))
+	for i, slot := range proof.StorageProof {
+		values[i] = web3.BytesToHash(slot.Value.Bytes())
+	}
+	if len(self.elems) == 1 {
+		return nil, values, nil
+	}
+
+	codeHash := crypto.Keccak256Hash(self.code)
+	expected := proof.CodeHash
+	if expected == (web3.Hash{}) {
+		expected = crypto.Keccak256Hash(nil)
+	}
+	if codeHash != expected {
+		return nil, nil, fmt.Errorf("account %s: %w: code does not match the code hash", self.addr, trie.ErrInvalidProof)
+	}
+	balance, overflow := uint256.FromBig(proof.Balance)
+	if overflow {
+		return nil, nil, fmt.Errorf("balance overflow: %s", proof.Balance)
+	}
+	acct := &storage.EthAccount{
+		Nonce:    proof.Nonce,
+		Balance:  balance,
+		Code:     self.code,
+		CodeHash: codeHash,
+	}
+	return acct, values, nil
+}
+
+// verifiedResult holds the verified accounts and slot values of a list of proof queries
+type verifiedResult struct {
+	accounts []*storage.EthAccount
+	values   [][]web3.Hash
+}
+
+// loadVerified fetches and verifies the accounts and storage slots, then stores them
+func (self *RemoteDB) loadVerified(queries []*proofQuery) error {
+	res, err := self.fetchVerified(queries)
+	if err != nil {
+		return err
+	}
+	return self.storeVerified(queries, res)
+}
+
+func (self *RemoteDB) fetchVerified(queries []*proofQuery) (*verifiedResult, error) {
+	res, err := self.withRetry(func(ctx context.Context) (interface{}, error) {
+		var elems []jsonrpc.BatchElem
+		fresh := make([]*proofQuery, len(queries))
+		for i, query := range queries {
+			// requests must not share state between attempts
+			fresh[i] = newProofQuery(query.addr, query.slots, self.block, len(query.elems) == 2)
+			elems = append(elems, fresh[i].elems...)
+		}
+		if err := self.client.BatchCallContext(ctx, elems); err != nil {
+			return nil, err
+		}
+		res := &verifiedResult{}
+		offset := 0
+		for _, query := range fresh {
+			// the requests were sent as copies in elems, collect their errors back
+			copy(query.elems, elems[offset:offset+len(query.elems)])
+			offset += len(query.elems)
+			acct, values, err := query.verify(*self.stateRoot)
+			if err != nil {
+				return nil, err
+			}
+			res.accounts = append(res.accounts, acct)
+			res.values = append(res.values, values)
+		}
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*verifiedResult), nil
+}
+
+func (self *RemoteDB) storeVerified(queries []*proofQuery, res *verifiedResult) error {
+	batch := &CacheBatch{}
+	for i, query := range queries {
+		if acct := res.accounts[i]; acct != nil {
+			batch.PutAccount(query.addr, acct)
+		}
+		for j, slot := range query.slots {
+			batch.PutStorage(query.addr, slot, res.values[i][j])
+		}
+	}
+	return self.storeBatch(batch)
+}
+
+// prefetchVerified loads the accounts and storage slots with batched eth_getProof requests, one
+// per account holding all of its slots
+func (self *RemoteDB) prefetchVerified(addrs []web3.Address, skeys []storageKey, size int) error {
+	var queries []*proofQuery
+	byAddr := make(map[web3.Address]*proofQuery)
+	query := func(addr web3.Address, withCode bool) *proofQuery {
+		if q := byAddr[addr]; q != nil {
+			return q
+		}
+		q := newProofQuery(addr, nil, self.block, withCode)
+		byAddr[addr] = q
+		queries = append(queries, q)
+		return q
+	}
+	for _, addr := range addrs {
+		query(addr, true)
+	}
+	for _, skey := range skeys {
+		q := query(skey.Addr, false)
+		q.slots = append(q.slots, skey.Key)
+	}
+
+	// an account takes two requests
+	n := size / 2
+	if n < 1 {
+		n = 1
+	}
+	var batches [][]*proofQuery
+	for len(queries) > n {
+		batches, queries = append(batches, queries[:n]), queries[n:]
+	}
+	if len(queries) != 0 {
+		batches = append(batches, queries)
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*verifiedResult, len(batches))
+	errs := make([]error, len(batches))
+	for i, batch := range batches {
+		wg.Add(1)
+		go func(i int, batch []*proofQuery) {
+			defer wg.Done()
+			results[i], errs[i] = self.fetchVerified(batch)
+		}(i, batch)
+	}
+	wg.Wait()
+
+	for i, batch := range batches {
+		if errs[i] != nil {
+			return fmt.Errorf("prefetch: %w", errs[i])
+		}
+		if err := self.storeVerified(batch, results[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
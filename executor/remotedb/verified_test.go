@@ -0,0 +1,125 @@
+package remotedb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/laizy/web3/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/fastrlp"
+)
+
+// proofNode serves eth_getProof and eth_getCode for a single contract
+type proofNode struct {
+	addr    web3.Address
+	code    []byte
+	slots   map[web3.Hash]*big.Int
+	balance *big.Int // balance reported by the node, differs from the proven one if lying
+
+	state   *trie.SecureTrie
+	storage *trie.SecureTrie
+}
+
+func newProofNode(addr web3.Address, code []byte, slots map[web3.Hash]*big.Int) *proofNode {
+	node := &proofNode{addr: addr, code: code, slots: slots, balance: big.NewInt(1000)}
+	node.storage = trie.NewSecureTrie()
+	ar := &fastrlp.Arena{}
+	for slot, val := range slots {
+		node.storage.Update(slot[:], ar.NewBigInt(val).MarshalTo(nil))
+	}
+	storageHash, codeHash := node.storage.Hash(), crypto.Keccak256Hash(code)
+	acct := ar.NewArray()
+	acct.Set(ar.NewUint(1))
+	acct.Set(ar.NewBigInt(node.balance))
+	acct.Set(ar.NewBytes(storageHash[:]))
+	acct.Set(ar.NewBytes(codeHash[:]))
+	node.state = trie.NewSecureTrie()
+	node.state.Update(addr[:], acct.MarshalTo(nil))
+	return node
+}
+
+func hexList(proof [][]byte) string {
+	var elems []string
+	for _, node := range proof {
+		elems = append(elems, fmt.Sprintf(`"0x%x"`, node))
+	}
+	return "[" + strings.Join(elems, ",") + "]"
+}
+
+func (self *proofNode) result(method string, params []json.RawMessage) string {
+	switch method {
+	case "eth_getCode":
+		return fmt.Sprintf(`"0x%x"`, self.code)
+	case "eth_getProof":
+		var slots []web3.Hash
+		json.Unmarshal(params[1], &slots)
+		var storageProofs []string
+		for _, slot := range slots {
+			val := self.slots[slot]
+			if val == nil {
+				val = big.NewInt(0)
+			}
+			storageProofs = append(storageProofs, fmt.Sprintf(`{"key":"%s","value":"0x%x","proof":%s}`,
+				slot, val, hexList(self.storage.Prove(slot[:]))))
+		}
+		return fmt.Sprintf(`{"address":"%s","accountProof":%s,"balance":"0x%x","codeHash":"%s","nonce":"0x1",`+
+			`"storageHash":"%s","storageProof":[%s]}`, self.addr, hexList(self.state.Prove(self.addr[:])), self.balance,
+			crypto.Keccak256Hash(self.code), self.storage.Hash(), strings.Join(storageProofs, ","))
+	}
+	return "null"
+}
+
+func (self *proofNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	var reqs []request
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var resps []string
+	for _, req := range reqs {
+		resps = append(resps, fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, self.result(req.Method, req.Params)))
+	}
+	w.Write([]byte("[" + strings.Join(resps, ",") + "]"))
+}
+
+func TestRemoteDBVerified(t *testing.T) {
+	addr := web3.Address{0x1}
+	slot, missing := web3.Hash{0x2}, web3.Hash{0x3}
+	node := newProofNode(addr, []byte{0x60, 0x00}, map[web3.Hash]*big.Int{slot: big.NewInt(0x1234)})
+	db, close := newTestRemoteDB(t, node.ServeHTTP)
+	defer close()
+
+	assert.Error(t, NewRemoteDB(nil).SetStateRoot(node.state.Hash()))
+	assert.NoError(t, db.SetStateRoot(node.state.Hash()))
+
+	val, err := db.GetStorage(addr, slot)
+	assert.NoError(t, err)
+	assert.Equal(t, web3.BytesToHash([]byte{0x12, 0x34}), val)
+	acct, err := db.GetAccount(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), acct.Nonce)
+	assert.Equal(t, uint64(1000), acct.Balance.Uint64())
+	assert.Equal(t, []byte{0x60, 0x00}, []byte(acct.Code))
+
+	assert.NoError(t, db.Prefetch(web3.AccessList{{Address: addr, Storage: []web3.Hash{missing}}}))
+	assert.Equal(t, web3.Hash{}, db.Storage[storageKey{Addr: addr, Key: missing}])
+
+	// a node lying about the balance is caught
+	node.balance = big.NewInt(2000)
+	db, close = newTestRemoteDB(t, node.ServeHTTP)
+	defer close()
+	assert.NoError(t, db.SetStateRoot(node.state.Hash()))
+	_, err = db.GetAccount(addr)
+	assert.ErrorIs(t, err, trie.ErrInvalidProof)
+}
@@ -0,0 +1,195 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm"
+	"github.com/laizy/web3/evm/params"
+	"github.com/laizy/web3/utils/common/hexutil"
+)
+
+// ReplayResult is the outcome of replaying a mined transaction
+type ReplayResult struct {
+	Result    *web3.ExecutionResult
+	Receipt   *web3.Receipt
+	StateDiff StateDiff
+	// Mismatches lists the receipt fields which differ from the on-chain receipt,
+	// empty if the replay reproduced the on-chain execution.
+	Mismatches []string
+}
+
+// ReplayTransaction re-executes a mined transaction on top of the state of its parent block.
+// All earlier transactions of the block are executed first, then the transaction itself with
+// the given tracer, which may be nil. The resulting receipt is compared with the on-chain one.
+// The forks follow the schedule of the chain, see SetChainConfig for devnets. Blocks with blob
+// transactions before the replayed one can not be replayed, ErrTxTypeNotSupported is returned.
+func (self *Executor) ReplayTransaction(txHash web3.Hash, tracer evm.Tracer) (*ReplayResult, error) {
+	eth := self.client.Eth()
+	tx, err := eth.GetTransactionByHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %s not found", txHash)
+	}
+	if tx.BlockHash == (web3.Hash{}) {
+		return nil, fmt.Errorf("transaction %s is not mined", txHash)
+	}
+	if tx.BlockNumber == 0 {
+		return nil, fmt.Errorf("transaction %s is in the genesis block", txHash)
+	}
+	block, err := eth.GetBlockByHash(tx.BlockHash, true)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", tx.BlockHash)
+	}
+	if tx.TxnIndex >= uint64(len(block.Transactions)) || block.Transactions[tx.TxnIndex].Hash() != txHash {
+		return nil, fmt.Errorf("transaction %s not found in block %s", txHash, block.Hash)
+	}
+	for _, prev := range block.Transactions[:tx.TxnIndex+1] {
+		if !supportedTxType(prev.Type) {
+			return nil, fmt.Errorf("replay transaction %s: %w: type %d", prev.Hash(), ErrTxTypeNotSupported, prev.Type)
+		}
+	}
+	chainID, err := self.resolveChainID()
+	if err != nil {
+		return nil, err
+	}
+	config, err := self.chainConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	replayer := NewExecutorAt(self.client, block.Number-1)
+	replayer.chainID = chainID
+	if cache := self.db.Cache(); cache != nil {
+		replayer.SetCache(cache, chainID)
+	}
+	replayer.preimages = self.preimages
+	replayer.config = self.config
+	usedGas := uint64(0)
+	for i := uint64(0); i < tx.TxnIndex; i++ {
+		if _, _, err := replayer.applyBlockTransaction(config, block, i, &usedGas, evm.Config{}); err != nil {
+			return nil, fmt.Errorf("replay transaction %s: %w", block.Transactions[i].Hash(), err)
+		}
+	}
+
+	cfg := evm.Config{}
+	if tracer != nil {
+		cfg.Debug = true
+		cfg.Tracer = tracer
+	}
+	before := replayer.overlayDB.GetWriteSet().DeepClone()
+	result, receipt, err := replayer.applyBlockTransaction(config, block, tx.TxnIndex, &usedGas, cfg)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := diffWriteSet(before, replayer.overlayDB.GetWriteSet(), replayer.db)
+	if err != nil {
+		return nil, err
+	}
+
+	onchain, err := eth.GetTransactionReceipt(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if onchain == nil {
+		return nil, fmt.Errorf("receipt of transaction %s not found", txHash)
+	}
+
+	return &ReplayResult{
+		Result:     result,
+		Receipt:    receipt,
+		StateDiff:  diff,
+		Mismatches: CompareReceipts(receipt, onchain),
+	}, nil
+}
+
+// BlockContext returns the context executing the transactions of the block
+func BlockContext(block *web3.Block) Eip155Context {
+	ctx := Eip155Context{
+		BlockHash:     block.Hash,
+		Height:        block.Number,
+		Timestamp:     block.Timestamp,
+		Coinbase:      block.Miner,
+		BaseFee:       block.BaseFee,
+		GasLimit:      block.GasLimit,
+		Difficulty:    block.Difficulty,
+		ExcessBlobGas: block.ExcessBlobGas,
+	}
+	// after the merge the difficulty is zero and the mix hash holds the prevrandao (EIP-4399)
+	if block.Difficulty == nil || block.Difficulty.Sign() == 0 {
+		random := block.MixHash
+		ctx.Random = &random
+	}
+	return ctx
+}
+
+// applyBlockTransaction executes the transaction at the given index of the block
+func (self *Executor) applyBlockTransaction(config *params.ChainConfig, block *web3.Block, index uint64,
+	usedGas *uint64, cfg evm.Config) (*web3.ExecutionResult, *web3.Receipt, error) {
+	tx := block.Transactions[index]
+	statedb := self.newStateDB(tx.Hash(), block.Hash)
+	cfg.EnablePreimageRecording = self.preimages != nil
+	ctx := BlockContext(block)
+	ctx.TxIndex = index
+	return ApplyTransaction(config, self.db, statedb, ctx, tx, usedGas, cfg, true)
+}
+
+// CompareReceipts returns the consensus fields which differ between the locally computed receipt
+// and the expected one, each formatted as "field: got <local>, want <expected>".
+func CompareReceipts(local, expected *web3.Receipt) []string {
+	var mismatches []string
+	mismatch := func(field string, got, want interface{}) {
+		mismatches = append(mismatches, fmt.Sprintf("%s: got %v, want %v", field, got, want))
+	}
+	if local.Status != expected.Status {
+		mismatch("status", local.Status, expected.Status)
+	}
+	if local.GasUsed != expected.GasUsed {
+		mismatch("gasUsed", local.GasUsed, expected.GasUsed)
+	}
+	if local.CumulativeGasUsed != expected.CumulativeGasUsed {
+		mismatch("cumulativeGasUsed", local.CumulativeGasUsed, expected.CumulativeGasUsed)
+	}
+	if local.ContractAddress != expected.ContractAddress {
+		mismatch("contractAddress", local.ContractAddress, expected.ContractAddress)
+	}
+	if expected.EffectiveGasPrice != nil && (local.EffectiveGasPrice == nil ||
+		local.EffectiveGasPrice.Cmp(expected.EffectiveGasPrice) != 0) {
+		mismatch("effectiveGasPrice", local.EffectiveGasPrice, expected.EffectiveGasPrice)
+	}
+	if len(local.Logs) != len(expected.Logs) {
+		mismatch("logs", len(local.Logs), len(expected.Logs))
+		return mismatches
+	}
+	for i, log := range local.Logs {
+		want := expected.Logs[i]
+		if log.Address != want.Address {
+			mismatch(fmt.Sprintf("logs[%d].address", i), log.Address, want.Address)
+		}
+		if !equalTopics(log.Topics, want.Topics) {
+			mismatch(fmt.Sprintf("logs[%d].topics", i), log.Topics, want.Topics)
+		}
+		if !bytes.Equal(log.Data, want.Data) {
+			mismatch(fmt.Sprintf("logs[%d].data", i), hexutil.Bytes(log.Data), hexutil.Bytes(want.Data))
+		}
+	}
+	return mismatches
+}
+
+func equalTopics(a, b []web3.Hash) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
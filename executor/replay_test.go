@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayTransaction(t *testing.T) {
+	from, to := web3.BytesToAddress([]byte{0xaa}), web3.BytesToAddress([]byte{0xbb})
+	emitter, coinbase := web3.BytesToAddress([]byte{0xcc}), web3.BytesToAddress([]byte{0xdd})
+	topic := web3.BytesToHash([]byte{0x11})
+	// LOG1 of the word 42 with the topic
+	code, _ := hex.DecodeString("602a600052" + "7f" + hex.EncodeToString(topic[:]) + "60206000a100")
+	node := newTestNode(map[web3.Address]*testAccount{
+		from:    {balance: big.NewInt(1000000)},
+		emitter: {code: code},
+	})
+	exec, closeFn := newTestExecutor(t, node, 20)
+	defer closeFn()
+
+	blockHash := web3.BytesToHash([]byte{0x99})
+	txJSON := func(hash web3.Hash, index, nonce uint64, typ string, to web3.Address) map[string]interface{} {
+		return map[string]interface{}{
+			"hash": hash, "from": from, "to": to, "input": "0x", "value": "0x5", "gas": "0x186a0",
+			"gasPrice": "0x2", "nonce": fmt.Sprintf("0x%x", nonce), "v": "0x1", "r": "0x1", "s": "0x1",
+			"blockHash": blockHash, "blockNumber": "0xb", "transactionIndex": fmt.Sprintf("0x%x", index),
+			"type": typ, "chainId": "0x539", "accessList": []interface{}{},
+			"maxFeePerGas": "0x3", "maxPriorityFeePerGas": "0x1",
+		}
+	}
+	transfer := txJSON(web3.BytesToHash([]byte{1}), 0, 0, "0x0", to)
+	call := txJSON(web3.BytesToHash([]byte{2}), 1, 1, "0x2", emitter)
+	block := map[string]interface{}{
+		"number": "0xb", "hash": blockHash, "parentHash": testBlockHash(10), "sha3Uncles": web3.Hash{},
+		"transactionsRoot": web3.Hash{}, "stateRoot": web3.Hash{}, "receiptsRoot": web3.Hash{},
+		"miner": coinbase, "gasLimit": "0x1c9c380", "gasUsed": "0xa813", "timestamp": "0x64",
+		"difficulty": "0x0", "extraData": "0x", "baseFeePerGas": "0x1", "mixHash": web3.BytesToHash([]byte{0x42}),
+		"transactions": []interface{}{transfer, call},
+	}
+	// the receipt mined by the chain
+	data := web3.BytesToHash([]byte{42})
+	receipt := map[string]interface{}{
+		"from": from, "to": emitter, "contractAddress": nil, "transactionHash": call["hash"],
+		"blockHash": blockHash, "blockNumber": "0xb", "transactionIndex": "0x1", "type": "0x2", "status": "0x1",
+		"gasUsed": "0x560b", "cumulativeGasUsed": "0xa813", "effectiveGasPrice": "0x2",
+		"logsBloom": "0x" + hex.EncodeToString(web3.LogsBloom([]*web3.Log{{Address: emitter, Topics: []web3.Hash{topic}}}).Bytes()),
+		"logs": []interface{}{map[string]interface{}{
+			"removed": false, "logIndex": "0x0", "blockNumber": "0xb", "transactionIndex": "0x1",
+			"transactionHash": call["hash"], "blockHash": blockHash, "address": emitter,
+			"topics": []web3.Hash{topic}, "data": "0x" + hex.EncodeToString(data[:]),
+		}},
+	}
+	node.replies = map[string]interface{}{
+		"eth_getTransactionByHash":  call,
+		"eth_getBlockByHash":        block,
+		"eth_getTransactionReceipt": receipt,
+	}
+
+	res, err := exec.ReplayTransaction(web3.BytesToHash([]byte{2}), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Mismatches)
+	assert.Equal(t, uint64(22027), res.Receipt.GasUsed)
+	assert.Equal(t, uint64(43027), res.Receipt.CumulativeGasUsed)
+	assert.Equal(t, 1, len(res.Receipt.Logs))
+	pre, post := res.StateDiff[from].Nonce()
+	assert.Equal(t, uint64(1), pre)
+	assert.Equal(t, uint64(2), post)
+	assert.NotEmpty(t, node.blocks)
+	for block := range node.blocks {
+		assert.Equal(t, "0xa", block, "reads at the parent block")
+	}
+
+	// the differences with the mined receipt are reported
+	receipt["gasUsed"] = "0x5600"
+	receipt["logs"] = []interface{}{}
+	res, err = exec.ReplayTransaction(web3.BytesToHash([]byte{2}), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gasUsed: got 22027, want 22016", "logs: got 1, want 0"}, res.Mismatches)
+
+	// blob transactions are not executed
+	transfer["type"] = "0x3"
+	_, err = exec.ReplayTransaction(web3.BytesToHash([]byte{2}), nil)
+	assert.True(t, errors.Is(err, ErrTxTypeNotSupported))
+	raw, _ := json.Marshal(transfer)
+	tx := new(web3.Transaction)
+	assert.NoError(t, tx.UnmarshalJSON(raw))
+	_, _, err = exec.ExecuteTransaction(tx, Eip155Context{Height: 11})
+	assert.True(t, errors.Is(err, ErrTxTypeNotSupported))
+}
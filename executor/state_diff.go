@@ -0,0 +1,232 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/evm/storage/overlaydb"
+	"github.com/laizy/web3/evm/storage/schema"
+	"github.com/laizy/web3/utils/codec"
+	"github.com/laizy/web3/utils/common/hexutil"
+)
+
+// StateDiff is the state changed by the execution of transactions, keyed by account address
+type StateDiff map[web3.Address]*AccountDiff
+
+// AccountDiff holds the account fields and storage slots of an account before and after execution.
+// Pre or Post is nil if the account did not exist at that point.
+type AccountDiff struct {
+	Pre     *storage.EthAccount
+	Post    *storage.EthAccount
+	Storage map[web3.Hash]*StorageDiff
+}
+
+// StorageDiff is the value of a storage slot before and after execution
+type StorageDiff struct {
+	Pre  web3.Hash
+	Post web3.Hash
+}
+
+// BalanceChange returns the balance difference of the account, post - pre
+func (self *AccountDiff) BalanceChange() *big.Int {
+	pre, post := big.NewInt(0), big.NewInt(0)
+	if self.Pre != nil && self.Pre.Balance != nil {
+		pre = self.Pre.Balance.ToBig()
+	}
+	if self.Post != nil && self.Post.Balance != nil {
+		post = self.Post.Balance.ToBig()
+	}
+	return post.Sub(post, pre)
+}
+
+// Nonce returns the nonce of the account before and after execution
+func (self *AccountDiff) Nonce() (pre, post uint64) {
+	if self.Pre != nil {
+		pre = self.Pre.Nonce
+	}
+	if self.Post != nil {
+		post = self.Post.Nonce
+	}
+	return pre, post
+}
+
+// Code returns the code of the account before and after execution
+func (self *AccountDiff) Code() (pre, post []byte) {
+	if self.Pre != nil {
+		pre = self.Pre.Code
+	}
+	if self.Post != nil {
+		post = self.Post.Code
+	}
+	return pre, post
+}
+
+// PrestateAccount is an account in the output format of the prestateTracer of geth
+type PrestateAccount struct {
+	Balance *hexutil.Big            `json:"balance,omitempty"`
+	Nonce   uint64                  `json:"nonce,omitempty"`
+	Code    hexutil.Bytes           `json:"code,omitempty"`
+	Storage map[web3.Hash]web3.Hash `json:"storage,omitempty"`
+}
+
+// PrestateDiff is the output of the prestateTracer of geth in diff mode. Pre holds the state of
+// the modified accounts before execution, and Post only holds the fields which changed. Created
+// accounts are missing in Pre and deleted accounts are missing in Post.
+type PrestateDiff struct {
+	Pre  map[web3.Address]*PrestateAccount `json:"pre"`
+	Post map[web3.Address]*PrestateAccount `json:"post"`
+}
+
+// Prestate converts the state diff to the diff mode format of the prestateTracer
+func (self StateDiff) Prestate() *PrestateDiff {
+	res := &PrestateDiff{
+		Pre:  make(map[web3.Address]*PrestateAccount),
+		Post: make(map[web3.Address]*PrestateAccount),
+	}
+	for addr, diff := range self {
+		if diff.Pre != nil {
+			pre := &PrestateAccount{
+				Balance: balanceOf(diff.Pre),
+				Nonce:   diff.Pre.Nonce,
+				Code:    diff.Pre.Code,
+			}
+			for slot, val := range diff.Storage {
+				if pre.Storage == nil {
+					pre.Storage = make(map[web3.Hash]web3.Hash)
+				}
+				pre.Storage[slot] = val.Pre
+			}
+			res.Pre[addr] = pre
+		}
+		if diff.Post == nil {
+			continue
+		}
+		post := &PrestateAccount{}
+		modified := false
+		if diff.BalanceChange().Sign() != 0 {
+			post.Balance = balanceOf(diff.Post)
+			modified = true
+		}
+		if pre, cur := diff.Nonce(); pre != cur {
+			post.Nonce = cur
+			modified = true
+		}
+		if pre, cur := diff.Code(); !bytes.Equal(pre, cur) {
+			post.Code = cur
+			modified = true
+		}
+		for slot, val := range diff.Storage {
+			modified = true
+			if val.Post == (web3.Hash{}) {
+				continue
+			}
+			if post.Storage == nil {
+				post.Storage = make(map[web3.Hash]web3.Hash)
+			}
+			post.Storage[slot] = val.Post
+		}
+		if modified {
+			res.Post[addr] = post
+		}
+	}
+	return res
+}
+
+// MarshalJSON encodes the state diff in the diff mode format of the prestateTracer
+func (self StateDiff) MarshalJSON() ([]byte, error) {
+	return json.Marshal(self.Prestate())
+}
+
+func balanceOf(acct *storage.EthAccount) *hexutil.Big {
+	if acct.Balance == nil {
+		return (*hexutil.Big)(big.NewInt(0))
+	}
+	return (*hexutil.Big)(acct.Balance.ToBig())
+}
+
+func (self StateDiff) account(addr web3.Address) *AccountDiff {
+	diff := self[addr]
+	if diff == nil {
+		diff = &AccountDiff{Storage: make(map[web3.Hash]*StorageDiff)}
+		self[addr] = diff
+	}
+	return diff
+}
+
+// diffWriteSet computes the state diff between the write set of the overlay db before execution
+// and after execution, values not in the old write set are loaded from the backend store.
+func diffWriteSet(before, after *overlaydb.MemDB, backend schema.PersistStore) (StateDiff, error) {
+	load := func(set *overlaydb.MemDB, key []byte) ([]byte, error) {
+		val, unknown := set.Get(key)
+		if !unknown {
+			return val, nil
+		}
+		val, err := backend.Get(key)
+		if err == schema.ErrNotFound {
+			return nil, nil
+		}
+		return val, err
+	}
+
+	diff := make(StateDiff)
+	accounts := make(map[web3.Address]bool)
+	var err error
+	after.ForEach(func(key, val []byte) {
+		if err != nil {
+			return
+		}
+		var pre []byte
+		if pre, err = load(before, key); err != nil || bytes.Equal(pre, val) {
+			return
+		}
+		switch schema.DataEntryPrefix(key[0]) {
+		case schema.ST_ETH_ACCOUNT:
+			addr := web3.BytesToAddress(key[1:])
+			acct := diff.account(addr)
+			accounts[addr] = true
+			if acct.Pre, err = decodeAccount(pre); err != nil {
+				return
+			}
+			acct.Post, err = decodeAccount(val)
+		case schema.ST_STORAGE:
+			acct := diff.account(web3.BytesToAddress(key[1:21]))
+			acct.Storage[web3.BytesToHash(key[21:])] = &StorageDiff{
+				Pre:  web3.BytesToHash(pre),
+				Post: web3.BytesToHash(val),
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	// accounts with only storage changes keep the same account fields
+	for addr, acct := range diff {
+		if accounts[addr] {
+			continue
+		}
+		key := append([]byte{byte(schema.ST_ETH_ACCOUNT)}, addr[:]...)
+		raw, err := load(after, key)
+		if err != nil {
+			return nil, err
+		}
+		if acct.Pre, err = decodeAccount(raw); err != nil {
+			return nil, err
+		}
+		acct.Post = acct.Pre
+	}
+	return diff, nil
+}
+
+func decodeAccount(raw []byte) (*storage.EthAccount, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	acct := &storage.EthAccount{}
+	if err := acct.Deserialization(codec.NewZeroCopySource(raw)); err != nil {
+		return nil, err
+	}
+	return acct, nil
+}
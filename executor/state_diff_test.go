@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/utils/common/uint256"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateDiffPrestate(t *testing.T) {
+	sender, created, deleted := web3.BytesToAddress([]byte{1}), web3.BytesToAddress([]byte{2}), web3.BytesToAddress([]byte{3})
+	slot1, slot2 := web3.BytesToHash([]byte{1}), web3.BytesToHash([]byte{2})
+	diff := StateDiff{
+		sender: {
+			Pre:  &storage.EthAccount{Nonce: 1, Balance: uint256.NewInt().SetUint64(100)},
+			Post: &storage.EthAccount{Nonce: 2, Balance: uint256.NewInt().SetUint64(100)},
+			Storage: map[web3.Hash]*StorageDiff{
+				slot1: {Pre: web3.Hash{}, Post: web3.BytesToHash([]byte{7})},
+				slot2: {Pre: web3.BytesToHash([]byte{8}), Post: web3.Hash{}},
+			},
+		},
+		created: {
+			Post:    &storage.EthAccount{Balance: uint256.NewInt().SetUint64(16), Code: []byte{0x60, 0x00}},
+			Storage: map[web3.Hash]*StorageDiff{},
+		},
+		deleted: {
+			Pre:     &storage.EthAccount{Balance: uint256.NewInt(), Code: []byte{0xff}},
+			Storage: map[web3.Hash]*StorageDiff{},
+		},
+	}
+
+	pre, post := diff[sender].Nonce()
+	assert.Equal(t, uint64(1), pre)
+	assert.Equal(t, uint64(2), post)
+
+	raw, err := json.Marshal(diff)
+	assert.Nil(t, err)
+	var res map[string]map[string]map[string]interface{}
+	assert.Nil(t, json.Unmarshal(raw, &res))
+
+	assert.Equal(t, map[string]interface{}{
+		"balance": "0x64",
+		"nonce":   float64(1),
+		"storage": map[string]interface{}{
+			slot1.String(): web3.Hash{}.String(),
+			slot2.String(): web3.BytesToHash([]byte{8}).String(),
+		},
+	}, res["pre"][sender.String()])
+	// unchanged balance is omitted, and the cleared slot is missing in post
+	assert.Equal(t, map[string]interface{}{
+		"nonce": float64(2),
+		"storage": map[string]interface{}{
+			slot1.String(): web3.BytesToHash([]byte{7}).String(),
+		},
+	}, res["post"][sender.String()])
+
+	assert.NotContains(t, res["pre"], created.String())
+	assert.Equal(t, map[string]interface{}{"balance": "0x10", "code": "0x6000"}, res["post"][created.String()])
+
+	assert.Equal(t, map[string]interface{}{"balance": "0x0", "code": "0xff"}, res["pre"][deleted.String()])
+	assert.NotContains(t, res["post"], deleted.String())
+}
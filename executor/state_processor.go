@@ -18,6 +18,9 @@
 package executor
 
 import (
+	"fmt"
+	"math/big"
+
 	"github.com/laizy/web3"
 	"github.com/laizy/web3/crypto"
 	"github.com/laizy/web3/evm"
@@ -26,7 +29,21 @@ import (
 	"github.com/laizy/web3/executor/remotedb"
 )
 
-func applyTransaction(msg Message, statedb *storage.StateDB, tx *web3.Transaction, usedGas *uint64, evm *evm.EVM, feeReceiver web3.Address) (*web3.ExecutionResult, *web3.Receipt, error) {
+// StateReadError is returned when some state could not be read during the execution of a
+// transaction, e.g. because the remote node is unreachable. The execution is discarded.
+type StateReadError struct {
+	Err error
+}
+
+func (self *StateReadError) Error() string {
+	return fmt.Sprintf("state read error: %v", self.Err)
+}
+
+func (self *StateReadError) Unwrap() error {
+	return self.Err
+}
+
+func applyTransaction(msg Message, statedb *storage.StateDB, tx *web3.Transaction, txIndex uint64, usedGas *uint64, evm *evm.EVM, feeReceiver web3.Address) (*web3.ExecutionResult, *web3.Receipt, error) {
 	// Create a new context to be used in the EVM environment
 	txContext := NewEVMTxContext(msg)
 
@@ -37,6 +54,10 @@ func applyTransaction(msg Message, statedb *storage.StateDB, tx *web3.Transactio
 	if err != nil {
 		return nil, nil, err
 	}
+	// the execution is meaningless if some state could not be read
+	if err = statedb.DbErr(); err != nil {
+		return nil, nil, &StateReadError{Err: err}
+	}
 	// flush changes to overlay db
 	err = statedb.Commit()
 	if err != nil {
@@ -51,14 +72,16 @@ func applyTransaction(msg Message, statedb *storage.StateDB, tx *web3.Transactio
 		status = 0
 	}
 	receipt := &web3.Receipt{
+		Type:              tx.Type,
 		Status:            status,
 		TransactionHash:   tx.Hash(),
-		TransactionIndex:  0,
+		TransactionIndex:  txIndex,
 		BlockHash:         web3.Hash{},
 		From:              msg.From(),
 		BlockNumber:       0,
 		GasUsed:           result.UsedGas,
-		CumulativeGasUsed: 0,
+		EffectiveGasPrice: new(big.Int).Set(msg.GasPrice()),
+		CumulativeGasUsed: *usedGas,
 		LogsBloom:         nil,
 		Logs:              nil,
 	}
@@ -71,18 +94,35 @@ func applyTransaction(msg Message, statedb *storage.StateDB, tx *web3.Transactio
 	receipt.BlockHash = statedb.BlockHash()
 	receipt.BlockNumber = evm.Context.BlockNumber.Uint64()
 	receipt.AddStorageLogs(statedb.GetLogs())
+	receipt.LogsBloom = web3.LogsBloom(receipt.Logs).Bytes()
 
 	return result, receipt, err
 }
 
+// supportedTxType reports whether transactions of the type can be executed. The fields of the
+// later types, e.g. the blob hashes, are not decoded.
+func supportedTxType(typ web3.TransactionType) bool {
+	return typ == web3.TransactionLegacy || typ == web3.TransactionAccessList || typ == web3.TransactionDynamicFee
+}
+
 // ApplyTransaction attempts to apply a transaction to the given state database
 // and uses the input parameters for its environment. It returns the receipt
 // for the transaction, gas used and an error if the transaction failed,
 // indicating the block was invalid.
-func ApplyTransaction(config *params.ChainConfig, bc *remotedb.RemoteDB, statedb *storage.StateDB, blockHeight, timestamp uint64, tx *web3.Transaction, usedGas *uint64, feeReceiver web3.Address, cfg evm.Config, checkNonce bool) (*web3.ExecutionResult, *web3.Receipt, error) {
+func ApplyTransaction(config *params.ChainConfig, bc *remotedb.RemoteDB, statedb *storage.StateDB, ctx Eip155Context, tx *web3.Transaction, usedGas *uint64, cfg evm.Config, checkNonce bool) (*web3.ExecutionResult, *web3.Receipt, error) {
+	if !supportedTxType(tx.Type) {
+		return nil, nil, fmt.Errorf("%w: type %d", ErrTxTypeNotSupported, tx.Type)
+	}
 	// Create a new context to be used in the EVM environment
-	msg := MessageFromTx(tx, checkNonce)
-	blockContext := NewEVMBlockContext(blockHeight, timestamp, bc.GetBlockHash)
+	msg := MessageFromTx(tx, ctx.BaseFee, checkNonce)
+	getHash := func(height uint64) web3.Hash {
+		hash, err := bc.GetBlockHash(height)
+		if err != nil {
+			statedb.SetDbErr(err)
+		}
+		return hash
+	}
+	blockContext := NewEVMBlockContext(ctx, getHash)
 	vmenv := evm.NewEVM(blockContext, evm.TxContext{}, statedb, config, cfg)
-	return applyTransaction(msg, statedb, tx, usedGas, vmenv, feeReceiver)
+	return applyTransaction(msg, statedb, tx, ctx.TxIndex, usedGas, vmenv, ctx.Coinbase)
 }
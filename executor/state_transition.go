@@ -62,6 +62,30 @@ var (
 	// ErrIntrinsicGas is returned if the transaction is specified to use less gas
 	// than required to start the invocation.
 	ErrIntrinsicGas = errors.New("intrinsic gas too low")
+
+	// ErrMaxInitCodeSizeExceeded is returned if creation transaction provides the init code bigger
+	// than init code size limit.
+	ErrMaxInitCodeSizeExceeded = errors.New("max initcode size exceeded")
+
+	// ErrTipAboveFeeCap is a sanity error to ensure no one is able to specify a
+	// transaction with a tip higher than the total fee cap.
+	ErrTipAboveFeeCap = errors.New("max priority fee per gas higher than max fee per gas")
+
+	// ErrTipVeryHigh is a sanity error to avoid extremely big numbers specified
+	// in the tip field.
+	ErrTipVeryHigh = errors.New("max priority fee per gas higher than 2^256-1")
+
+	// ErrFeeCapVeryHigh is a sanity error to avoid extremely big numbers specified
+	// in the fee cap field.
+	ErrFeeCapVeryHigh = errors.New("max fee per gas higher than 2^256-1")
+
+	// ErrFeeCapTooLow is returned if the transaction fee cap is less than the
+	// the base fee of the block.
+	ErrFeeCapTooLow = errors.New("max fee per gas less than block base fee")
+
+	// ErrTxTypeNotSupported is returned if a transaction is not supported by the
+	// executor, e.g. blob transactions.
+	ErrTxTypeNotSupported = errors.New("transaction type not supported")
 )
 
 /*
@@ -85,6 +109,8 @@ type StateTransition struct {
 	msg        Message
 	gas        uint64
 	gasPrice   *big.Int
+	gasFeeCap  *big.Int
+	gasTipCap  *big.Int
 	initialGas uint64
 	value      *big.Int
 	data       []byte
@@ -100,25 +126,33 @@ type Message interface {
 	To() *web3.Address
 
 	GasPrice() *big.Int
+	GasFeeCap() *big.Int
+	GasTipCap() *big.Int
 	Gas() uint64
 	Value() *big.Int
 
 	Nonce() uint64
 	CheckNonce() bool
 	Data() []byte
+	AccessList() web3.AccessList
+	BlobHashes() []web3.Hash
 }
 
 type message struct {
 	from web3.Address
 	to   *web3.Address
 
-	gasPrice *big.Int
-	gas      uint64
-	value    *big.Int
+	gasPrice  *big.Int
+	gasFeeCap *big.Int
+	gasTipCap *big.Int
+	gas       uint64
+	value     *big.Int
 
 	nonce      uint64
 	checkNonce bool
 	data       []byte
+	accessList web3.AccessList
+	blobHashes []web3.Hash
 }
 
 func (self *message) From() web3.Address {
@@ -132,6 +166,12 @@ func (self *message) To() *web3.Address {
 func (self *message) GasPrice() *big.Int {
 	return self.gasPrice
 }
+func (self *message) GasFeeCap() *big.Int {
+	return self.gasFeeCap
+}
+func (self *message) GasTipCap() *big.Int {
+	return self.gasTipCap
+}
 func (self *message) Gas() uint64 {
 	return self.gas
 }
@@ -148,26 +188,56 @@ func (self *message) CheckNonce() bool {
 func (self *message) Data() []byte {
 	return self.data
 }
+func (self *message) AccessList() web3.AccessList {
+	return self.accessList
+}
+func (self *message) BlobHashes() []web3.Hash {
+	return self.blobHashes
+}
 
-func MessageFromTx(tx *web3.Transaction, checkNonce bool) Message {
+// MessageFromTx returns the message of the transaction. For dynamic fee transactions the
+// gas price is the effective gas price paid under the given base fee, which may be nil
+// if the block has none. Blob transactions are not supported, so the message has no blob hashes.
+func MessageFromTx(tx *web3.Transaction, baseFee *big.Int, checkNonce bool) Message {
 	value := tx.Value
 	if value == nil {
 		value = big.NewInt(0)
 	}
+	gasPrice := big.NewInt(0).SetUint64(tx.GasPrice)
+	gasFeeCap, gasTipCap := gasPrice, gasPrice
+	if tx.Type == web3.TransactionDynamicFee {
+		gasFeeCap, gasTipCap = new(big.Int), new(big.Int)
+		if tx.MaxFeePerGas != nil {
+			gasFeeCap.Set(tx.MaxFeePerGas)
+		}
+		if tx.MaxPriorityFeePerGas != nil {
+			gasTipCap.Set(tx.MaxPriorityFeePerGas)
+		}
+		gasPrice = new(big.Int).Set(gasFeeCap)
+		if baseFee != nil {
+			gasPrice = gasPrice.Add(gasTipCap, baseFee)
+			if gasPrice.Cmp(gasFeeCap) > 0 {
+				gasPrice = gasPrice.Set(gasFeeCap)
+			}
+		}
+	}
 	return &message{
 		from:       tx.From,
 		to:         tx.To,
-		gasPrice:   big.NewInt(0).SetUint64(tx.GasPrice),
+		gasPrice:   gasPrice,
+		gasFeeCap:  gasFeeCap,
+		gasTipCap:  gasTipCap,
 		gas:        tx.Gas,
 		value:      value,
 		nonce:      tx.Nonce,
 		checkNonce: checkNonce,
 		data:       tx.Input,
+		accessList: tx.AccessList,
 	}
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, contractCreation, isHomestead bool, isEIP2028 bool) uint64 {
+func IntrinsicGas(data []byte, accessList web3.AccessList, contractCreation, isHomestead, isEIP2028, isEIP3860 bool) uint64 {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if contractCreation && isHomestead {
@@ -201,16 +271,39 @@ func IntrinsicGas(data []byte, contractCreation, isHomestead bool, isEIP2028 boo
 			panic(ErrGasUintOverflow)
 		}
 		gas += z * params.TxDataZeroGas
+
+		if contractCreation && isEIP3860 {
+			lenWords := toWordSize(uint64(len(data)))
+			if (math.MaxUint64-gas)/params.InitCodeWordGas < lenWords {
+				panic(ErrGasUintOverflow)
+			}
+			gas += lenWords * params.InitCodeWordGas
+		}
+	}
+	if accessList != nil {
+		gas += uint64(len(accessList)) * params.TxAccessListAddressGas
+		gas += uint64(accessList.StorageKeys()) * params.TxAccessListStorageKeyGas
 	}
 	return gas
 }
 
+// toWordSize returns the ceiled word size required for init code payment calculation.
+func toWordSize(size uint64) uint64 {
+	if size > math.MaxUint64-31 {
+		return math.MaxUint64/32 + 1
+	}
+
+	return (size + 31) / 32
+}
+
 // NewStateTransition initialises and returns a new state transition object.
 func NewStateTransition(evm *evm.EVM, msg Message, feeReceiver web3.Address) *StateTransition {
 	return &StateTransition{
 		evm:         evm,
 		msg:         msg,
 		gasPrice:    msg.GasPrice(),
+		gasFeeCap:   msg.GasFeeCap(),
+		gasTipCap:   msg.GasTipCap(),
 		value:       msg.Value(),
 		data:        msg.Data(),
 		state:       evm.StateDB,
@@ -237,17 +330,21 @@ func (st *StateTransition) to() web3.Address {
 	return *st.msg.To()
 }
 
-func (st *StateTransition) buyGas() {
-	gas := st.msg.Gas()
-	mgval := new(big.Int).Mul(new(big.Int).SetUint64(gas), st.gasPrice)
-	if have, want := st.state.GetBalance(st.msg.From()), mgval; have.Cmp(want) < 0 {
-		mgval = have
-		gas = have.Div(have, st.gasPrice).Uint64()
+func (st *StateTransition) buyGas() error {
+	mgval := new(big.Int).SetUint64(st.msg.Gas())
+	mgval = mgval.Mul(mgval, st.gasPrice)
+	// the balance must cover the gas at the fee cap, but only the effective price is charged
+	balanceCheck := new(big.Int).SetUint64(st.msg.Gas())
+	balanceCheck = balanceCheck.Mul(balanceCheck, st.gasFeeCap)
+	balanceCheck = balanceCheck.Add(balanceCheck, st.value)
+	if have, want := st.state.GetBalance(st.msg.From()), balanceCheck; have.Cmp(want) < 0 {
+		return fmt.Errorf("%w: address %v have %v want %v", ErrInsufficientFunds, st.msg.From().String(), have, want)
 	}
 
-	st.gas += gas
-	st.initialGas = gas
+	st.gas += st.msg.Gas()
+	st.initialGas = st.msg.Gas()
 	st.state.SubBalance(st.msg.From(), mgval)
+	return nil
 }
 
 func (st *StateTransition) preCheck() error {
@@ -262,8 +359,26 @@ func (st *StateTransition) preCheck() error {
 				st.msg.From().String(), msgNonce, stNonce)
 		}
 	}
-	st.buyGas()
-	return nil
+	// Make sure that transaction gasFeeCap is greater than the baseFee (post london)
+	if st.evm.ChainRules().IsLondon && st.evm.Context.BaseFee != nil {
+		if l := st.gasFeeCap.BitLen(); l > 256 {
+			return fmt.Errorf("%w: address %v, maxFeePerGas bit length: %d", ErrFeeCapVeryHigh,
+				st.msg.From().String(), l)
+		}
+		if l := st.gasTipCap.BitLen(); l > 256 {
+			return fmt.Errorf("%w: address %v, maxPriorityFeePerGas bit length: %d", ErrTipVeryHigh,
+				st.msg.From().String(), l)
+		}
+		if st.gasFeeCap.Cmp(st.gasTipCap) < 0 {
+			return fmt.Errorf("%w: address %v, maxPriorityFeePerGas: %s, maxFeePerGas: %s", ErrTipAboveFeeCap,
+				st.msg.From().String(), st.gasTipCap, st.gasFeeCap)
+		}
+		if st.gasFeeCap.Cmp(st.evm.Context.BaseFee) < 0 {
+			return fmt.Errorf("%w: address %v, maxFeePerGas: %s baseFee: %s", ErrFeeCapTooLow,
+				st.msg.From().String(), st.gasFeeCap, st.evm.Context.BaseFee)
+		}
+	}
+	return st.buyGas()
 }
 
 // TransitionDb will transition the state by applying the current message and
@@ -294,10 +409,15 @@ func (st *StateTransition) TransitionDb() (*web3.ExecutionResult, error) {
 	if err := st.preCheck(); err != nil {
 		return nil, err
 	}
+	if cfg := st.evm.Config(); cfg.Debug {
+		if tracer, ok := cfg.Tracer.(evm.TxTracer); ok {
+			tracer.CaptureTxStart(st.initialGas)
+			defer func() { tracer.CaptureTxEnd(st.gas) }()
+		}
+	}
 	msg := st.msg
 	sender := evm.AccountRef(msg.From())
-	homestead := st.evm.ChainConfig().IsHomestead(st.evm.Context.BlockNumber)
-	istanbul := st.evm.ChainConfig().IsIstanbul(st.evm.Context.BlockNumber)
+	rules := st.evm.ChainRules()
 	contractCreation := msg.To() == nil
 
 	var (
@@ -305,15 +425,20 @@ func (st *StateTransition) TransitionDb() (*web3.ExecutionResult, error) {
 		vmerr error // vm errors do not effect consensus and are therefore not assigned to err
 	)
 	// Check clauses 4-5, subtract intrinsic gas if everything is correct
-	gas := IntrinsicGas(st.data, contractCreation, homestead, istanbul)
+	gas := IntrinsicGas(st.data, msg.AccessList(), contractCreation, rules.IsHomestead, rules.IsIstanbul, rules.IsShanghai)
 	if st.gas < gas {
 		vmerr = fmt.Errorf("%w: have %d, want %d", ErrIntrinsicGas, st.gas, gas)
 		gas = st.gas
+		// Check whether the init code size has been exceeded.
+	} else if rules.IsShanghai && contractCreation && len(st.data) > params.MaxInitCodeSize {
+		vmerr = fmt.Errorf("%w: code size %v limit %v", ErrMaxInitCodeSizeExceeded, len(st.data), params.MaxInitCodeSize)
 		// Check clause 6
 	} else if msg.Value().Sign() > 0 && !st.evm.Context.CanTransfer(st.state, msg.From(), msg.Value()) {
 		vmerr = fmt.Errorf("%w: address %v", ErrInsufficientFundsForTransfer, msg.From().String())
 	}
 	st.gas -= gas
+	// Set up the initial access list and reset the transient storage.
+	st.state.PrepareAccessList(rules, msg.From(), st.evm.Context.Coinbase, msg.To(), st.evm.ActivePrecompiles(), msg.AccessList())
 	if vmerr == nil {
 		if contractCreation {
 			ret, _, st.gas, vmerr = st.evm.Create(sender, st.data, st.gas, st.value)
@@ -325,16 +450,27 @@ func (st *StateTransition) TransitionDb() (*web3.ExecutionResult, error) {
 	} else {
 		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
 	}
-	st.refundGas()
-	gAmount := new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice)
+	if rules.IsLondon {
+		// After EIP-3529: refunds are capped to gasUsed / 5
+		st.refundGas(params.RefundQuotientEIP3529)
+	} else {
+		// Before EIP-3529: refunds were capped to gasUsed / 2
+		st.refundGas(params.RefundQuotient)
+	}
+	// Only the priority fee goes to the fee receiver, the base fee is burned.
+	effectiveTip := st.gasPrice
+	if rules.IsLondon && st.evm.Context.BaseFee != nil {
+		effectiveTip = new(big.Int).Sub(st.gasPrice, st.evm.Context.BaseFee)
+	}
+	gAmount := new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), effectiveTip)
 	st.state.AddBalance(st.GasReceiver, gAmount)
 
 	return web3.NewExecutionResult(st.gasUsed(), vmerr, ret), nil
 }
 
-func (st *StateTransition) refundGas() {
-	// Apply refund counter, capped to half of the used gas.
-	refund := st.gasUsed() / 2
+func (st *StateTransition) refundGas(refundQuotient uint64) {
+	// Apply refund counter, capped to a refund quotient
+	refund := st.gasUsed() / refundQuotient
 	if refund > st.state.GetRefund() {
 		refund = st.state.GetRefund()
 	}
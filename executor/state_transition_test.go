@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/evm"
+	"github.com/laizy/web3/evm/params"
+	"github.com/laizy/web3/evm/storage"
+	"github.com/laizy/web3/evm/storage/overlaydb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMessageFees(t *testing.T) {
+	from, to := web3.BytesToAddress([]byte{0xaa}), web3.BytesToAddress([]byte{0xbb})
+	coinbase := web3.BytesToAddress([]byte{0xcc})
+	const gas = 21000
+	baseFee := big.NewInt(10)
+	value := big.NewInt(1000)
+	fee := func(price int64) *big.Int { return big.NewInt(gas * price) }
+
+	cases := []struct {
+		name    string
+		tx      *web3.Transaction
+		balance *big.Int
+		err     error
+		price   int64 // effective gas price paid by the sender
+		tip     int64 // price per gas received by the coinbase
+	}{
+		{
+			name:    "legacy",
+			tx:      &web3.Transaction{Type: web3.TransactionLegacy, GasPrice: 15},
+			balance: new(big.Int).Add(fee(15), value),
+			price:   15,
+			tip:     5,
+		},
+		{
+			name: "legacy price below base fee",
+			tx:   &web3.Transaction{Type: web3.TransactionLegacy, GasPrice: 5},
+			err:  ErrFeeCapTooLow,
+		},
+		{
+			name:    "legacy insufficient balance",
+			tx:      &web3.Transaction{Type: web3.TransactionLegacy, GasPrice: 15},
+			balance: new(big.Int).Add(fee(15), big.NewInt(999)),
+			err:     ErrInsufficientFunds,
+		},
+		{
+			name:    "access list",
+			tx:      &web3.Transaction{Type: web3.TransactionAccessList, GasPrice: 12},
+			balance: new(big.Int).Add(fee(12), value),
+			price:   12,
+			tip:     2,
+		},
+		{
+			name: "access list price below base fee",
+			tx:   &web3.Transaction{Type: web3.TransactionAccessList, GasPrice: 9},
+			err:  ErrFeeCapTooLow,
+		},
+		{
+			name:    "access list insufficient balance",
+			tx:      &web3.Transaction{Type: web3.TransactionAccessList, GasPrice: 12},
+			balance: fee(12),
+			err:     ErrInsufficientFunds,
+		},
+		{
+			name:    "dynamic fee",
+			tx:      &web3.Transaction{Type: web3.TransactionDynamicFee, MaxFeePerGas: big.NewInt(20), MaxPriorityFeePerGas: big.NewInt(2)},
+			balance: new(big.Int).Add(fee(20), value),
+			price:   12,
+			tip:     2,
+		},
+		{
+			name:    "dynamic fee capped tip",
+			tx:      &web3.Transaction{Type: web3.TransactionDynamicFee, MaxFeePerGas: big.NewInt(13), MaxPriorityFeePerGas: big.NewInt(13)},
+			balance: new(big.Int).Add(fee(13), value),
+			price:   13,
+			tip:     3,
+		},
+		{
+			name: "dynamic fee cap below base fee",
+			tx:   &web3.Transaction{Type: web3.TransactionDynamicFee, MaxFeePerGas: big.NewInt(5), MaxPriorityFeePerGas: big.NewInt(1)},
+			err:  ErrFeeCapTooLow,
+		},
+		{
+			name: "dynamic fee tip above fee cap",
+			tx:   &web3.Transaction{Type: web3.TransactionDynamicFee, MaxFeePerGas: big.NewInt(20), MaxPriorityFeePerGas: big.NewInt(30)},
+			err:  ErrTipAboveFeeCap,
+		},
+		{
+			// the balance covers the effective price but not the fee cap
+			name:    "dynamic fee insufficient balance for fee cap",
+			tx:      &web3.Transaction{Type: web3.TransactionDynamicFee, MaxFeePerGas: big.NewInt(20), MaxPriorityFeePerGas: big.NewInt(2)},
+			balance: new(big.Int).Add(fee(12), value),
+			err:     ErrInsufficientFunds,
+		},
+	}
+
+	for _, c := range cases {
+		statedb := storage.NewStateDB(storage.NewCacheDB(overlaydb.NewOverlayDB(overlaydb.NewMemStore())), web3.Hash{}, web3.Hash{})
+		if c.balance != nil {
+			statedb.AddBalance(from, c.balance)
+		}
+		c.tx.From, c.tx.To, c.tx.Gas, c.tx.Value = from, &to, gas, value
+		ctx := NewEVMBlockContext(Eip155Context{Coinbase: coinbase, BaseFee: baseFee}, nil)
+		msg := MessageFromTx(c.tx, baseFee, false)
+		vmenv := evm.NewEVM(ctx, NewEVMTxContext(msg), statedb, params.TestChainConfig, evm.Config{})
+
+		result, err := ApplyMessage(vmenv, msg, coinbase)
+		if c.err != nil {
+			assert.ErrorIs(t, err, c.err, c.name)
+			continue
+		}
+		assert.NoError(t, err, c.name)
+		assert.False(t, result.Failed(), c.name)
+		assert.Equal(t, uint64(gas), result.UsedGas, c.name)
+		spent := new(big.Int).Add(fee(c.price), value)
+		assert.Equal(t, new(big.Int).Sub(c.balance, spent), statedb.GetBalance(from), c.name)
+		assert.Equal(t, value, statedb.GetBalance(to), c.name)
+		assert.Equal(t, fee(c.tip), statedb.GetBalance(coinbase), c.name)
+	}
+}
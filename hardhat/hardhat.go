@@ -12,6 +12,7 @@ import (
 
 	"github.com/laizy/web3/abi"
 	"github.com/laizy/web3/registry"
+	"github.com/laizy/web3/sourcemap"
 	"github.com/laizy/web3/utils"
 	"github.com/laizy/web3/utils/common/hexutil"
 )
@@ -27,8 +28,9 @@ func GetArtifacts(artifactDirName ...string) (map[string]*Artifact, error) {
 		return nil, err
 	}
 	results := make(map[string]*Artifact)
+	buildInfos := make(map[string]*buildInfo)
 	for name, path := range pathes {
-		arti, err := getArtifactWithPath(path)
+		arti, err := getArtifactWithPath(path, buildInfos)
 		if err != nil {
 			return nil, err
 		}
@@ -44,12 +46,13 @@ func GetArtifact(name string, artifactDirName ...string) (*Artifact, error) {
 		return nil, err
 	}
 
-	return getArtifactWithPath(path)
+	return getArtifactWithPath(path, make(map[string]*buildInfo))
 }
 
 func DecodeArtifact(buf []byte) (*Artifact, error) {
 	type InnerCode struct {
-		Object hexutil.Bytes
+		Object    hexutil.Bytes
+		SourceMap string `json:"sourceMap"`
 	}
 	type artifact struct {
 		ContractName      string      `json:"contractName"`
@@ -58,6 +61,8 @@ func DecodeArtifact(buf []byte) (*Artifact, error) {
 		Bytecode          interface{} `json:"bytecode"`
 		DeployedBytecode  interface{} `json:"deployedBytecode"`
 		DeployedBytecode2 InnerCode   `json:"deployed_bytecode"` //this is more forge compile case
+		SourceMap         string      `json:"sourceMap"`         // truffle case
+		DeployedSourceMap string      `json:"deployedSourceMap"` // truffle case
 	}
 	var value artifact
 	err := json.Unmarshal(buf, &value)
@@ -78,6 +83,7 @@ func DecodeArtifact(buf []byte) (*Artifact, error) {
 			panic(err)
 		}
 		_bytecode = innerCode.Object.String()
+		value.SourceMap = innerCode.SourceMap
 	}
 	var _deployedByte string
 	if value.DeployedBytecode != nil { //because depolyedBytecode have 2 key&struct, so this interface maybe empty
@@ -89,26 +95,114 @@ func DecodeArtifact(buf []byte) (*Artifact, error) {
 				panic(err)
 			}
 			_deployedByte = innerCode.Object.String()
+			value.DeployedSourceMap = innerCode.SourceMap
 		}
 	} else {
 		_deployedByte = value.DeployedBytecode2.Object.String()
+		value.DeployedSourceMap = value.DeployedBytecode2.SourceMap
 	}
 
 	return &Artifact{
-		ContractName:     value.ContractName,
-		SourceName:       value.SourceName,
-		Abi:              _abi,
-		Bytecode:         hexutil.MustDecode(_bytecode),
-		DeployedBytecode: hexutil.MustDecode(_deployedByte),
+		ContractName:      value.ContractName,
+		SourceName:        value.SourceName,
+		Abi:               _abi,
+		Bytecode:          hexutil.MustDecode(_bytecode),
+		DeployedBytecode:  hexutil.MustDecode(_deployedByte),
+		SourceMap:         value.SourceMap,
+		DeployedSourceMap: value.DeployedSourceMap,
 	}, nil
 }
 
-func getArtifactWithPath(path string) (*Artifact, error) {
+func getArtifactWithPath(path string, buildInfos map[string]*buildInfo) (*Artifact, error) {
 	buf, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	return DecodeArtifact(buf)
+	arti, err := DecodeArtifact(buf)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadSources(arti, path, buildInfos); err != nil {
+		return nil, err
+	}
+	return arti, nil
+}
+
+// buildInfo is the compiler input and output saved by hardhat in artifacts/build-info
+type buildInfo struct {
+	Input struct {
+		Sources map[string]struct {
+			Content string `json:"content"`
+		} `json:"sources"`
+	} `json:"input"`
+	Output struct {
+		Sources map[string]struct {
+			ID int `json:"id"`
+		} `json:"sources"`
+		Contracts map[string]map[string]struct {
+			Evm struct {
+				Bytecode struct {
+					SourceMap string `json:"sourceMap"`
+				} `json:"bytecode"`
+				DeployedBytecode struct {
+					SourceMap string `json:"sourceMap"`
+				} `json:"deployedBytecode"`
+			} `json:"evm"`
+		} `json:"contracts"`
+	} `json:"output"`
+}
+
+// loadSources fills the source maps and the source files of the artifact from the build info
+// referenced by the debug file next to the artifact, nothing is done if the debug file or the
+// build info is missing.
+func loadSources(arti *Artifact, path string, buildInfos map[string]*buildInfo) error {
+	dbgPath := strings.TrimSuffix(path, ".json") + ".dbg.json"
+	buf, err := ioutil.ReadFile(dbgPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var dbg struct {
+		BuildInfo string `json:"buildInfo"`
+	}
+	if err := json.Unmarshal(buf, &dbg); err != nil {
+		return err
+	}
+	if dbg.BuildInfo == "" {
+		return nil
+	}
+	infoPath := filepath.Join(filepath.Dir(dbgPath), dbg.BuildInfo)
+	info := buildInfos[infoPath]
+	if info == nil {
+		buf, err := ioutil.ReadFile(infoPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// the build info is pruned, e.g. by hardhat clean, the artifact has no sources
+				return nil
+			}
+			return err
+		}
+		info = &buildInfo{}
+		if err := json.Unmarshal(buf, info); err != nil {
+			return err
+		}
+		buildInfos[infoPath] = info
+	}
+
+	if contract, ok := info.Output.Contracts[arti.SourceName][arti.ContractName]; ok {
+		arti.SourceMap = contract.Evm.Bytecode.SourceMap
+		arti.DeployedSourceMap = contract.Evm.DeployedBytecode.SourceMap
+	}
+	arti.Sources = nil
+	for name, source := range info.Output.Sources {
+		for len(arti.Sources) <= source.ID {
+			arti.Sources = append(arti.Sources, sourcemap.Source{})
+		}
+		arti.Sources[source.ID] = sourcemap.Source{Name: name, Content: info.Input.Sources[name].Content}
+	}
+	return nil
 }
 
 func getArtifactPathes(artifactDirName string) (map[string]string, error) {
@@ -182,11 +276,25 @@ func GetProjectRoot() (string, error) {
 }
 
 type Artifact struct {
-	ContractName     string        `json:"contractName"` // "DSProxy",
-	SourceName       string        `json:"sourceName"`   // "contracts/proxy.sol"
-	Abi              string        `json:"abi"`
-	Bytecode         hexutil.Bytes `json:"bytecode"`         // 0x6080
-	DeployedBytecode hexutil.Bytes `json:"deployedBytecode"` // 0x6080
+	ContractName      string             `json:"contractName"` // "DSProxy",
+	SourceName        string             `json:"sourceName"`   // "contracts/proxy.sol"
+	Abi               string             `json:"abi"`
+	Bytecode          hexutil.Bytes      `json:"bytecode"`          // 0x6080
+	DeployedBytecode  hexutil.Bytes      `json:"deployedBytecode"`  // 0x6080
+	SourceMap         string             `json:"sourceMap"`         // "60:992:6:-:0;;;"
+	DeployedSourceMap string             `json:"deployedSourceMap"` // "60:992:6:-:0;;;"
+	Sources           []sourcemap.Source `json:"sources,omitempty"` // indexed by the file index of the source maps
+}
+
+// SourceMaps returns the creation code and the runtime code of the contract with their source maps
+func (self *Artifact) SourceMaps() (creation, runtime *sourcemap.Contract, err error) {
+	if creation, err = sourcemap.NewContract(self.ContractName, self.Bytecode, self.SourceMap, self.Sources); err != nil {
+		return nil, nil, err
+	}
+	if runtime, err = sourcemap.NewContract(self.ContractName, self.DeployedBytecode, self.DeployedSourceMap, self.Sources); err != nil {
+		return nil, nil, err
+	}
+	return creation, runtime, nil
 }
 
 func pathExists(path string) bool {
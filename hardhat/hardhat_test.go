@@ -1,6 +1,11 @@
 package hardhat
 
-import "testing"
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 var forgeJson = `
 {
@@ -38,3 +43,44 @@ func TestDecodeOld(t *testing.T) {
 		t.Fatalf("decode new err: %v", err)
 	}
 }
+
+func TestDecodeSourceMap(t *testing.T) {
+	arti, err := DecodeArtifact([]byte(forgeJson))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arti.DeployedSourceMap != "60:992:6:-:0;;;;;;;;" {
+		t.Fatalf("unexpected deployed source map: %s", arti.DeployedSourceMap)
+	}
+	_, runtime, err := arti.SourceMaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc, ok := runtime.Location(0); !ok || loc.Offset != 60 || loc.File != 6 {
+		t.Fatalf("unexpected location: %v", loc)
+	}
+}
+
+func TestMissingBuildInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hardhat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "xx.json")
+	if err := ioutil.WriteFile(path, []byte(hardhatJson), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dbg := `{"_format": "hh-sol-dbg-1", "buildInfo": "../build-info/missing.json"}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "xx.dbg.json"), []byte(dbg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	arti, err := getArtifactWithPath(path, map[string]*buildInfo{})
+	if err != nil {
+		t.Fatalf("missing build info err: %v", err)
+	}
+	if arti.Sources != nil {
+		t.Fatalf("unexpected sources: %v", arti.Sources)
+	}
+}
@@ -0,0 +1,173 @@
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/laizy/web3"
+)
+
+// Batch collects calls which Do sends in as few round trips as possible, the value of each call
+// has the same type as the one returned by the Eth method of the same name.
+//
+//	res, err := client.Batch().GetBalance(addr, web3.Latest).GetNonce(addr, web3.Latest).Do()
+type Batch struct {
+	c     *Client
+	calls []*batchCall
+}
+
+type batchCall struct {
+	method string
+	params []interface{}
+	out    interface{}
+	// decode converts out into the value of the result
+	decode func() (interface{}, error)
+}
+
+// BatchResult is the result of a call of the batch
+type BatchResult struct {
+	Method string
+	Value  interface{}
+	Error  error
+}
+
+// Batch creates an empty batch of calls
+func (c *Client) Batch() *Batch {
+	return &Batch{c: c}
+}
+
+// Len returns the number of calls in the batch
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+// Add adds a call whose result is decoded into out, out is the value of the result
+func (b *Batch) Add(method string, out interface{}, params ...interface{}) *Batch {
+	return b.add(method, out, func() (interface{}, error) { return out, nil }, params)
+}
+
+func (b *Batch) add(method string, out interface{}, decode func() (interface{}, error), params []interface{}) *Batch {
+	b.calls = append(b.calls, &batchCall{method: method, params: params, out: out, decode: decode})
+	return b
+}
+
+func (b *Batch) addString(method string, decode func(out string) (interface{}, error), params ...interface{}) *Batch {
+	out := new(string)
+	return b.add(method, out, func() (interface{}, error) { return decode(*out) }, params)
+}
+
+func decodeUint64(out string) (interface{}, error) {
+	return parseUint64orHex(out)
+}
+
+func decodeBigInt(out string) (interface{}, error) {
+	num, ok := new(big.Int).SetString(strings.TrimPrefix(out, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert to big.int")
+	}
+	return num, nil
+}
+
+func decodeString(out string) (interface{}, error) {
+	return out, nil
+}
+
+// BlockNumber adds eth_blockNumber, the value is an uint64
+func (b *Batch) BlockNumber() *Batch {
+	return b.addString("eth_blockNumber", decodeUint64)
+}
+
+// ChainID adds eth_chainId, the value is a *big.Int
+func (b *Batch) ChainID() *Batch {
+	return b.addString("eth_chainId", decodeBigInt)
+}
+
+// GasPrice adds eth_gasPrice, the value is an uint64
+func (b *Batch) GasPrice() *Batch {
+	return b.addString("eth_gasPrice", decodeUint64)
+}
+
+// GetBalance adds eth_getBalance, the value is a *big.Int
+func (b *Batch) GetBalance(addr web3.Address, blockNumber web3.BlockNumber) *Batch {
+	return b.addString("eth_getBalance", decodeBigInt, addr, blockNumber.String())
+}
+
+// GetNonce adds eth_getTransactionCount, the value is an uint64
+func (b *Batch) GetNonce(addr web3.Address, blockNumber web3.BlockNumber) *Batch {
+	return b.addString("eth_getTransactionCount", decodeUint64, addr, blockNumber.String())
+}
+
+// GetCode adds eth_getCode at the latest block, the value is the hex string of the code
+func (b *Batch) GetCode(addr web3.Address) *Batch {
+	return b.GetCodeAt(addr, web3.Latest)
+}
+
+// GetCodeAt adds eth_getCode, the value is the hex string of the code
+func (b *Batch) GetCodeAt(addr web3.Address, blockNumber web3.BlockNumber) *Batch {
+	return b.addString("eth_getCode", decodeString, addr, blockNumber.String())
+}
+
+// GetStorage adds eth_getStorageAt, the value is a web3.Hash
+func (b *Batch) GetStorage(account web3.Address, key web3.Hash, blockNumber web3.BlockNumber) *Batch {
+	slot := fmt.Sprintf("0x%x", new(big.Int).SetBytes(key.Bytes()))
+	return b.addString("eth_getStorageAt", func(out string) (interface{}, error) {
+		return web3.HexToHash(out), nil
+	}, account, slot, blockNumber.String())
+}
+
+// Call adds eth_call, the value is the hex string of the returned data
+func (b *Batch) Call(msg *web3.CallMsg, block web3.BlockNumber) *Batch {
+	return b.addString("eth_call", decodeString, msg, block.String())
+}
+
+// GetBlockByNumber adds eth_getBlockByNumber, the value is a *web3.Block
+func (b *Batch) GetBlockByNumber(i web3.BlockNumber, full bool) *Batch {
+	out := new(*web3.Block)
+	return b.add("eth_getBlockByNumber", out, func() (interface{}, error) { return *out, nil }, []interface{}{i.String(), full})
+}
+
+// GetBlockByHash adds eth_getBlockByHash, the value is a *web3.Block
+func (b *Batch) GetBlockByHash(hash web3.Hash, full bool) *Batch {
+	out := new(*web3.Block)
+	return b.add("eth_getBlockByHash", out, func() (interface{}, error) { return *out, nil }, []interface{}{hash, full})
+}
+
+// GetTransactionByHash adds eth_getTransactionByHash, the value is a *web3.Transaction
+func (b *Batch) GetTransactionByHash(hash web3.Hash) *Batch {
+	out := new(*web3.Transaction)
+	return b.add("eth_getTransactionByHash", out, func() (interface{}, error) { return *out, nil }, []interface{}{hash})
+}
+
+// GetTransactionReceipt adds eth_getTransactionReceipt, the value is a *web3.Receipt
+func (b *Batch) GetTransactionReceipt(hash web3.Hash) *Batch {
+	out := new(*web3.Receipt)
+	return b.add("eth_getTransactionReceipt", out, func() (interface{}, error) { return *out, nil }, []interface{}{hash})
+}
+
+// Do sends the calls and returns their results in the order they are added. The error of each
+// call is set on its result while the returned error reports a failure of the whole batch.
+func (b *Batch) Do() ([]*BatchResult, error) {
+	return b.DoContext(context.Background())
+}
+
+// DoContext is like Do with a context
+func (b *Batch) DoContext(ctx context.Context) ([]*BatchResult, error) {
+	elems := make([]BatchElem, len(b.calls))
+	for i, call := range b.calls {
+		elems[i] = BatchElem{Method: call.method, Params: call.params, Result: call.out}
+	}
+	if err := b.c.BatchCallContext(ctx, elems); err != nil {
+		return nil, err
+	}
+	results := make([]*BatchResult, len(b.calls))
+	for i, call := range b.calls {
+		res := &BatchResult{Method: call.method, Error: elems[i].Error}
+		if res.Error == nil {
+			res.Value, res.Error = call.decode()
+		}
+		results[i] = res
+	}
+	return results, nil
+}
@@ -0,0 +1,67 @@
+package jsonrpc
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch(t *testing.T) {
+	s := newMockServer(t, map[string]string{
+		"eth_getBalance":          `"0x64"`,
+		"eth_getTransactionCount": `"0x5"`,
+		"eth_getCode":             `"0x6000"`,
+		"eth_getBlockByNumber":    `null`,
+	})
+	defer s.Close()
+
+	addr := web3.BytesToAddress([]byte{1})
+	for _, url := range s.addrs() {
+		c, err := NewClient(url)
+		assert.NoError(t, err)
+
+		res, err := c.Batch().
+			GetBalance(addr, web3.Latest).
+			GetNonce(addr, web3.Latest).
+			GetCode(addr).
+			GetBlockByNumber(1, false).
+			Add("eth_methodNotFound", new(string)).
+			Do()
+		assert.NoError(t, err, url)
+		assert.Equal(t, 5, len(res))
+		assert.Equal(t, big.NewInt(100), res[0].Value)
+		assert.Equal(t, uint64(5), res[1].Value)
+		assert.Equal(t, "0x6000", res[2].Value)
+		assert.Nil(t, res[3].Error)
+		assert.Nil(t, res[3].Value.(*web3.Block))
+		assert.Equal(t, "eth_methodNotFound", res[4].Method)
+		assert.Error(t, res[4].Error)
+
+		c.Close()
+	}
+}
+
+func TestBatchLimit(t *testing.T) {
+	s := newMockServer(t, map[string]string{"eth_getBalance": `"0x1"`})
+	defer s.Close()
+
+	c, err := NewClientWithConfig(s.http.URL, &Config{BatchLimit: 3})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	batch := c.Batch()
+	for i := 0; i < 10; i++ {
+		batch.GetBalance(web3.BytesToAddress([]byte{byte(i)}), web3.Latest)
+	}
+	res, err := batch.Do()
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(res))
+	for _, r := range res {
+		assert.NoError(t, r.Error)
+		assert.Equal(t, big.NewInt(1), r.Value)
+	}
+	assert.Equal(t, int64(4), atomic.LoadInt64(&s.requests))
+}
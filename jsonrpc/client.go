@@ -1,13 +1,27 @@
 package jsonrpc
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/laizy/web3"
 	"github.com/laizy/web3/jsonrpc/transport"
 )
 
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultBatchLimit = 100
+)
+
 // Client is the jsonrpc client
 type Client struct {
-	transport transport.Transport
-	endpoints endpoints
+	transport    transport.Transport
+	endpoints    endpoints
+	timeout      time.Duration
+	batchLimit   int
+	interceptors []transport.Interceptor
+	handler      transport.Handler
 
 	GasLimitFactor func(gasLimit uint64) uint64
 }
@@ -20,23 +34,100 @@ type endpoints struct {
 	w *Web3
 	e *Eth
 	n *Net
+	d *Debug
+}
+
+// Config is the configuration of the client
+type Config struct {
+	// Timeout bounds the dial and the calls whose context has no deadline, zero disables it
+	Timeout time.Duration
+	// BatchLimit is the maximum number of calls sent in a single batch request, larger batches
+	// are split into several requests, zero disables the limit
+	BatchLimit int
+}
+
+// DefaultConfig returns the default client config
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout:    defaultTimeout,
+		BatchLimit: defaultBatchLimit,
+	}
 }
 
 // NewClient creates a new client
 func NewClient(addr string) (*Client, error) {
-	c := &Client{GasLimitFactor: DefaultGasFactor}
-	c.endpoints.w = &Web3{c}
-	c.endpoints.e = &Eth{c}
-	c.endpoints.n = &Net{c}
+	return NewClientWithConfig(addr, DefaultConfig())
+}
 
-	t, err := transport.NewTransport(addr)
+// NewClientWithConfig creates a new client with the config
+func NewClientWithConfig(addr string, config *Config) (*Client, error) {
+	c := newClient(config)
+	ctx, cancel := c.withTimeout(context.Background())
+	defer cancel()
+	t, err := transport.NewTransportContext(ctx, addr)
 	if err != nil {
 		return nil, err
 	}
-	c.transport = t
+	c.setTransport(t)
 	return c, nil
 }
 
+// NewClientWithTransport creates a new client over the transport, such as a transport.Multi
+// spreading the calls over several endpoints
+func NewClientWithTransport(t transport.Transport, config *Config) *Client {
+	c := newClient(config)
+	c.setTransport(t)
+	return c
+}
+
+func (c *Client) setTransport(t transport.Transport) {
+	c.transport = t
+	c.handler = transport.Chain(transport.Send(t), append(c.interceptors, traceRpc)...)
+}
+
+// Use adds interceptors to the calls of the client, such as transport.RateLimit and
+// transport.Retry. The interceptors added first are the outermost ones. It must be called before
+// the client is used concurrently.
+func (c *Client) Use(interceptors ...transport.Interceptor) {
+	c.interceptors = append(c.interceptors, interceptors...)
+	c.setTransport(c.transport)
+}
+
+// traceRpc prints the calls sent to the transport when web3.TraceRpc is set
+func traceRpc(next transport.Handler) transport.Handler {
+	logging := transport.Logging(func(format string, args ...interface{}) {
+		fmt.Printf(format+"\n", args...)
+	})(next)
+	return func(ctx context.Context, req *transport.Request) error {
+		if web3.TraceRpc {
+			return logging(ctx, req)
+		}
+		return next(ctx, req)
+	}
+}
+
+func newClient(config *Config) *Client {
+	c := &Client{GasLimitFactor: DefaultGasFactor, timeout: config.Timeout, batchLimit: config.BatchLimit}
+	c.endpoints.w = &Web3{c}
+	c.endpoints.e = &Eth{c}
+	c.endpoints.n = &Net{c}
+	c.endpoints.d = &Debug{c}
+	return c
+}
+
+// SetTimeout sets the timeout of the calls whose context has no deadline, zero disables it
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// withTimeout applies the default timeout if the context has no deadline
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
 // Close closes the tranport
 func (c *Client) Close() error {
 	return c.transport.Close()
@@ -44,5 +135,42 @@ func (c *Client) Close() error {
 
 // Call makes a jsonrpc call
 func (c *Client) Call(method string, out interface{}, params ...interface{}) error {
-	return c.transport.Call(method, out, params...)
+	return c.CallContext(context.Background(), method, out, params...)
+}
+
+// CallContext makes a jsonrpc call which is aborted once the context is done
+func (c *Client) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	return c.handler(ctx, &transport.Request{Method: method, Params: params, Out: out})
+}
+
+// BatchElem is a single request in a batch call
+type BatchElem = transport.BatchElem
+
+// BatchCall makes a batch of jsonrpc calls in a single round trip, or one round trip per
+// BatchLimit calls. Transports without batch support fall back to sequential calls. The error
+// of each call is set on its element.
+func (c *Client) BatchCall(elems []BatchElem) error {
+	return c.BatchCallContext(context.Background(), elems)
+}
+
+// BatchCallContext is like BatchCall but the calls are aborted once the context is done
+func (c *Client) BatchCallContext(ctx context.Context, elems []BatchElem) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	size := c.batchLimit
+	if size <= 0 {
+		size = len(elems)
+	}
+	for start := 0; start < len(elems); start += size {
+		end := start + size
+		if end > len(elems) {
+			end = len(elems)
+		}
+		if err := c.handler(ctx, &transport.Request{Batch: elems[start:end]}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
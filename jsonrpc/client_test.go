@@ -0,0 +1,135 @@
+package jsonrpc
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/testutil"
+	"github.com/laizy/web3/utils/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchCall(t *testing.T) {
+	testutil.MultiAddr(t, nil, func(s *testutil.TestServer, addr string) {
+		c, _ := NewClient(addr)
+		defer c.Close()
+
+		var num hexutil.Uint64
+		var balance hexutil.Big
+		var missing string
+		elems := []BatchElem{
+			{Method: "eth_blockNumber", Result: &num},
+			{Method: "eth_getBalance", Params: []interface{}{s.Account(0), web3.Latest.String()}, Result: &balance},
+			{Method: "eth_methodNotFound", Result: &missing},
+		}
+		assert.NoError(t, c.BatchCall(elems))
+		assert.NoError(t, elems[0].Error)
+		assert.NoError(t, elems[1].Error)
+		assert.Error(t, elems[2].Error)
+
+		expected, err := c.Eth().GetBalance(s.Account(0), web3.Latest)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, balance.ToInt())
+	})
+}
+
+// hangingServers returns the http, websocket and ipc addresses of servers which never reply
+func hangingServers(t *testing.T) ([]string, func()) {
+	done := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+
+	dir, err := ioutil.TempDir("", "web3-ipc")
+	assert.NoError(t, err)
+	ipcPath := filepath.Join(dir, "geth.ipc")
+	listener, err := net.Listen("unix", ipcPath)
+	assert.NoError(t, err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go ioutil.ReadAll(conn)
+		}
+	}()
+
+	addrs := []string{server.URL, "ws" + strings.TrimPrefix(server.URL, "http"), ipcPath}
+	return addrs, func() {
+		close(done)
+		listener.Close()
+		server.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCallContext(t *testing.T) {
+	addrs, closeFn := hangingServers(t)
+	defer closeFn()
+
+	for _, addr := range addrs {
+		c, err := NewClientWithConfig(addr, &Config{Timeout: 100 * time.Millisecond})
+		assert.NoError(t, err)
+
+		// the default timeout applies to the calls without a deadline
+		start := time.Now()
+		_, err = c.Eth().BlockNumber()
+		assert.Error(t, err, addr)
+		assert.True(t, time.Since(start) < 5*time.Second, addr)
+
+		// cancelling the context aborts the call
+		c.SetTimeout(0)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		_, err = c.Eth().BlockNumberContext(ctx)
+		assert.Error(t, err, addr)
+		assert.Equal(t, context.Canceled, ctx.Err())
+
+		var num hexutil.Uint64
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+		err = c.BatchCallContext(ctx, []BatchElem{{Method: "eth_blockNumber", Result: &num}})
+		cancel()
+		assert.Error(t, err, addr)
+
+		// the deadline bounds every call of the batch
+		c.SetTimeout(100 * time.Millisecond)
+		start = time.Now()
+		err = c.BatchCall([]BatchElem{
+			{Method: "eth_blockNumber", Result: &num},
+			{Method: "eth_chainId", Result: &num},
+			{Method: "eth_gasPrice", Result: &num},
+		})
+		assert.Error(t, err, addr)
+		assert.True(t, time.Since(start) < 5*time.Second, addr)
+
+		c.Close()
+	}
+}
@@ -0,0 +1,45 @@
+package jsonrpc
+
+import (
+	"context"
+
+	"github.com/laizy/web3"
+)
+
+// Debug is the debug namespace
+type Debug struct {
+	c *Client
+}
+
+// Debug returns the reference to the debug namespace
+func (c *Client) Debug() *Debug {
+	return c.endpoints.d
+}
+
+// StorageEntry is a storage slot returned by debug_storageRangeAt. Key is nil if the node
+// does not know the preimage of the hashed slot key.
+type StorageEntry struct {
+	Key   *web3.Hash `json:"key"`
+	Value web3.Hash  `json:"value"`
+}
+
+// StorageRangeResult is a range of the storage of a contract, keyed by the hashed slot key
+type StorageRangeResult struct {
+	Storage map[web3.Hash]StorageEntry `json:"storage"`
+	NextKey *web3.Hash                 `json:"nextKey"`
+}
+
+// StorageRangeAt returns at most maxResult storage slots of the contract, starting at the hashed
+// slot key start, in the state before the transaction at txIndex of the block is executed.
+func (d *Debug) StorageRangeAt(blockHash web3.Hash, txIndex uint64, addr web3.Address, start web3.Hash, maxResult uint64) (*StorageRangeResult, error) {
+	return d.StorageRangeAtContext(context.Background(), blockHash, txIndex, addr, start, maxResult)
+}
+
+// StorageRangeAtContext is like StorageRangeAt with a context
+func (d *Debug) StorageRangeAtContext(ctx context.Context, blockHash web3.Hash, txIndex uint64, addr web3.Address, start web3.Hash, maxResult uint64) (*StorageRangeResult, error) {
+	var out *StorageRangeResult
+	if err := d.c.CallContext(ctx, "debug_storageRangeAt", &out, blockHash, txIndex, addr, start, maxResult); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
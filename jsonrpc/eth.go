@@ -1,6 +1,8 @@
 package jsonrpc
 
 import (
+	"context"
+
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -22,8 +24,23 @@ func (c *Client) Eth() *Eth {
 
 // GetCode returns the code of a contract
 func (e *Eth) GetCode(addr web3.Address) (string, error) {
+	return e.GetCodeAt(addr, web3.Latest)
+}
+
+// GetCodeContext is like GetCode with a context
+func (e *Eth) GetCodeContext(ctx context.Context, addr web3.Address) (string, error) {
+	return e.GetCodeAtContext(ctx, addr, web3.Latest)
+}
+
+// GetCodeAt returns the code of a contract at the given block
+func (e *Eth) GetCodeAt(addr web3.Address, blockNumber web3.BlockNumber) (string, error) {
+	return e.GetCodeAtContext(context.Background(), addr, blockNumber)
+}
+
+// GetCodeAtContext is like GetCodeAt with a context
+func (e *Eth) GetCodeAtContext(ctx context.Context, addr web3.Address, blockNumber web3.BlockNumber) (string, error) {
 	var res string
-	if err := e.c.Call("eth_getCode", &res, addr, "latest"); err != nil {
+	if err := e.c.CallContext(ctx, "eth_getCode", &res, addr, blockNumber.String()); err != nil {
 		return "", err
 	}
 	return res, nil
@@ -31,8 +48,13 @@ func (e *Eth) GetCode(addr web3.Address) (string, error) {
 
 // Accounts returns a list of addresses owned by client.
 func (e *Eth) Accounts() ([]web3.Address, error) {
+	return e.AccountsContext(context.Background())
+}
+
+// AccountsContext is like Accounts with a context
+func (e *Eth) AccountsContext(ctx context.Context) ([]web3.Address, error) {
 	var out []web3.Address
-	if err := e.c.Call("eth_accounts", &out); err != nil {
+	if err := e.c.CallContext(ctx, "eth_accounts", &out); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -40,8 +62,13 @@ func (e *Eth) Accounts() ([]web3.Address, error) {
 
 // BlockNumber returns the number of most recent block.
 func (e *Eth) BlockNumber() (uint64, error) {
+	return e.BlockNumberContext(context.Background())
+}
+
+// BlockNumberContext is like BlockNumber with a context
+func (e *Eth) BlockNumberContext(ctx context.Context) (uint64, error) {
 	var out string
-	if err := e.c.Call("eth_blockNumber", &out); err != nil {
+	if err := e.c.CallContext(ctx, "eth_blockNumber", &out); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
@@ -49,8 +76,13 @@ func (e *Eth) BlockNumber() (uint64, error) {
 
 // GetBlockByNumber returns information about a block by block number.
 func (e *Eth) GetBlockByNumber(i web3.BlockNumber, full bool) (*web3.Block, error) {
+	return e.GetBlockByNumberContext(context.Background(), i, full)
+}
+
+// GetBlockByNumberContext is like GetBlockByNumber with a context
+func (e *Eth) GetBlockByNumberContext(ctx context.Context, i web3.BlockNumber, full bool) (*web3.Block, error) {
 	var b *web3.Block
-	if err := e.c.Call("eth_getBlockByNumber", &b, i.String(), full); err != nil {
+	if err := e.c.CallContext(ctx, "eth_getBlockByNumber", &b, i.String(), full); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -58,8 +90,13 @@ func (e *Eth) GetBlockByNumber(i web3.BlockNumber, full bool) (*web3.Block, erro
 
 // GetBlockByHash returns information about a block by hash.
 func (e *Eth) GetBlockByHash(hash web3.Hash, full bool) (*web3.Block, error) {
+	return e.GetBlockByHashContext(context.Background(), hash, full)
+}
+
+// GetBlockByHashContext is like GetBlockByHash with a context
+func (e *Eth) GetBlockByHashContext(ctx context.Context, hash web3.Hash, full bool) (*web3.Block, error) {
 	var b *web3.Block
-	if err := e.c.Call("eth_getBlockByHash", &b, hash, full); err != nil {
+	if err := e.c.CallContext(ctx, "eth_getBlockByHash", &b, hash, full); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -67,8 +104,13 @@ func (e *Eth) GetBlockByHash(hash web3.Hash, full bool) (*web3.Block, error) {
 
 // GetFilterChanges returns the filter changes for log filters
 func (e *Eth) GetFilterChanges(id string) ([]*web3.Log, error) {
+	return e.GetFilterChangesContext(context.Background(), id)
+}
+
+// GetFilterChangesContext is like GetFilterChanges with a context
+func (e *Eth) GetFilterChangesContext(ctx context.Context, id string) ([]*web3.Log, error) {
 	var raw string
-	err := e.c.Call("eth_getFilterChanges", &raw, id)
+	err := e.c.CallContext(ctx, "eth_getFilterChanges", &raw, id)
 	if err != nil {
 		return nil, err
 	}
@@ -81,29 +123,49 @@ func (e *Eth) GetFilterChanges(id string) ([]*web3.Log, error) {
 
 // GetTransactionByHash returns a transaction by his hash
 func (e *Eth) GetTransactionByHash(hash web3.Hash) (*web3.Transaction, error) {
+	return e.GetTransactionByHashContext(context.Background(), hash)
+}
+
+// GetTransactionByHashContext is like GetTransactionByHash with a context
+func (e *Eth) GetTransactionByHashContext(ctx context.Context, hash web3.Hash) (*web3.Transaction, error) {
 	var txn *web3.Transaction
-	err := e.c.Call("eth_getTransactionByHash", &txn, hash)
+	err := e.c.CallContext(ctx, "eth_getTransactionByHash", &txn, hash)
 	return txn, err
 }
 
 //GetTransactionByBlockHashAndIndex returns the transaction for the given block hash and index.
 func (e *Eth) GetTransactionByBlockHashAndIndex(blockHash web3.Hash, index uint64) (*web3.Transaction, error) {
+	return e.GetTransactionByBlockHashAndIndexContext(context.Background(), blockHash, index)
+}
+
+// GetTransactionByBlockHashAndIndexContext is like GetTransactionByBlockHashAndIndex with a context
+func (e *Eth) GetTransactionByBlockHashAndIndexContext(ctx context.Context, blockHash web3.Hash, index uint64) (*web3.Transaction, error) {
 	var txn *web3.Transaction
-	err := e.c.Call("eth_getTransactionByBlockHashAndIndex", &txn, blockHash, hexutil.Uint64(index))
+	err := e.c.CallContext(ctx, "eth_getTransactionByBlockHashAndIndex", &txn, blockHash, hexutil.Uint64(index))
 	return txn, err
 }
 
 // GetTransactionByBlockNumberAndIndex returns the transaction for the given block number and index.
 func (e *Eth) GetTransactionByBlockNumberAndIndex(blockNumber web3.BlockNumber, index uint64) (*web3.Transaction, error) {
+	return e.GetTransactionByBlockNumberAndIndexContext(context.Background(), blockNumber, index)
+}
+
+// GetTransactionByBlockNumberAndIndexContext is like GetTransactionByBlockNumberAndIndex with a context
+func (e *Eth) GetTransactionByBlockNumberAndIndexContext(ctx context.Context, blockNumber web3.BlockNumber, index uint64) (*web3.Transaction, error) {
 	var txn *web3.Transaction
-	err := e.c.Call("eth_getTransactionByBlockNumberAndIndex", &txn, blockNumber, hexutil.Uint64(index).String())
+	err := e.c.CallContext(ctx, "eth_getTransactionByBlockNumberAndIndex", &txn, blockNumber, hexutil.Uint64(index).String())
 	return txn, err
 }
 
 // GetFilterChangesBlock returns the filter changes for block filters
 func (e *Eth) GetFilterChangesBlock(id string) ([]web3.Hash, error) {
+	return e.GetFilterChangesBlockContext(context.Background(), id)
+}
+
+// GetFilterChangesBlockContext is like GetFilterChangesBlock with a context
+func (e *Eth) GetFilterChangesBlockContext(ctx context.Context, id string) ([]web3.Hash, error) {
 	var raw string
-	err := e.c.Call("eth_getFilterChanges", &raw, id)
+	err := e.c.CallContext(ctx, "eth_getFilterChanges", &raw, id)
 	if err != nil {
 		return nil, err
 	}
@@ -116,51 +178,86 @@ func (e *Eth) GetFilterChangesBlock(id string) ([]web3.Hash, error) {
 
 // NewFilter creates a new log filter
 func (e *Eth) NewFilter(filter *web3.LogFilter) (string, error) {
+	return e.NewFilterContext(context.Background(), filter)
+}
+
+// NewFilterContext is like NewFilter with a context
+func (e *Eth) NewFilterContext(ctx context.Context, filter *web3.LogFilter) (string, error) {
 	var id string
-	err := e.c.Call("eth_newFilter", &id, filter)
+	err := e.c.CallContext(ctx, "eth_newFilter", &id, filter)
 	return id, err
 }
 
 // NewBlockFilter creates a new block filter
 func (e *Eth) NewBlockFilter() (string, error) {
+	return e.NewBlockFilterContext(context.Background())
+}
+
+// NewBlockFilterContext is like NewBlockFilter with a context
+func (e *Eth) NewBlockFilterContext(ctx context.Context) (string, error) {
 	var id string
-	err := e.c.Call("eth_newBlockFilter", &id, nil)
+	err := e.c.CallContext(ctx, "eth_newBlockFilter", &id, nil)
 	return id, err
 }
 
 // UninstallFilter uninstalls a filter
 func (e *Eth) UninstallFilter(id string) (bool, error) {
+	return e.UninstallFilterContext(context.Background(), id)
+}
+
+// UninstallFilterContext is like UninstallFilter with a context
+func (e *Eth) UninstallFilterContext(ctx context.Context, id string) (bool, error) {
 	var res bool
-	err := e.c.Call("eth_uninstallFilter", &res, id)
+	err := e.c.CallContext(ctx, "eth_uninstallFilter", &res, id)
 	return res, err
 }
 
 // SendRawTransaction sends a signed transaction in rlp format.
 func (e *Eth) SendRawTransaction(data []byte) (web3.Hash, error) {
+	return e.SendRawTransactionContext(context.Background(), data)
+}
+
+// SendRawTransactionContext is like SendRawTransaction with a context
+func (e *Eth) SendRawTransactionContext(ctx context.Context, data []byte) (web3.Hash, error) {
 	var hash web3.Hash
 	hexData := "0x" + hex.EncodeToString(data)
-	err := e.c.Call("eth_sendRawTransaction", &hash, hexData)
+	err := e.c.CallContext(ctx, "eth_sendRawTransaction", &hash, hexData)
 	return hash, err
 }
 
 // SendTransaction creates new message call transaction or a contract creation.
 func (e *Eth) SendTransaction(txn *web3.Transaction) (web3.Hash, error) {
+	return e.SendTransactionContext(context.Background(), txn)
+}
+
+// SendTransactionContext is like SendTransaction with a context
+func (e *Eth) SendTransactionContext(ctx context.Context, txn *web3.Transaction) (web3.Hash, error) {
 	var hash web3.Hash
-	err := e.c.Call("eth_sendTransaction", &hash, txn)
+	err := e.c.CallContext(ctx, "eth_sendTransaction", &hash, txn)
 	return hash, err
 }
 
 // GetTransactionReceipt returns the receipt of a transaction by transaction hash.
 func (e *Eth) GetTransactionReceipt(hash web3.Hash) (*web3.Receipt, error) {
+	return e.GetTransactionReceiptContext(context.Background(), hash)
+}
+
+// GetTransactionReceiptContext is like GetTransactionReceipt with a context
+func (e *Eth) GetTransactionReceiptContext(ctx context.Context, hash web3.Hash) (*web3.Receipt, error) {
 	var receipt *web3.Receipt
-	err := e.c.Call("eth_getTransactionReceipt", &receipt, hash)
+	err := e.c.CallContext(ctx, "eth_getTransactionReceipt", &receipt, hash)
 	return receipt, err
 }
 
 // GetNonce returns the nonce of the account
 func (e *Eth) GetNonce(addr web3.Address, blockNumber web3.BlockNumber) (uint64, error) {
+	return e.GetNonceContext(context.Background(), addr, blockNumber)
+}
+
+// GetNonceContext is like GetNonce with a context
+func (e *Eth) GetNonceContext(ctx context.Context, addr web3.Address, blockNumber web3.BlockNumber) (uint64, error) {
 	var nonce string
-	if err := e.c.Call("eth_getTransactionCount", &nonce, addr, blockNumber.String()); err != nil {
+	if err := e.c.CallContext(ctx, "eth_getTransactionCount", &nonce, addr, blockNumber.String()); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(nonce)
@@ -168,11 +265,16 @@ func (e *Eth) GetNonce(addr web3.Address, blockNumber web3.BlockNumber) (uint64,
 
 // StorageAt returns the value of key in the contract storage of the given account.
 func (ec *Eth) GetStorage(account web3.Address, key web3.Hash, blockNumber web3.BlockNumber) (web3.Hash, error) {
+	return ec.GetStorageContext(context.Background(), account, key, blockNumber)
+}
+
+// GetStorageContext is like GetStorage with a context
+func (ec *Eth) GetStorageContext(ctx context.Context, account web3.Address, key web3.Hash, blockNumber web3.BlockNumber) (web3.Hash, error) {
 	slot := key.String()
 	value := big.NewInt(0).SetBytes(key.Bytes())
 	slot = fmt.Sprintf("0x%x", value)
 	var out string
-	if err := ec.c.Call("eth_getStorageAt", &out, account, slot, blockNumber.String()); err != nil {
+	if err := ec.c.CallContext(ctx, "eth_getStorageAt", &out, account, slot, blockNumber.String()); err != nil {
 		return web3.Hash{}, err
 	}
 
@@ -181,8 +283,13 @@ func (ec *Eth) GetStorage(account web3.Address, key web3.Hash, blockNumber web3.
 
 // GetBalance returns the balance of the account of given address.
 func (e *Eth) GetBalance(addr web3.Address, blockNumber web3.BlockNumber) (*big.Int, error) {
+	return e.GetBalanceContext(context.Background(), addr, blockNumber)
+}
+
+// GetBalanceContext is like GetBalance with a context
+func (e *Eth) GetBalanceContext(ctx context.Context, addr web3.Address, blockNumber web3.BlockNumber) (*big.Int, error) {
 	var out string
-	if err := e.c.Call("eth_getBalance", &out, addr, blockNumber.String()); err != nil {
+	if err := e.c.CallContext(ctx, "eth_getBalance", &out, addr, blockNumber.String()); err != nil {
 		return nil, err
 	}
 	b, ok := new(big.Int).SetString(out[2:], 16)
@@ -194,29 +301,101 @@ func (e *Eth) GetBalance(addr web3.Address, blockNumber web3.BlockNumber) (*big.
 
 // GasPrice returns the current price per gas in wei.
 func (e *Eth) GasPrice() (uint64, error) {
+	return e.GasPriceContext(context.Background())
+}
+
+// GasPriceContext is like GasPrice with a context
+func (e *Eth) GasPriceContext(ctx context.Context) (uint64, error) {
 	var out string
-	if err := e.c.Call("eth_gasPrice", &out); err != nil {
+	if err := e.c.CallContext(ctx, "eth_gasPrice", &out); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
 }
 
+// MaxPriorityFeePerGas returns a suggestion for the EIP-1559 priority fee (tip) in wei.
+func (e *Eth) MaxPriorityFeePerGas() (*big.Int, error) {
+	return e.MaxPriorityFeePerGasContext(context.Background())
+}
+
+// MaxPriorityFeePerGasContext is like MaxPriorityFeePerGas with a context
+func (e *Eth) MaxPriorityFeePerGasContext(ctx context.Context) (*big.Int, error) {
+	var out string
+	if err := e.c.CallContext(ctx, "eth_maxPriorityFeePerGas", &out); err != nil {
+		return nil, err
+	}
+	return parseBigInt(out), nil
+}
+
 // Call executes a new message call immediately without creating a transaction on the block chain.
 func (e *Eth) Call(msg *web3.CallMsg, block web3.BlockNumber) (string, error) {
+	return e.CallContext(context.Background(), msg, block)
+}
+
+// CallContext is like Call with a context
+func (e *Eth) CallContext(ctx context.Context, msg *web3.CallMsg, block web3.BlockNumber) (string, error) {
 	var out string
-	if err := e.c.Call("eth_call", &out, msg, block.String()); err != nil {
+	if err := e.c.CallContext(ctx, "eth_call", &out, msg, block.String()); err != nil {
 		return "", err
 	}
 	return out, nil
 }
 
+// CreateAccessList returns the access list of the message executed at the given block and the
+// gas used by it.
+func (e *Eth) CreateAccessList(msg *web3.CallMsg, block web3.BlockNumber) (web3.AccessList, uint64, error) {
+	return e.CreateAccessListContext(context.Background(), msg, block)
+}
+
+// CreateAccessListContext is like CreateAccessList with a context
+func (e *Eth) CreateAccessListContext(ctx context.Context, msg *web3.CallMsg, block web3.BlockNumber) (web3.AccessList, uint64, error) {
+	var out struct {
+		AccessList web3.AccessList `json:"accessList"`
+		GasUsed    string          `json:"gasUsed"`
+		Error      string          `json:"error"`
+	}
+	if err := e.c.CallContext(ctx, "eth_createAccessList", &out, msg, block.String()); err != nil {
+		return nil, 0, err
+	}
+	if out.Error != "" {
+		return nil, 0, fmt.Errorf("create access list: %s", out.Error)
+	}
+	gasUsed, err := parseUint64orHex(out.GasUsed)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out.AccessList, gasUsed, nil
+}
+
+// GetProof returns the merkle proof of the account and of the given storage slots at the block
+func (e *Eth) GetProof(addr web3.Address, slots []web3.Hash, block web3.BlockNumber) (*web3.AccountProof, error) {
+	return e.GetProofContext(context.Background(), addr, slots, block)
+}
+
+// GetProofContext is like GetProof with a context
+func (e *Eth) GetProofContext(ctx context.Context, addr web3.Address, slots []web3.Hash, block web3.BlockNumber) (*web3.AccountProof, error) {
+	if slots == nil {
+		slots = []web3.Hash{}
+	}
+	var out *web3.AccountProof
+	if err := e.c.CallContext(ctx, "eth_getProof", &out, addr, slots, block.String()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EstimateGasContract estimates the gas to deploy a contract
 func (e *Eth) EstimateGasContract(bin []byte) (uint64, error) {
+	return e.EstimateGasContractContext(context.Background(), bin)
+}
+
+// EstimateGasContractContext is like EstimateGasContract with a context
+func (e *Eth) EstimateGasContractContext(ctx context.Context, bin []byte) (uint64, error) {
 	var out string
 	msg := map[string]interface{}{
 		"data": "0x" + hex.EncodeToString(bin),
 	}
-	if err := e.c.Call("eth_estimateGas", &out, msg); err != nil {
+	if err := e.c.CallContext(ctx, "eth_estimateGas", &out, msg); err != nil {
 		return 0, err
 	}
 	return e.parseAndApplyFactor(out)
@@ -235,8 +414,13 @@ func (e *Eth) parseAndApplyFactor(out string) (uint64, error) {
 
 // EstimateGas generates and returns an estimate of how much gas is necessary to allow the transaction to complete.
 func (e *Eth) EstimateGas(msg *web3.CallMsg) (uint64, error) {
+	return e.EstimateGasContext(context.Background(), msg)
+}
+
+// EstimateGasContext is like EstimateGas with a context
+func (e *Eth) EstimateGasContext(ctx context.Context, msg *web3.CallMsg) (uint64, error) {
 	var out string
-	if err := e.c.Call("eth_estimateGas", &out, msg); err != nil {
+	if err := e.c.CallContext(ctx, "eth_estimateGas", &out, msg); err != nil {
 		return 0, err
 	}
 	return e.parseAndApplyFactor(out)
@@ -244,8 +428,13 @@ func (e *Eth) EstimateGas(msg *web3.CallMsg) (uint64, error) {
 
 // GetLogs returns an array of all logs matching a given filter object
 func (e *Eth) GetLogs(filter *web3.LogFilter) ([]*web3.Log, error) {
+	return e.GetLogsContext(context.Background(), filter)
+}
+
+// GetLogsContext is like GetLogs with a context
+func (e *Eth) GetLogsContext(ctx context.Context, filter *web3.LogFilter) ([]*web3.Log, error) {
 	var out []*web3.Log
-	if err := e.c.Call("eth_getLogs", &out, filter); err != nil {
+	if err := e.c.CallContext(ctx, "eth_getLogs", &out, filter); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -253,8 +442,13 @@ func (e *Eth) GetLogs(filter *web3.LogFilter) ([]*web3.Log, error) {
 
 // ChainID returns the id of the chain
 func (e *Eth) ChainID() (*big.Int, error) {
+	return e.ChainIDContext(context.Background())
+}
+
+// ChainIDContext is like ChainID with a context
+func (e *Eth) ChainIDContext(ctx context.Context) (*big.Int, error) {
 	var out string
-	if err := e.c.Call("eth_chainId", &out); err != nil {
+	if err := e.c.CallContext(ctx, "eth_chainId", &out); err != nil {
 		return nil, err
 	}
 	return parseBigInt(out), nil
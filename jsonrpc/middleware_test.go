@@ -0,0 +1,218 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/laizy/web3/jsonrpc/codec"
+	"github.com/laizy/web3/jsonrpc/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyTransport fails the first calls of each method with a limit exceeded error
+type flakyTransport struct {
+	lock     sync.Mutex
+	failures map[string]int // method to the number of calls left to fail
+	calls    map[string]int
+	batches  int
+}
+
+func newFlakyTransport(failures map[string]int) *flakyTransport {
+	return &flakyTransport{failures: failures, calls: map[string]int{}}
+}
+
+func (f *flakyTransport) call(method string, out interface{}) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls[method]++
+	if f.failures[method] > 0 {
+		f.failures[method]--
+		return &codec.ErrorObject{Code: -32005, Message: "limit exceeded"}
+	}
+	return json.Unmarshal([]byte(`"0x1"`), out)
+}
+
+func (f *flakyTransport) Call(method string, out interface{}, params ...interface{}) error {
+	return f.CallContext(context.Background(), method, out, params...)
+}
+
+func (f *flakyTransport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	return f.call(method, out)
+}
+
+func (f *flakyTransport) BatchCall(elems []BatchElem) error {
+	return f.BatchCallContext(context.Background(), elems)
+}
+
+func (f *flakyTransport) BatchCallContext(ctx context.Context, elems []BatchElem) error {
+	f.lock.Lock()
+	f.batches++
+	f.lock.Unlock()
+	for i := range elems {
+		elems[i].Error = f.call(elems[i].Method, elems[i].Result)
+	}
+	return nil
+}
+
+func (f *flakyTransport) Close() error {
+	return nil
+}
+
+func testRetryConfig() *transport.RetryConfig {
+	config := transport.DefaultRetryConfig()
+	config.MinBackoff = time.Millisecond
+	return config
+}
+
+func TestMiddlewareRetry(t *testing.T) {
+	f := newFlakyTransport(map[string]int{"eth_blockNumber": 2, "eth_gasPrice": 10})
+	c := NewClientWithTransport(f, DefaultConfig())
+	c.Use(transport.Retry(testRetryConfig()))
+
+	var out string
+	assert.NoError(t, c.Call("eth_blockNumber", &out))
+	assert.Equal(t, "0x1", out)
+	assert.Equal(t, 3, f.calls["eth_blockNumber"])
+
+	// gives up after MaxRetries
+	err := c.Call("eth_gasPrice", &out)
+	assert.True(t, transport.IsRetryableError(err))
+	assert.Equal(t, 6, f.calls["eth_gasPrice"])
+
+	// errors which are not retryable are returned at once
+	config := testRetryConfig()
+	config.Retryable = func(err error) bool { return false }
+	f = newFlakyTransport(map[string]int{"eth_blockNumber": 1})
+	c = NewClientWithTransport(f, DefaultConfig())
+	c.Use(transport.Retry(config))
+	assert.Error(t, c.Call("eth_blockNumber", &out))
+	assert.Equal(t, 1, f.calls["eth_blockNumber"])
+}
+
+func TestMiddlewareRetryBatch(t *testing.T) {
+	f := newFlakyTransport(map[string]int{"eth_getBalance": 1})
+	c := NewClientWithTransport(f, DefaultConfig())
+	c.Use(transport.Retry(testRetryConfig()))
+
+	elems := []BatchElem{
+		{Method: "eth_blockNumber", Result: new(string)},
+		{Method: "eth_getBalance", Result: new(string)},
+		{Method: "eth_methodNotFound", Result: new(string)},
+	}
+	assert.NoError(t, c.BatchCall(elems))
+	for _, elem := range elems {
+		assert.NoError(t, elem.Error)
+		assert.Equal(t, "0x1", *elem.Result.(*string))
+	}
+	// only the failed call is sent again
+	assert.Equal(t, 2, f.batches)
+	assert.Equal(t, 1, f.calls["eth_blockNumber"])
+	assert.Equal(t, 2, f.calls["eth_getBalance"])
+}
+
+func TestIsRetryableError(t *testing.T) {
+	cases := []struct {
+		err       error
+		retryable bool
+	}{
+		{&codec.ErrorObject{Code: -32005, Message: "limit exceeded"}, true},
+		{&codec.ErrorObject{Code: -32000, Message: "header not found"}, true},
+		{&codec.ErrorObject{Code: -32000, Message: "Too Many Requests"}, true},
+		{&codec.ErrorObject{Code: -32000, Message: "execution reverted"}, false},
+		{&transport.HTTPError{StatusCode: 429}, true},
+		{&transport.HTTPError{StatusCode: 503}, true},
+		{&transport.HTTPError{StatusCode: 500}, false},
+		{&transport.HTTPError{StatusCode: 502}, false},
+		{&transport.HTTPError{StatusCode: 504}, false},
+		{fmt.Errorf("connection reset"), false},
+		{context.DeadlineExceeded, false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.retryable, transport.IsRetryableError(c.err), c.err.Error())
+	}
+
+	// other server errors are opt-in
+	retryable := transport.RetryHTTPStatus(502, 504)
+	assert.True(t, retryable(&transport.HTTPError{StatusCode: 502}))
+	assert.True(t, retryable(&transport.HTTPError{StatusCode: 504}))
+	assert.True(t, retryable(&transport.HTTPError{StatusCode: 429}))
+	assert.False(t, retryable(&transport.HTTPError{StatusCode: 500}))
+	assert.True(t, retryable(&codec.ErrorObject{Code: -32005, Message: "limit exceeded"}))
+}
+
+func TestMiddlewareRateLimit(t *testing.T) {
+	f := newFlakyTransport(nil)
+	c := NewClientWithTransport(f, DefaultConfig())
+	c.Use(transport.RateLimit(20, 1))
+
+	var out string
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, c.Call("eth_blockNumber", &out))
+	}
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+
+	// the wait is aborted with the context
+	c = NewClientWithTransport(f, DefaultConfig())
+	c.Use(transport.RateLimit(0.1, 1))
+	assert.NoError(t, c.Call("eth_blockNumber", &out))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, c.CallContext(ctx, "eth_blockNumber", &out))
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	var order []string
+	interceptor := func(name string) transport.Interceptor {
+		return func(next transport.Handler) transport.Handler {
+			return func(ctx context.Context, req *transport.Request) error {
+				order = append(order, name)
+				return next(ctx, req)
+			}
+		}
+	}
+	c := NewClientWithTransport(newFlakyTransport(nil), DefaultConfig())
+	c.Use(interceptor("a"), interceptor("b"))
+	c.Use(interceptor("c"))
+
+	var out string
+	assert.NoError(t, c.Call("eth_blockNumber", &out))
+	assert.Equal(t, []string{"a", "b", "c"}, order)
+}
+
+func TestMiddlewareLoggingAndMetrics(t *testing.T) {
+	var logs []string
+	observed := map[string]error{}
+	f := newFlakyTransport(map[string]int{"eth_gasPrice": 1})
+	c := NewClientWithTransport(f, DefaultConfig())
+	c.Use(
+		transport.Logging(func(format string, args ...interface{}) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		}),
+		transport.Metrics(func(method string, duration time.Duration, err error) {
+			observed[method] = err
+		}),
+	)
+
+	var out string
+	assert.NoError(t, c.Call("eth_blockNumber", &out, "0x1"))
+	assert.Error(t, c.Call("eth_gasPrice", &out))
+	assert.NoError(t, c.BatchCall([]BatchElem{{Method: "eth_chainId", Result: new(string)}}))
+
+	assert.Equal(t, 6, len(logs))
+	assert.Equal(t, `rpc request: eth_blockNumber ["0x1"]`, logs[0])
+	assert.True(t, strings.HasPrefix(logs[1], "rpc response"))
+	assert.True(t, strings.HasSuffix(logs[1], `"0x1"`))
+	assert.True(t, strings.HasPrefix(logs[3], "rpc error"))
+	assert.Equal(t, `rpc batch request: [["eth_chainId",null]]`, logs[4])
+
+	assert.Equal(t, 3, len(observed))
+	assert.NoError(t, observed["eth_blockNumber"])
+	assert.Error(t, observed["eth_gasPrice"])
+	assert.NoError(t, observed["eth_chainId"])
+}
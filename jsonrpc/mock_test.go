@@ -0,0 +1,177 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/laizy/web3/jsonrpc/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockServer is a jsonrpc server answering with fixed results over http, websocket and ipc,
+// eth_subscribe returns a new subscription id on every call
+type mockServer struct {
+	results  map[string]string // method to the raw json result
+	requests int64             // number of received http requests or messages
+	subs     int64             // number of eth_subscribe calls
+	subFails int64             // number of the next eth_subscribe calls to fail
+
+	http     *httptest.Server
+	ipcDir   string
+	listener net.Listener
+
+	lock  sync.Mutex
+	conns []*websocket.Conn
+}
+
+func newMockServer(t *testing.T, results map[string]string) *mockServer {
+	s := &mockServer{results: results}
+	s.http = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	dir, err := ioutil.TempDir("", "web3-ipc")
+	assert.NoError(t, err)
+	s.ipcDir = dir
+	s.listener, err = net.Listen("unix", filepath.Join(dir, "geth.ipc"))
+	assert.NoError(t, err)
+	go s.serveIPC()
+	return s
+}
+
+// addrs returns the http, websocket and ipc addresses
+func (s *mockServer) addrs() []string {
+	return []string{s.http.URL, s.wsAddr(), s.listener.Addr().String()}
+}
+
+func (s *mockServer) wsAddr() string {
+	return "ws" + strings.TrimPrefix(s.http.URL, "http")
+}
+
+func (s *mockServer) Close() {
+	s.closeConns()
+	s.listener.Close()
+	s.http.Close()
+	os.RemoveAll(s.ipcDir)
+}
+
+// closeConns drops the websocket connections
+func (s *mockServer) closeConns() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *mockServer) handle(req *codec.Request) *codec.Response {
+	resp := &codec.Response{ID: req.ID}
+	if req.Method == "eth_subscribe" && atomic.AddInt64(&s.subFails, -1) >= 0 {
+		resp.Error = &codec.ErrorObject{Code: -32000, Message: "subscription failed"}
+	} else if req.Method == "eth_subscribe" {
+		resp.Result = json.RawMessage(fmt.Sprintf(`"0x%x"`, atomic.AddInt64(&s.subs, 1)))
+	} else if result, ok := s.results[req.Method]; ok {
+		resp.Result = json.RawMessage(result)
+	} else {
+		resp.Error = &codec.ErrorObject{Code: -32601, Message: "the method " + req.Method + " does not exist"}
+	}
+	return resp
+}
+
+// handleMessage answers a request or a batch, the batch responses are sent in reverse order
+func (s *mockServer) handleMessage(msg []byte) []byte {
+	atomic.AddInt64(&s.requests, 1)
+	var res interface{}
+	if len(msg) != 0 && msg[0] == '[' {
+		var reqs []*codec.Request
+		if err := json.Unmarshal(msg, &reqs); err != nil {
+			return nil
+		}
+		resps := make([]*codec.Response, len(reqs))
+		for i, req := range reqs {
+			resps[len(reqs)-1-i] = s.handle(req)
+		}
+		res = resps
+	} else {
+		var req codec.Request
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return nil
+		}
+		res = s.handle(&req)
+	}
+	buf, _ := json.Marshal(res)
+	return buf
+}
+
+func (s *mockServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s.lock.Lock()
+		s.conns = append(s.conns, conn)
+		s.lock.Unlock()
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := s.write(conn, s.handleMessage(msg)); err != nil {
+				return
+			}
+		}
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Write(s.handleMessage(body))
+}
+
+func (s *mockServer) write(conn *websocket.Conn, msg []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, msg)
+}
+
+// notify sends the result of the latest subscription to the websocket connections
+func (s *mockServer) notify(result string) {
+	msg := fmt.Sprintf(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0x%x","result":%s}}`,
+		atomic.LoadInt64(&s.subs), result)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, conn := range s.conns {
+		conn.WriteMessage(websocket.TextMessage, []byte(msg))
+	}
+}
+
+func (s *mockServer) serveIPC() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			dec := json.NewDecoder(conn)
+			for {
+				var msg json.RawMessage
+				if err := dec.Decode(&msg); err != nil {
+					return
+				}
+				if _, err := conn.Write(s.handleMessage(msg)); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
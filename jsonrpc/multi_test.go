@@ -0,0 +1,129 @@
+package jsonrpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/laizy/web3/jsonrpc/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMultiClient(t *testing.T, urls []string, config *transport.MultiConfig) (*Client, *transport.Multi) {
+	multi, err := transport.NewMulti(urls, config)
+	assert.NoError(t, err)
+	return NewClientWithTransport(multi, DefaultConfig()), multi
+}
+
+func TestMultiFailover(t *testing.T) {
+	var failed int64
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&failed, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	good := newMockServer(t, map[string]string{"eth_blockNumber": `"0x64"`})
+	defer good.Close()
+
+	c, multi := newMultiClient(t, []string{down.URL, good.http.URL}, &transport.MultiConfig{})
+	defer c.Close()
+
+	for i := 0; i < 4; i++ {
+		num, err := c.Eth().BlockNumber()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(100), num)
+	}
+	// the failed endpoint is only tried again when no other endpoint is left
+	assert.Equal(t, int64(1), atomic.LoadInt64(&failed))
+	status := multi.Status()
+	assert.False(t, status[0].Healthy)
+	assert.IsType(t, &transport.HTTPError{}, status[0].LastErr)
+	assert.True(t, status[1].Healthy)
+
+	// the errors of the node are not failed over
+	_, err := c.Net().Version()
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&failed))
+}
+
+func TestMultiRoundRobin(t *testing.T) {
+	a := newMockServer(t, map[string]string{"eth_blockNumber": `"0x1"`})
+	defer a.Close()
+	b := newMockServer(t, map[string]string{"eth_blockNumber": `"0x1"`})
+	defer b.Close()
+
+	c, _ := newMultiClient(t, []string{a.http.URL, b.wsAddr()}, &transport.MultiConfig{})
+	defer c.Close()
+	for i := 0; i < 10; i++ {
+		_, err := c.Eth().BlockNumber()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int64(5), atomic.LoadInt64(&a.requests))
+	assert.Equal(t, int64(5), atomic.LoadInt64(&b.requests))
+}
+
+func TestMultiLatencyAndLag(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x64"}`))
+	}))
+	defer slow.Close()
+	fast := newMockServer(t, map[string]string{"eth_blockNumber": `"0x64"`})
+	defer fast.Close()
+	lagging := newMockServer(t, map[string]string{"eth_blockNumber": `"0x1"`})
+	defer lagging.Close()
+
+	c, multi := newMultiClient(t, []string{slow.URL, lagging.http.URL, fast.http.URL}, &transport.MultiConfig{
+		Policy:              transport.LowestLatency,
+		HealthCheckInterval: time.Hour,
+		MaxBlockLag:         10,
+	})
+	defer c.Close()
+	status := multi.Status()
+	assert.Equal(t, uint64(100), status[0].BlockNumber)
+	assert.Equal(t, uint64(1), status[1].BlockNumber)
+	assert.True(t, status[0].Latency > status[2].Latency)
+
+	for i := 0; i < 5; i++ {
+		num, err := c.Eth().BlockNumber()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(100), num)
+	}
+	// health check and the calls
+	assert.Equal(t, int64(6), atomic.LoadInt64(&fast.requests))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&lagging.requests))
+}
+
+func TestMultiConnState(t *testing.T) {
+	s := newMockServer(t, map[string]string{"eth_blockNumber": `"0x1"`})
+
+	c, multi := newMultiClient(t, []string{s.wsAddr()}, &transport.MultiConfig{})
+	assert.Equal(t, transport.StateConnected, c.ConnState())
+	assert.Equal(t, transport.StateConnected, multi.State())
+
+	waitState := func(state transport.ConnState) {
+		select {
+		case got := <-c.ConnStateCh():
+			assert.Equal(t, state, got)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("state %s timeout", state)
+		}
+	}
+
+	// the only endpoint is lost, then reconnected
+	s.closeConns()
+	waitState(transport.StateReconnecting)
+	assert.Error(t, <-c.ConnErrCh())
+	waitState(transport.StateConnected)
+
+	// the endpoint keeps reconnecting while the node is down
+	s.Close()
+	waitState(transport.StateReconnecting)
+	assert.Equal(t, transport.StateReconnecting, c.ConnState())
+
+	c.Close()
+	waitState(transport.StateClosed)
+	assert.Equal(t, transport.StateClosed, c.ConnState())
+}
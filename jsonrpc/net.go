@@ -1,5 +1,7 @@
 package jsonrpc
 
+import "context"
+
 // Net is the net namespace
 type Net struct {
 	c *Client
@@ -12,8 +14,13 @@ func (c *Client) Net() *Net {
 
 // Version returns the current network id
 func (n *Net) Version() (uint64, error) {
+	return n.VersionContext(context.Background())
+}
+
+// VersionContext is like Version with a context
+func (n *Net) VersionContext(ctx context.Context) (uint64, error) {
 	var out string
-	if err := n.c.Call("net_version", &out); err != nil {
+	if err := n.c.CallContext(ctx, "net_version", &out); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
@@ -21,15 +28,25 @@ func (n *Net) Version() (uint64, error) {
 
 // Listening returns true if client is actively listening for network connections
 func (n *Net) Listening() (bool, error) {
+	return n.ListeningContext(context.Background())
+}
+
+// ListeningContext is like Listening with a context
+func (n *Net) ListeningContext(ctx context.Context) (bool, error) {
 	var out bool
-	err := n.c.Call("net_listening", &out)
+	err := n.c.CallContext(ctx, "net_listening", &out)
 	return out, err
 }
 
 // PeerCount returns number of peers currently connected to the client
 func (n *Net) PeerCount() (uint64, error) {
+	return n.PeerCountContext(context.Background())
+}
+
+// PeerCountContext is like PeerCount with a context
+func (n *Net) PeerCountContext(ctx context.Context) (uint64, error) {
 	var out string
-	if err := n.c.Call("net_peerCount", &out); err != nil {
+	if err := n.c.CallContext(ctx, "net_peerCount", &out); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
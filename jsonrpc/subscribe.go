@@ -14,7 +14,35 @@ func (c *Client) SubscriptionEnabled() bool {
 	return ok
 }
 
-// Subscribe starts a new subscription
+// ConnState returns the state of the connection, transports without a persistent connection
+// are always connected
+func (c *Client) ConnState() transport.ConnState {
+	if t, ok := c.transport.(transport.ReconnectTransport); ok {
+		return t.State()
+	}
+	return transport.StateConnected
+}
+
+// ConnStateCh returns the channel notified when the connection is lost, re-established or
+// closed, nil if the transport has no persistent connection
+func (c *Client) ConnStateCh() <-chan transport.ConnState {
+	if t, ok := c.transport.(transport.ReconnectTransport); ok {
+		return t.StateCh()
+	}
+	return nil
+}
+
+// ConnErrCh returns the channel notified of the connection errors, nil if the transport has no
+// persistent connection
+func (c *Client) ConnErrCh() <-chan error {
+	if t, ok := c.transport.(transport.ReconnectTransport); ok {
+		return t.ErrCh()
+	}
+	return nil
+}
+
+// Subscribe starts a new subscription, the subscription is re-established with the same callback
+// when the transport reconnects
 func (c *Client) Subscribe(method string, param interface{}, callback func(b []byte)) (func() error, error) {
 	pub, ok := c.transport.(transport.PubSubTransport)
 	if !ok {
@@ -2,10 +2,12 @@ package jsonrpc
 
 import (
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/laizy/web3"
+	"github.com/laizy/web3/jsonrpc/transport"
 	"github.com/laizy/web3/testutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -70,3 +72,104 @@ func TestSubscribeNewHead(t *testing.T) {
 		assert.Error(t, cancel())
 	})
 }
+
+func TestSubscribeReconnect(t *testing.T) {
+	s := newMockServer(t, nil)
+	defer s.Close()
+
+	c, err := NewClient(s.wsAddr())
+	assert.NoError(t, err)
+	assert.Equal(t, transport.StateConnected, c.ConnState())
+
+	data := make(chan string, 4)
+	_, err = c.Subscribe("newHeads", nil, func(b []byte) {
+		data <- string(b)
+	})
+	assert.NoError(t, err)
+
+	receive := func() string {
+		select {
+		case msg := <-data:
+			return msg
+		case <-time.After(2 * time.Second):
+			t.Fatal("subscription timeout")
+		}
+		return ""
+	}
+	waitState := func(state transport.ConnState) {
+		select {
+		case got := <-c.ConnStateCh():
+			assert.Equal(t, state, got)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("state %s timeout", state)
+		}
+	}
+
+	s.notify("1")
+	assert.Equal(t, "1", receive())
+
+	// the server drops the connection
+	s.closeConns()
+	waitState(transport.StateReconnecting)
+	waitState(transport.StateConnected)
+	assert.Error(t, <-c.ConnErrCh())
+
+	// the subscription is re-established with a new id, the notifications sent before the
+	// client knows the new id are dropped
+	for i := 0; i < 100 && len(data) == 0; i++ {
+		if atomic.LoadInt64(&s.subs) == 2 {
+			s.notify("2")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Equal(t, "2", receive())
+	assert.Equal(t, int64(2), atomic.LoadInt64(&s.subs))
+
+	c.Close()
+	waitState(transport.StateClosed)
+}
+
+func TestSubscribeReconnectRetry(t *testing.T) {
+	s := newMockServer(t, nil)
+	defer s.Close()
+
+	c, err := NewClient(s.wsAddr())
+	assert.NoError(t, err)
+	defer c.Close()
+
+	data := make(chan string, 4)
+	_, err = c.Subscribe("newHeads", nil, func(b []byte) {
+		data <- string(b)
+	})
+	assert.NoError(t, err)
+
+	// the first eth_subscribe after the reconnection fails
+	atomic.StoreInt64(&s.subFails, 1)
+	s.closeConns()
+
+	var errs []string
+	for len(errs) < 2 {
+		select {
+		case err := <-c.ConnErrCh():
+			errs = append(errs, err.Error())
+		case <-time.After(2 * time.Second):
+			t.Fatal("error timeout")
+		}
+	}
+	assert.Contains(t, errs[1], "resubscribe newHeads")
+
+	// the subscription is retried with backoff
+	for i := 0; i < 100 && len(data) == 0; i++ {
+		if atomic.LoadInt64(&s.subs) == 2 {
+			s.notify("2")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	select {
+	case msg := <-data:
+		assert.Equal(t, "2", msg)
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription not re-established")
+	}
+	assert.Equal(t, int64(2), atomic.LoadInt64(&s.subs))
+}
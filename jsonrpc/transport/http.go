@@ -1,26 +1,28 @@
 package transport
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"sync/atomic"
 
-	"github.com/laizy/web3"
 	"github.com/laizy/web3/jsonrpc/codec"
-	"github.com/valyala/fasthttp"
 )
 
 // HTTP is an http transport
 type HTTP struct {
 	addr   string
-	client *fasthttp.Client
+	client *http.Client
 	nextId uint64
 }
 
 func newHTTP(addr string) *HTTP {
 	return &HTTP{
 		addr:   addr,
-		client: &fasthttp.Client{},
+		client: &http.Client{},
 	}
 }
 
@@ -34,8 +36,46 @@ func (c *HTTP) nextID() uint64 {
 	return id
 }
 
+// HTTPError is returned when the server replies with a rate limit or a server error status
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http status %d: %s", e.StatusCode, e.Body)
+}
+
+// post sends the request body, the request is aborted once the context is done
+func (h *HTTP) post(ctx context.Context, raw []byte) ([]byte, error) {
+	req, err := http.NewRequest("POST", h.addr, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := h.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError {
+		return nil, &HTTPError{StatusCode: res.StatusCode, Body: string(body)}
+	}
+	return body, nil
+}
+
 // Call implements the transport interface
 func (h *HTTP) Call(method string, out interface{}, params ...interface{}) error {
+	return h.CallContext(context.Background(), method, out, params...)
+}
+
+// CallContext implements the transport interface
+func (h *HTTP) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
 	// Encode json-rpc request
 	request := codec.Request{
 		Method:  method,
@@ -57,31 +97,13 @@ func (h *HTTP) Call(method string, out interface{}, params ...interface{}) error
 		return err
 	}
 
-	req := fasthttp.AcquireRequest()
-	res := fasthttp.AcquireResponse()
-
-	defer fasthttp.ReleaseRequest(req)
-	defer fasthttp.ReleaseResponse(res)
-
-	if web3.TraceRpc {
-		fmt.Printf("http eth rpc request: %s\n", string(raw))
-	}
-
-	req.SetRequestURI(h.addr)
-	req.Header.SetMethod("POST")
-	req.Header.SetContentType("application/json")
-	req.SetBody(raw)
-
-	if err := h.client.Do(req, res); err != nil {
+	body, err := h.post(ctx, raw)
+	if err != nil {
 		return err
 	}
 
 	// Decode json-rpc response
 	var response codec.Response
-	body := res.Body()
-	if web3.TraceRpc {
-		fmt.Printf("http eth rpc response: %s\n", string(body))
-	}
 	if err := json.Unmarshal(body, &response); err != nil {
 		return err
 	}
@@ -94,3 +116,50 @@ func (h *HTTP) Call(method string, out interface{}, params ...interface{}) error
 	}
 	return nil
 }
+
+// BatchCall implements the BatchTransport interface
+func (h *HTTP) BatchCall(elems []BatchElem) error {
+	return h.BatchCallContext(context.Background(), elems)
+}
+
+// BatchCallContext implements the BatchTransport interface
+func (h *HTTP) BatchCallContext(ctx context.Context, elems []BatchElem) error {
+	if len(elems) == 0 {
+		return nil
+	}
+	requests := make([]codec.Request, len(elems))
+	for i, elem := range elems {
+		request, err := newRequest(h.nextID(), elem.Method, elem.Params)
+		if err != nil {
+			return err
+		}
+		requests[i] = request
+	}
+	raw, err := json.Marshal(requests)
+	if err != nil {
+		return err
+	}
+
+	body, err := h.post(ctx, raw)
+	if err != nil {
+		return err
+	}
+
+	var responses []codec.Response
+	if err := json.Unmarshal(body, &responses); err != nil {
+		// the server may reply with a single error object to the whole batch
+		var response codec.Response
+		if json.Unmarshal(body, &response) == nil && response.Error != nil {
+			return response.Error
+		}
+		return err
+	}
+	byID := make(map[uint64]*codec.Response, len(responses))
+	for i := range responses {
+		byID[responses[i].ID] = &responses[i]
+	}
+	for i := range elems {
+		setBatchResult(&elems[i], byID[requests[i].ID])
+	}
+	return nil
+}
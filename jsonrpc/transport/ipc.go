@@ -1,23 +1,28 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
 	"net"
 )
 
-func newIPC(addr string) (Transport, error) {
-	conn, err := net.Dial("unix", addr)
+func newIPC(ctx context.Context, addr string) (Transport, error) {
+	dial := func(ctx context.Context) (Codec, error) {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "unix", addr)
+		if err != nil {
+			return nil, err
+		}
+		return &ipcCodec{
+			buf:  json.RawMessage{},
+			conn: conn,
+			dec:  json.NewDecoder(conn),
+		}, nil
+	}
+	codec, err := dial(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	codec := &ipcCodec{
-		buf:  json.RawMessage{},
-		conn: conn,
-		dec:  json.NewDecoder(conn),
-	}
-
-	return newStream(codec)
+	return newStream(codec, dial)
 }
 
 type ipcCodec struct {
@@ -0,0 +1,319 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/laizy/web3/jsonrpc/codec"
+)
+
+// Request is a call passing through the interceptors, Batch is set for batch calls
+type Request struct {
+	Method string
+	Params []interface{}
+	Out    interface{}
+	Batch  []BatchElem
+}
+
+// IsBatch reports whether the request is a batch call
+func (r *Request) IsBatch() bool {
+	return r.Batch != nil
+}
+
+// Handler sends a request, the error of each call of a batch is set on its element
+type Handler func(ctx context.Context, req *Request) error
+
+// Interceptor wraps a handler to act before and after the request is sent
+type Interceptor func(next Handler) Handler
+
+// Chain wraps the handler with the interceptors, the first interceptor is the outermost one
+func Chain(handler Handler, interceptors ...Interceptor) Handler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		handler = interceptors[i](handler)
+	}
+	return handler
+}
+
+// Send returns the handler sending the requests with the transport
+func Send(t Transport) Handler {
+	return func(ctx context.Context, req *Request) error {
+		if !req.IsBatch() {
+			return t.CallContext(ctx, req.Method, req.Out, req.Params...)
+		}
+		if bt, ok := t.(BatchTransport); ok {
+			return bt.BatchCallContext(ctx, req.Batch)
+		}
+		for i := range req.Batch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			elem := &req.Batch[i]
+			elem.Error = t.CallContext(ctx, elem.Method, elem.Result, elem.Params...)
+		}
+		return nil
+	}
+}
+
+// size returns the number of calls of the request
+func (r *Request) size() int {
+	if r.IsBatch() {
+		return len(r.Batch)
+	}
+	return 1
+}
+
+type tokenBucket struct {
+	lock   sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes n tokens and returns the delay until they are available
+func (b *tokenBucket) reserve(n float64) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) cancel(n float64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.tokens += n
+}
+
+// RateLimit limits the calls to rate calls per second with bursts of burst calls, every call of a
+// batch takes a token. The requests wait for the tokens or fail when the context is done.
+func RateLimit(rate float64, burst int) Interceptor {
+	bucket := &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) error {
+			n := float64(req.size())
+			if delay := bucket.reserve(n); delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					bucket.cancel(n)
+					return ctx.Err()
+				}
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// RetryConfig is the configuration of the retry interceptor
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries of a call
+	MaxRetries int
+	// MinBackoff is the delay before the first retry, doubled on every retry up to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retryable reports whether a failed call is retried, IsRetryableError if nil
+	Retryable func(err error) bool
+}
+
+// DefaultRetryConfig returns the default retry config
+func DefaultRetryConfig() *RetryConfig {
+	return &RetryConfig{
+		MaxRetries: 5,
+		MinBackoff: 200 * time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+		Retryable:  IsRetryableError,
+	}
+}
+
+// IsRetryableError reports whether the node rejected the call because of the rate limit or
+// because it is not synchronized yet, so the call can be retried without being processed twice.
+// Connection errors are not retryable since the call may have been processed, neither are the
+// http server errors other than 429 and 503, see RetryHTTPStatus to retry them.
+func IsRetryableError(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode == http.StatusServiceUnavailable
+	}
+	var rpcErr *codec.ErrorObject
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	if rpcErr.Code == limitExceededCode {
+		return true
+	}
+	msg := strings.ToLower(rpcErr.Message)
+	for _, reason := range []string{"rate limit", "too many requests", "header not found"} {
+		if strings.Contains(msg, reason) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryHTTPStatus returns a Retryable func which also retries the http errors with the given
+// status codes, on top of the errors reported by IsRetryableError
+func RetryHTTPStatus(codes ...int) func(err error) bool {
+	return func(err error) bool {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			for _, code := range codes {
+				if httpErr.StatusCode == code {
+					return true
+				}
+			}
+		}
+		return IsRetryableError(err)
+	}
+}
+
+// Retry retries the calls which fail with a retryable error with exponential backoff, only the
+// failed calls of a batch are retried
+func Retry(config *RetryConfig) Interceptor {
+	retryable := config.Retryable
+	if retryable == nil {
+		retryable = IsRetryableError
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) error {
+			backoff := config.MinBackoff
+			pending := req
+			// indexes of the pending calls in the batch
+			var indexes []int
+			for attempt := 0; ; attempt++ {
+				err := next(ctx, pending)
+				for i, index := range indexes {
+					req.Batch[index].Error = pending.Batch[i].Error
+				}
+				if err != nil {
+					if !retryable(err) {
+						return err
+					}
+				} else {
+					if !req.IsBatch() {
+						return nil
+					}
+					if pending, indexes = failedCalls(req, retryable); pending == nil {
+						return nil
+					}
+				}
+				if attempt >= config.MaxRetries {
+					return err
+				}
+
+				timer := time.NewTimer(backoff)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return err
+				}
+				if backoff *= 2; config.MaxBackoff > 0 && backoff > config.MaxBackoff {
+					backoff = config.MaxBackoff
+				}
+			}
+		}
+	}
+}
+
+// failedCalls returns the batch of the calls which failed with a retryable error and their
+// indexes in the request, nil if there is none
+func failedCalls(req *Request, retryable func(err error) bool) (*Request, []int) {
+	var batch []BatchElem
+	var indexes []int
+	for i, elem := range req.Batch {
+		if elem.Error != nil && retryable(elem.Error) {
+			elem.Error = nil
+			batch = append(batch, elem)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(batch) == 0 {
+		return nil, nil
+	}
+	return &Request{Batch: batch}, indexes
+}
+
+// Logging logs the requests and the responses with logf
+func Logging(logf func(format string, args ...interface{})) Interceptor {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) error {
+			start := time.Now()
+			if req.IsBatch() {
+				calls := make([]interface{}, len(req.Batch))
+				for i, elem := range req.Batch {
+					calls[i] = []interface{}{elem.Method, elem.Params}
+				}
+				logf("rpc batch request: %s", jsonString(calls))
+			} else {
+				logf("rpc request: %s %s", req.Method, jsonString(req.Params))
+			}
+			err := next(ctx, req)
+			elapsed := time.Since(start)
+			switch {
+			case err != nil:
+				logf("rpc error (%v): %v", elapsed, err)
+			case req.IsBatch():
+				results := make([]interface{}, len(req.Batch))
+				for i, elem := range req.Batch {
+					if elem.Error != nil {
+						results[i] = elem.Error.Error()
+					} else {
+						results[i] = elem.Result
+					}
+				}
+				logf("rpc batch response (%v): %s", elapsed, jsonString(results))
+			default:
+				logf("rpc response (%v): %s", elapsed, jsonString(req.Out))
+			}
+			return err
+		}
+	}
+}
+
+func jsonString(v interface{}) string {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err.Error()
+	}
+	return string(buf)
+}
+
+// Metrics calls observe with the duration and the error of every call, the calls of a batch are
+// observed with the duration of the batch
+func Metrics(observe func(method string, duration time.Duration, err error)) Interceptor {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) error {
+			start := time.Now()
+			err := next(ctx, req)
+			duration := time.Since(start)
+			if !req.IsBatch() {
+				observe(req.Method, duration, err)
+				return err
+			}
+			for _, elem := range req.Batch {
+				if err != nil {
+					observe(elem.Method, duration, err)
+				} else {
+					observe(elem.Method, duration, elem.Error)
+				}
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,474 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Policy is the order in which the multi transport tries the endpoints
+type Policy int
+
+const (
+	// RoundRobin spreads the calls over the endpoints in turn
+	RoundRobin Policy = iota
+	// LowestLatency sends the calls to the endpoint with the lowest average latency
+	LowestLatency
+)
+
+// MultiConfig is the configuration of the multi transport
+type MultiConfig struct {
+	Policy Policy
+	// HealthCheckInterval is the interval of the eth_blockNumber health checks, zero disables them
+	HealthCheckInterval time.Duration
+	// AttemptTimeout bounds the call to a single endpoint before failing over to the next one,
+	// zero disables it
+	AttemptTimeout time.Duration
+	// MaxBlockLag avoids the endpoints whose block number is more than MaxBlockLag blocks behind
+	// the highest one seen by the health checks, zero disables it
+	MaxBlockLag uint64
+}
+
+// DefaultMultiConfig returns the default multi transport config
+func DefaultMultiConfig() *MultiConfig {
+	return &MultiConfig{
+		Policy:              RoundRobin,
+		HealthCheckInterval: 15 * time.Second,
+		AttemptTimeout:      10 * time.Second,
+	}
+}
+
+// latencyWeight is the weight of the last call in the moving average of the latency
+const latencyWeight = 0.2
+
+type endpoint struct {
+	url       string
+	transport Transport
+
+	lock        sync.RWMutex
+	healthy     bool
+	latency     time.Duration // moving average
+	blockNumber uint64
+	lastErr     error
+}
+
+// usable reports whether the endpoint is healthy and, for a persistent connection, connected
+func (e *endpoint) usable() bool {
+	e.lock.RLock()
+	t, healthy := e.transport, e.healthy
+	e.lock.RUnlock()
+	if !healthy || t == nil {
+		return false
+	}
+	if rt, ok := t.(ReconnectTransport); ok {
+		return rt.State() == StateConnected
+	}
+	return true
+}
+
+func (e *endpoint) record(latency time.Duration, err error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.lastErr = err
+	if err != nil {
+		if IsTransientError(err) {
+			e.healthy = false
+		}
+		return
+	}
+	e.healthy = true
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(e.latency))
+	}
+}
+
+// EndpointStatus is the status of an endpoint of the multi transport
+type EndpointStatus struct {
+	URL         string
+	Healthy     bool
+	Latency     time.Duration // moving average of the call latency
+	BlockNumber uint64        // block number of the last health check
+	LastErr     error
+}
+
+// Multi is a transport which spreads the calls over several endpoints. A call which fails with a
+// transient error, such as a connection error or a 429/5xx response, is sent to the next endpoint.
+// Unhealthy and lagging endpoints are only tried when no other endpoint is left. The connection
+// state is connected as long as one endpoint is usable.
+type Multi struct {
+	config    MultiConfig
+	endpoints []*endpoint
+	next      uint64
+
+	stateLock sync.Mutex
+	state     ConnState
+	stateCh   chan ConnState
+	errCh     chan error
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+var _ ReconnectTransport = (*Multi)(nil)
+
+// NewMulti creates a transport over the endpoints, the endpoints which fail to connect are
+// dialed again by the health checks. It fails if no endpoint can be connected. Subscriptions are
+// not failed over: a subscription stays on the endpoint it was made on and is only re-established
+// when that endpoint reconnects.
+func NewMulti(urls []string, config *MultiConfig) (*Multi, error) {
+	return NewMultiContext(context.Background(), urls, config)
+}
+
+// NewMultiContext is like NewMulti with a context bounding the dial of the endpoints
+func NewMultiContext(ctx context.Context, urls []string, config *MultiConfig) (*Multi, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no endpoint")
+	}
+	m := &Multi{
+		config:  *config,
+		stateCh: make(chan ConnState, notifyBufferSize),
+		errCh:   make(chan error, notifyBufferSize),
+		closeCh: make(chan struct{}),
+	}
+	var lastErr error
+	connected := 0
+	for _, url := range urls {
+		e := &endpoint{url: url}
+		e.transport, e.lastErr = NewTransportContext(ctx, url)
+		if e.lastErr != nil {
+			lastErr = e.lastErr
+		} else {
+			e.healthy = true
+			connected++
+		}
+		m.endpoints = append(m.endpoints, e)
+	}
+	if connected == 0 {
+		return nil, lastErr
+	}
+	for _, e := range m.endpoints {
+		if e.transport != nil {
+			m.watch(e, e.transport)
+		}
+	}
+	if m.config.HealthCheckInterval > 0 {
+		m.HealthCheck()
+		go m.healthCheckLoop()
+	}
+	return m, nil
+}
+
+// State implements the ReconnectTransport interface
+func (m *Multi) State() ConnState {
+	m.stateLock.Lock()
+	defer m.stateLock.Unlock()
+	return m.state
+}
+
+// StateCh implements the ReconnectTransport interface
+func (m *Multi) StateCh() <-chan ConnState {
+	return m.stateCh
+}
+
+// ErrCh implements the ReconnectTransport interface, it is notified of the connection errors of
+// the endpoints
+func (m *Multi) ErrCh() <-chan error {
+	return m.errCh
+}
+
+func (m *Multi) setState(state ConnState) {
+	m.stateLock.Lock()
+	defer m.stateLock.Unlock()
+	if m.state == state || m.state == StateClosed {
+		return
+	}
+	m.state = state
+	select {
+	case m.stateCh <- state:
+	default:
+	}
+}
+
+func (m *Multi) notifyErr(err error) {
+	select {
+	case m.errCh <- err:
+	default:
+	}
+}
+
+// updateState sets the state to connected if an endpoint is usable, reconnecting otherwise
+func (m *Multi) updateState() {
+	state := StateReconnecting
+	for _, e := range m.endpoints {
+		if e.usable() {
+			state = StateConnected
+			break
+		}
+	}
+	m.setState(state)
+}
+
+// watch follows the connection state and the errors of an endpoint with a persistent connection
+func (m *Multi) watch(e *endpoint, t Transport) {
+	rt, ok := t.(ReconnectTransport)
+	if !ok {
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-m.closeCh:
+				return
+			case <-rt.StateCh():
+				m.updateState()
+			case err := <-rt.ErrCh():
+				m.notifyErr(fmt.Errorf("%s: %v", e.url, err))
+			}
+		}
+	}()
+}
+
+// Status returns the status of the endpoints
+func (m *Multi) Status() []EndpointStatus {
+	status := make([]EndpointStatus, len(m.endpoints))
+	for i, e := range m.endpoints {
+		e.lock.RLock()
+		status[i] = EndpointStatus{
+			URL:         e.url,
+			Healthy:     e.healthy,
+			Latency:     e.latency,
+			BlockNumber: e.blockNumber,
+			LastErr:     e.lastErr,
+		}
+		e.lock.RUnlock()
+	}
+	return status
+}
+
+// candidates returns the endpoints in the order they are tried, the available endpoints ordered
+// by the policy followed by the unhealthy and lagging ones
+func (m *Multi) candidates() []*endpoint {
+	var highest uint64
+	for _, e := range m.endpoints {
+		e.lock.RLock()
+		if e.healthy && e.blockNumber > highest {
+			highest = e.blockNumber
+		}
+		e.lock.RUnlock()
+	}
+
+	var available, others []*endpoint
+	latencies := make(map[*endpoint]time.Duration, len(m.endpoints))
+	for _, e := range m.endpoints {
+		ok := e.usable()
+		e.lock.RLock()
+		if m.config.MaxBlockLag > 0 && e.blockNumber+m.config.MaxBlockLag < highest {
+			ok = false
+		}
+		latencies[e] = e.latency
+		e.lock.RUnlock()
+		if ok {
+			available = append(available, e)
+		} else {
+			others = append(others, e)
+		}
+	}
+
+	switch m.config.Policy {
+	case LowestLatency:
+		sort.SliceStable(available, func(i, j int) bool {
+			return latencies[available[i]] < latencies[available[j]]
+		})
+	default:
+		if len(available) > 1 {
+			start := int(atomic.AddUint64(&m.next, 1) % uint64(len(available)))
+			available = append(available[start:], available[:start]...)
+		}
+	}
+	return append(available, others...)
+}
+
+// do runs the request on the endpoints until one succeeds or fails with an error which is not
+// transient
+func (m *Multi) do(ctx context.Context, request func(ctx context.Context, t Transport) error) error {
+	var lastErr error
+	for _, e := range m.candidates() {
+		e.lock.RLock()
+		t := e.transport
+		e.lock.RUnlock()
+		if t == nil {
+			continue
+		}
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if m.config.AttemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, m.config.AttemptTimeout)
+		}
+		start := time.Now()
+		err := request(attemptCtx, t)
+		if err != nil && ctx.Err() == nil && attemptCtx.Err() == context.DeadlineExceeded {
+			// the endpoint timed out but the call can still be sent to the next one
+			err = fmt.Errorf("%s: %v", e.url, ErrTimeout)
+		}
+		cancel()
+		e.record(time.Since(start), err)
+		m.updateState()
+		if err == nil || !IsTransientError(err) {
+			return err
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no endpoint available")
+	}
+	return lastErr
+}
+
+// Call implements the transport interface
+func (m *Multi) Call(method string, out interface{}, params ...interface{}) error {
+	return m.CallContext(context.Background(), method, out, params...)
+}
+
+// CallContext implements the transport interface
+func (m *Multi) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	return m.do(ctx, func(ctx context.Context, t Transport) error {
+		return t.CallContext(ctx, method, out, params...)
+	})
+}
+
+// BatchCall implements the BatchTransport interface
+func (m *Multi) BatchCall(elems []BatchElem) error {
+	return m.BatchCallContext(context.Background(), elems)
+}
+
+// BatchCallContext implements the BatchTransport interface, the batch fails over only when the
+// whole batch fails
+func (m *Multi) BatchCallContext(ctx context.Context, elems []BatchElem) error {
+	return m.do(ctx, func(ctx context.Context, t Transport) error {
+		if bt, ok := t.(BatchTransport); ok {
+			return bt.BatchCallContext(ctx, elems)
+		}
+		for i := range elems {
+			elems[i].Error = t.CallContext(ctx, elems[i].Method, elems[i].Result, elems[i].Params...)
+		}
+		return nil
+	})
+}
+
+// Subscribe implements the PubSubTransport interface, the subscription is made on the first
+// available endpoint supporting subscriptions and is not failed over to the other endpoints
+func (m *Multi) Subscribe(method string, param interface{}, callback func(b []byte)) (func() error, error) {
+	for _, e := range m.candidates() {
+		e.lock.RLock()
+		t := e.transport
+		e.lock.RUnlock()
+		if pub, ok := t.(PubSubTransport); ok {
+			return pub.Subscribe(method, param, callback)
+		}
+	}
+	return nil, fmt.Errorf("no endpoint supports subscriptions")
+}
+
+// Close implements the transport interface
+func (m *Multi) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+	})
+	m.setState(StateClosed)
+	var lastErr error
+	for _, e := range m.endpoints {
+		e.lock.RLock()
+		t := e.transport
+		e.lock.RUnlock()
+		if t != nil {
+			if err := t.Close(); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+func (m *Multi) healthCheckLoop() {
+	ticker := time.NewTicker(m.config.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.closeCh:
+			return
+		case <-ticker.C:
+			m.HealthCheck()
+		}
+	}
+}
+
+// HealthCheck checks the endpoints with eth_blockNumber and dials the endpoints which are not
+// connected
+func (m *Multi) HealthCheck() {
+	timeout := m.config.AttemptTimeout
+	if timeout <= 0 {
+		timeout = callTimeout
+	}
+	var wg sync.WaitGroup
+	for _, e := range m.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			m.checkEndpoint(ctx, e)
+		}(e)
+	}
+	wg.Wait()
+}
+
+func (m *Multi) checkEndpoint(ctx context.Context, e *endpoint) {
+	defer m.updateState()
+	e.lock.RLock()
+	t := e.transport
+	e.lock.RUnlock()
+	if t == nil {
+		var err error
+		if t, err = NewTransportContext(ctx, e.url); err != nil {
+			e.record(0, err)
+			m.notifyErr(fmt.Errorf("%s: %v", e.url, err))
+			return
+		}
+		e.lock.Lock()
+		e.transport = t
+		e.lock.Unlock()
+		m.watch(e, t)
+	}
+
+	var out string
+	start := time.Now()
+	err := t.CallContext(ctx, "eth_blockNumber", &out)
+	var number uint64
+	if err == nil {
+		_, err = fmt.Sscanf(out, "0x%x", &number)
+		if err != nil {
+			err = fmt.Errorf("invalid block number %q", out)
+		}
+	}
+	if err != nil && !IsTransientError(err) {
+		// a node which can not answer eth_blockNumber is not usable
+		e.lock.Lock()
+		e.healthy, e.lastErr = false, err
+		e.lock.Unlock()
+		return
+	}
+	e.record(time.Since(start), err)
+	if err == nil {
+		e.lock.Lock()
+		e.blockNumber = number
+		e.lock.Unlock()
+	}
+}
@@ -0,0 +1,249 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ConnState is the state of the connection of a stream transport
+type ConnState int
+
+const (
+	// StateConnected is the state of an open connection
+	StateConnected ConnState = iota
+	// StateReconnecting is the state after the connection is lost, until it is re-established
+	StateReconnecting
+	// StateClosed is the state after the transport is closed or the connection is lost without
+	// reconnection
+	StateClosed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	}
+	return fmt.Sprintf("ConnState(%d)", int(s))
+}
+
+// ReconnectTransport is a transport which reconnects with backoff after the connection is lost and
+// re-establishes the active subscriptions with the same callbacks
+type ReconnectTransport interface {
+	// State returns the current state of the connection
+	State() ConnState
+
+	// StateCh returns the channel notified of the state changes, the changes are dropped if
+	// the channel is full
+	StateCh() <-chan ConnState
+
+	// ErrCh returns the channel notified of the connection errors, including the failed
+	// reconnection attempts, the errors are dropped if the channel is full
+	ErrCh() <-chan error
+}
+
+// ErrConnectionLost happens when the connection is lost while a request is pending
+var ErrConnectionLost = fmt.Errorf("connection lost")
+
+const (
+	notifyBufferSize    = 16
+	minReconnectBackoff = 100 * time.Millisecond
+	maxReconnectBackoff = 30 * time.Second
+)
+
+type subscription struct {
+	id       string // id of the subscription on the current connection
+	method   string
+	param    interface{}
+	callback func(b []byte)
+}
+
+func (s *subscription) params() []interface{} {
+	params := []interface{}{s.method}
+	if s.param != nil {
+		params = append(params, s.param)
+	}
+	return params
+}
+
+// State implements the ReconnectTransport interface
+func (s *stream) State() ConnState {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return s.state
+}
+
+// StateCh implements the ReconnectTransport interface
+func (s *stream) StateCh() <-chan ConnState {
+	return s.stateCh
+}
+
+// ErrCh implements the ReconnectTransport interface
+func (s *stream) ErrCh() <-chan error {
+	return s.errCh
+}
+
+func (s *stream) setState(state ConnState) {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	if s.state == state || s.state == StateClosed {
+		return
+	}
+	s.state = state
+	select {
+	case s.stateCh <- state:
+	default:
+	}
+}
+
+func (s *stream) notifyErr(err error) {
+	select {
+	case s.errCh <- err:
+	default:
+	}
+}
+
+// run reads the connection and reconnects when the connection is lost until the stream is closed
+func (s *stream) run(conn Codec) {
+	for {
+		err := s.listen(conn)
+		if s.isClosed() {
+			s.failPending(ErrClosed)
+			return
+		}
+		s.failPending(ErrConnectionLost)
+		s.notifyErr(err)
+		if s.dial == nil {
+			s.setState(StateClosed)
+			return
+		}
+		conn = s.reconnect()
+		if conn == nil {
+			return
+		}
+		go s.resubscribe(conn)
+	}
+}
+
+// failPending fails the requests waiting for a response of the lost connection
+func (s *stream) failPending(err error) {
+	s.handlerLock.Lock()
+	handlers := s.handler
+	s.handler = map[uint64]callback{}
+	s.handlerLock.Unlock()
+
+	for _, callback := range handlers {
+		callback(nil, err)
+	}
+}
+
+// reconnect dials with exponential backoff until a connection is opened, nil if the stream is
+// closed meanwhile
+func (s *stream) reconnect() Codec {
+	s.setState(StateReconnecting)
+	backoff := minReconnectBackoff
+	for {
+		select {
+		case <-s.closeCh:
+			return nil
+		case <-time.After(backoff):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+		conn, err := s.dial(ctx)
+		cancel()
+		if err != nil {
+			s.notifyErr(fmt.Errorf("reconnect: %v", err))
+			if backoff *= 2; backoff > maxReconnectBackoff {
+				backoff = maxReconnectBackoff
+			}
+			continue
+		}
+
+		s.codecLock.Lock()
+		if s.isClosed() {
+			s.codecLock.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.codec = conn
+		s.codecLock.Unlock()
+		s.setState(StateConnected)
+		return conn
+	}
+}
+
+// resubscribe re-establishes the active subscriptions on the new connection, the subscriptions
+// which fail are retried with backoff until the connection is lost again, the next reconnection
+// then takes over
+func (s *stream) resubscribe(conn Codec) {
+	s.subsLock.Lock()
+	subs := make([]*subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	s.subsLock.Unlock()
+
+	backoff := minReconnectBackoff
+	for {
+		var failed []*subscription
+		for _, sub := range subs {
+			if err := s.resubscribeOne(sub); err != nil {
+				s.notifyErr(fmt.Errorf("resubscribe %s: %v", sub.method, err))
+				failed = append(failed, sub)
+			}
+		}
+		if len(failed) == 0 {
+			return
+		}
+
+		select {
+		case <-s.closeCh:
+			return
+		case <-time.After(backoff):
+		}
+		if s.getCodec() != conn {
+			return
+		}
+		if backoff *= 2; backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+		subs = failed
+	}
+}
+
+// resubscribeOne re-establishes a subscription under a new id, the subscriptions cancelled
+// meanwhile are skipped
+func (s *stream) resubscribeOne(sub *subscription) error {
+	s.subsLock.Lock()
+	active := s.subs[sub.id] == sub
+	s.subsLock.Unlock()
+	if !active {
+		return nil
+	}
+
+	var id string
+	if err := s.Call("eth_subscribe", &id, sub.params()...); err != nil {
+		return err
+	}
+
+	s.subsLock.Lock()
+	active = s.subs[sub.id] == sub
+	if active {
+		delete(s.subs, sub.id)
+		sub.id = id
+		s.subs[id] = sub
+	}
+	s.subsLock.Unlock()
+
+	if !active {
+		// cancelled while resubscribing
+		var result bool
+		s.Call("eth_unsubscribe", &result, id)
+	}
+	return nil
+}
@@ -1,8 +1,14 @@
 package transport
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"strings"
+
+	"github.com/laizy/web3/jsonrpc/codec"
 )
 
 // Transport is an inteface for transport methods to send jsonrpc requests
@@ -10,6 +16,9 @@ type Transport interface {
 	// Call makes a jsonrpc request
 	Call(method string, out interface{}, params ...interface{}) error
 
+	// CallContext makes a jsonrpc request which is aborted once the context is done
+	CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error
+
 	// Close closes the transport connection if necessary
 	Close() error
 }
@@ -20,6 +29,74 @@ type PubSubTransport interface {
 	Subscribe(method string, param interface{}, callback func(b []byte)) (func() error, error)
 }
 
+// BatchTransport is a transport that can send several jsonrpc requests in a single round trip
+type BatchTransport interface {
+	// BatchCall makes a batch of jsonrpc requests, the error of each request is set on its
+	// element while the returned error reports a failure of the whole batch
+	BatchCall(elems []BatchElem) error
+
+	// BatchCallContext makes a batch of jsonrpc requests which is aborted once the context is done
+	BatchCallContext(ctx context.Context, elems []BatchElem) error
+}
+
+// BatchElem is a single request in a batch call
+type BatchElem struct {
+	Method string
+	Params []interface{}
+	// Result is the value the response is decoded into, as the out value of Call
+	Result interface{}
+	// Error is set if the request failed or its result could not be decoded
+	Error error
+}
+
+func newRequest(id uint64, method string, params []interface{}) (codec.Request, error) {
+	request := codec.Request{
+		JsonRpc: "2.0",
+		ID:      id,
+		Method:  method,
+	}
+	if len(params) > 0 {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return request, err
+		}
+		request.Params = data
+	}
+	return request, nil
+}
+
+// setBatchResult decodes the response of a batch element
+func setBatchResult(elem *BatchElem, resp *codec.Response) {
+	if resp == nil {
+		elem.Error = fmt.Errorf("missing response for batch request %s", elem.Method)
+		return
+	}
+	if resp.Error != nil {
+		elem.Error = resp.Error
+		return
+	}
+	elem.Error = json.Unmarshal(resp.Result, elem.Result)
+}
+
+// IsTransientError reports whether the request failed because of the connection or the load of the
+// node, so it may succeed if retried or sent to another node. Errors returned by the node itself,
+// except the rate limit ones, and the cancellation of the context are not transient.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var rpcErr *codec.ErrorObject
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == limitExceededCode
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr)
+}
+
+// limitExceededCode is the jsonrpc error code of the request rate limit (EIP-1474)
+const limitExceededCode = -32005
+
 const (
 	wsPrefix  = "ws://"
 	wssPrefix = "wss://"
@@ -27,12 +104,18 @@ const (
 
 // NewTransport creates a new transport object
 func NewTransport(url string) (Transport, error) {
+	return NewTransportContext(context.Background(), url)
+}
+
+// NewTransportContext creates a new transport object, the context bounds the dial of the
+// websocket and ipc connections
+func NewTransportContext(ctx context.Context, url string) (Transport, error) {
 	if strings.HasPrefix(url, wsPrefix) || strings.HasPrefix(url, wssPrefix) {
-		return newWebsocket(url)
+		return newWebsocket(ctx, url)
 	}
 	if _, err := os.Stat(url); err == nil {
 		// path exists, it could be an ipc path
-		return newIPC(url)
+		return newIPC(ctx, url)
 	}
 	return newHTTP(url), nil
 }
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,21 +13,32 @@ import (
 	"github.com/laizy/web3/jsonrpc/codec"
 )
 
-func newWebsocket(url string) (Transport, error) {
-	wsConn, _, err := websocket.DefaultDialer.Dial(url, http.Header{})
+func newWebsocket(ctx context.Context, url string) (Transport, error) {
+	dial := func(ctx context.Context) (Codec, error) {
+		wsConn, _, err := websocket.DefaultDialer.DialContext(ctx, url, http.Header{})
+		if err != nil {
+			return nil, err
+		}
+		keepAlive(wsConn, time.Second*10)
+		return &websocketCodec{conn: wsConn}, nil
+	}
+	codec, err := dial(ctx)
 	if err != nil {
 		return nil, err
 	}
-	codec := &websocketCodec{
-		conn: wsConn,
-	}
-	keepAlive(wsConn, time.Second*10)
-	return newStream(codec)
+	return newStream(codec, dial)
 }
 
 // ErrTimeout happens when the websocket requests times out
 var ErrTimeout = fmt.Errorf("timeout")
 
+// ErrClosed happens when the transport is closed while a request is pending
+var ErrClosed = fmt.Errorf("transport closed")
+
+// callTimeout bounds the requests made without a context, the requests made with a context
+// are only bounded by the context
+const callTimeout = 10 * time.Second
+
 type ackMessage struct {
 	buf []byte
 	err error
@@ -35,30 +47,42 @@ type ackMessage struct {
 type callback func(b []byte, err error)
 
 type stream struct {
-	seq   uint64
-	codec Codec
+	seq uint64
+
+	codecLock sync.RWMutex
+	codec     Codec
+	// dial opens a new connection after the connection is lost, nil if the stream does not reconnect
+	dial func(ctx context.Context) (Codec, error)
 
 	// call handlers
 	handlerLock sync.Mutex
 	handler     map[uint64]callback
 
-	// subscriptions
+	// subscriptions by id
 	subsLock sync.Mutex
-	subs     map[string]func(b []byte)
+	subs     map[string]*subscription
+
+	// connection state
+	stateLock sync.Mutex
+	state     ConnState
+	stateCh   chan ConnState
+	errCh     chan error
 
 	closeCh chan struct{}
-	timer   *time.Timer
 }
 
-func newStream(codec Codec) (*stream, error) {
+func newStream(codec Codec, dial func(ctx context.Context) (Codec, error)) (*stream, error) {
 	w := &stream{
 		codec:   codec,
+		dial:    dial,
 		closeCh: make(chan struct{}),
 		handler: map[uint64]callback{},
-		subs:    map[string]func(b []byte){},
+		subs:    map[string]*subscription{},
+		stateCh: make(chan ConnState, notifyBufferSize),
+		errCh:   make(chan error, notifyBufferSize),
 	}
 
-	go w.listen()
+	go w.run(codec)
 	return w, nil
 }
 
@@ -91,7 +115,14 @@ func keepAlive(c *websocket.Conn, timeout time.Duration) {
 // Close implements the the transport interface
 func (s *stream) Close() error {
 	close(s.closeCh)
-	return s.codec.Close()
+	s.setState(StateClosed)
+	return s.getCodec().Close()
+}
+
+func (s *stream) getCodec() Codec {
+	s.codecLock.RLock()
+	defer s.codecLock.RUnlock()
+	return s.codec
 }
 
 func (s *stream) incSeq() uint64 {
@@ -107,22 +138,32 @@ func (s *stream) isClosed() bool {
 	}
 }
 
-func (s *stream) listen() {
+// listen dispatches the messages read from the connection until the connection fails
+func (s *stream) listen(conn Codec) error {
 	buf := []byte{}
 
 	for {
 		var err error
-		buf, err = s.codec.Read(buf[:0])
+		buf, err = conn.Read(buf[:0])
 		if err != nil {
-			if !s.isClosed() {
-				// log error
+			return err
+		}
+
+		if len(buf) != 0 && buf[0] == '[' {
+			// response to a batch request
+			var resps []codec.Response
+			if err = json.Unmarshal(buf, &resps); err != nil {
+				continue
 			}
-			return
+			for _, resp := range resps {
+				go s.handleMsg(resp)
+			}
+			continue
 		}
 
 		var resp codec.Response
 		if err = json.Unmarshal(buf, &resp); err != nil {
-			return
+			continue
 		}
 
 		if resp.ID != 0 {
@@ -131,7 +172,7 @@ func (s *stream) listen() {
 			// handle subscription
 			var respSub codec.Request
 			if err = json.Unmarshal(buf, &respSub); err != nil {
-				return
+				continue
 			}
 
 			if respSub.Method == "eth_subscription" {
@@ -148,7 +189,7 @@ func (s *stream) handleSubscription(response codec.Request) {
 	}
 
 	s.subsLock.Lock()
-	callback, ok := s.subs[sub.ID]
+	subscription, ok := s.subs[sub.ID]
 	s.subsLock.Unlock()
 
 	if !ok {
@@ -156,7 +197,7 @@ func (s *stream) handleSubscription(response codec.Request) {
 	}
 
 	// call the callback function
-	callback(sub.Result)
+	subscription.callback(sub.Result)
 }
 
 func (s *stream) handleMsg(response codec.Response) {
@@ -189,47 +230,67 @@ func (s *stream) setHandler(id uint64, ack chan *ackMessage) {
 	s.handlerLock.Lock()
 	s.handler[id] = callback
 	s.handlerLock.Unlock()
+}
 
-	s.timer = time.AfterFunc(10*time.Second, func() {
-		s.handlerLock.Lock()
-		delete(s.handler, id)
-		s.handlerLock.Unlock()
+func (s *stream) removeHandler(id uint64) {
+	s.handlerLock.Lock()
+	delete(s.handler, id)
+	s.handlerLock.Unlock()
+}
 
-		select {
-		case ack <- &ackMessage{nil, ErrTimeout}:
-		default:
-		}
-	})
+// wait waits for the response until the context is done, a response which is already received
+// is returned even if the context is done
+func (s *stream) wait(ctx context.Context, ack chan *ackMessage) (*ackMessage, error) {
+	select {
+	case resp := <-ack:
+		return resp, nil
+	default:
+	}
+	select {
+	case resp := <-ack:
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.closeCh:
+		return nil, ErrClosed
+	}
 }
 
-// Call implements the transport interface
+// Call implements the transport interface, the call fails with ErrTimeout if there is no
+// response after callTimeout
 func (s *stream) Call(method string, out interface{}, params ...interface{}) error {
-	seq := s.incSeq()
-	request := codec.Request{
-		JsonRpc: "2.0",
-		ID:      seq,
-		Method:  method,
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	err := s.CallContext(ctx, method, out, params...)
+	if err == context.DeadlineExceeded {
+		return ErrTimeout
 	}
-	if len(params) > 0 {
-		data, err := json.Marshal(params)
-		if err != nil {
-			return err
-		}
-		request.Params = data
+	return err
+}
+
+// CallContext implements the transport interface
+func (s *stream) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	request, err := newRequest(s.incSeq(), method, params)
+	if err != nil {
+		return err
 	}
 
-	ack := make(chan *ackMessage)
-	s.setHandler(seq, ack)
+	ack := make(chan *ackMessage, 1)
+	s.setHandler(request.ID, ack)
+	defer s.removeHandler(request.ID)
 
 	raw, err := json.Marshal(request)
 	if err != nil {
 		return err
 	}
-	if err := s.codec.Write(raw); err != nil {
+	if err := s.getCodec().Write(raw); err != nil {
 		return err
 	}
 
-	resp := <-ack
+	resp, err := s.wait(ctx, ack)
+	if err != nil {
+		return err
+	}
 	if resp.err != nil {
 		return resp.err
 	}
@@ -239,52 +300,107 @@ func (s *stream) Call(method string, out interface{}, params ...interface{}) err
 	return nil
 }
 
-func (s *stream) unsubscribe(id string) error {
-	s.subsLock.Lock()
-	defer s.subsLock.Unlock()
+// BatchCall implements the BatchTransport interface, the calls without a response after
+// callTimeout fail with ErrTimeout
+func (s *stream) BatchCall(elems []BatchElem) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	return s.batchCall(ctx, elems, true)
+}
 
-	if _, ok := s.subs[id]; !ok {
-		return fmt.Errorf("subscription %s not found", id)
+// BatchCallContext implements the BatchTransport interface
+func (s *stream) BatchCallContext(ctx context.Context, elems []BatchElem) error {
+	return s.batchCall(ctx, elems, false)
+}
+
+// batchCall sends the batch and waits for the responses until the context is done. When the
+// deadline of the context is reached, the calls without a response fail with ErrTimeout if
+// timeoutElems is set, otherwise the whole batch fails.
+func (s *stream) batchCall(ctx context.Context, elems []BatchElem, timeoutElems bool) error {
+	if len(elems) == 0 {
+		return nil
+	}
+	requests := make([]codec.Request, len(elems))
+	acks := make([]chan *ackMessage, len(elems))
+	for i, elem := range elems {
+		request, err := newRequest(s.incSeq(), elem.Method, elem.Params)
+		if err != nil {
+			return err
+		}
+		requests[i] = request
+		acks[i] = make(chan *ackMessage, 1)
+		s.setHandler(request.ID, acks[i])
+		defer s.removeHandler(request.ID)
 	}
-	delete(s.subs, id)
 
-	var result bool
-	if err := s.Call("eth_unsubscribe", &result, id); err != nil {
+	raw, err := json.Marshal(requests)
+	if err != nil {
 		return err
 	}
-	if !result {
-		return fmt.Errorf("failed to unsubscribe")
+	if err := s.getCodec().Write(raw); err != nil {
+		return err
+	}
+
+	for i, ack := range acks {
+		resp, err := s.wait(ctx, ack)
+		if err == context.DeadlineExceeded && timeoutElems {
+			elems[i].Error = ErrTimeout
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if resp.err != nil {
+			elems[i].Error = resp.err
+			continue
+		}
+		elems[i].Error = json.Unmarshal(resp.buf, elems[i].Result)
 	}
 	return nil
 }
 
-func (s *stream) setSubscription(id string, callback func(b []byte)) {
+func (s *stream) unsubscribe(sub *subscription) error {
 	s.subsLock.Lock()
-	defer s.subsLock.Unlock()
+	if s.subs[sub.id] != sub {
+		s.subsLock.Unlock()
+		return fmt.Errorf("subscription %s not found", sub.id)
+	}
+	delete(s.subs, sub.id)
+	s.subsLock.Unlock()
 
-	s.subs[id] = callback
+	var result bool
+	if err := s.Call("eth_unsubscribe", &result, sub.id); err != nil {
+		return err
+	}
+	if !result {
+		return fmt.Errorf("failed to unsubscribe")
+	}
+	return nil
 }
 
 // Subscribe implements the PubSubTransport interface
 func (s *stream) Subscribe(method string, param interface{}, callback func(b []byte)) (func() error, error) {
-	callParam := []interface{}{method}
-	if param != nil {
-		callParam = append(callParam, param)
-	}
+	sub := &subscription{method: method, param: param, callback: callback}
 	var out string
-	if err := s.Call("eth_subscribe", &out, callParam...); err != nil {
+	if err := s.Call("eth_subscribe", &out, sub.params()...); err != nil {
 		return nil, err
 	}
+	sub.id = out
+
+	s.subsLock.Lock()
+	s.subs[sub.id] = sub
+	s.subsLock.Unlock()
 
-	s.setSubscription(out, callback)
 	cancel := func() error {
-		return s.unsubscribe(out)
+		return s.unsubscribe(sub)
 	}
 	return cancel, nil
 }
 
 type websocketCodec struct {
 	conn *websocket.Conn
+	// the connection supports a single concurrent writer
+	lock sync.Mutex
 }
 
 func (w *websocketCodec) Close() error {
@@ -292,6 +408,8 @@ func (w *websocketCodec) Close() error {
 }
 
 func (w *websocketCodec) Write(b []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
 	return w.conn.WriteMessage(websocket.TextMessage, b)
 }
 
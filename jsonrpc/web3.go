@@ -1,5 +1,7 @@
 package jsonrpc
 
+import "context"
+
 // Web3 is the web3 namespace
 type Web3 struct {
 	c *Client
@@ -12,15 +14,25 @@ func (c *Client) Web3() *Web3 {
 
 // ClientVersion returns the current client version
 func (w *Web3) ClientVersion() (string, error) {
+	return w.ClientVersionContext(context.Background())
+}
+
+// ClientVersionContext is like ClientVersion with a context
+func (w *Web3) ClientVersionContext(ctx context.Context) (string, error) {
 	var out string
-	err := w.c.Call("web3_clientVersion", &out)
+	err := w.c.CallContext(ctx, "web3_clientVersion", &out)
 	return out, err
 }
 
 // Sha3 returns Keccak-256 (not the standardized SHA3-256) of the given data
 func (w *Web3) Sha3(val []byte) ([]byte, error) {
+	return w.Sha3Context(context.Background(), val)
+}
+
+// Sha3Context is like Sha3 with a context
+func (w *Web3) Sha3Context(ctx context.Context, val []byte) ([]byte, error) {
 	var out string
-	if err := w.c.Call("web3_sha3", &out, encodeToHex(val)); err != nil {
+	if err := w.c.CallContext(ctx, "web3_sha3", &out, encodeToHex(val)); err != nil {
 		return nil, err
 	}
 	return parseHexBytes(out)
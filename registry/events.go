@@ -13,6 +13,8 @@ import (
 
 type EventRegistry struct {
 	events        map[web3.Hash]*abi.Event
+	methods       map[[4]byte]*abi.Method
+	errors        map[[4]byte]*abi.Error
 	contractNames map[web3.Address]string
 	lock          sync.RWMutex
 }
@@ -43,10 +45,48 @@ func (self *EventRegistry) Register(e *abi.Event) {
 	self.events[e.ID()] = e
 }
 
+// RegisterMethod registers the method so its selector can be resolved, the method registered
+// first is kept if the selectors collide.
+func (self *EventRegistry) RegisterMethod(m *abi.Method) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if len(self.methods) == 0 {
+		self.methods = map[[4]byte]*abi.Method{}
+	}
+	var id [4]byte
+	copy(id[:], m.ID())
+	if self.methods[id] != nil {
+		return
+	}
+	self.methods[id] = m
+}
+
+// RegisterError registers the custom error so reverts with its selector can be decoded, the
+// error registered first is kept if the selectors collide.
+func (self *EventRegistry) RegisterError(e *abi.Error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if len(self.errors) == 0 {
+		self.errors = map[[4]byte]*abi.Error{}
+	}
+	var id [4]byte
+	copy(id[:], e.ID())
+	if self.errors[id] != nil {
+		return
+	}
+	self.errors[id] = e
+}
+
 func (self *EventRegistry) RegisterFromAbi(abi *abi.ABI) {
 	for _, e := range abi.Events {
 		self.Register(e)
 	}
+	for _, m := range abi.MethodsBySig {
+		self.RegisterMethod(m)
+	}
+	for _, e := range abi.Errors {
+		self.RegisterError(e)
+	}
 }
 
 func (self *EventRegistry) RegisterFromHumanString(eventStr string) {
@@ -54,6 +94,14 @@ func (self *EventRegistry) RegisterFromHumanString(eventStr string) {
 	self.Register(e)
 }
 
+func (self *EventRegistry) RegisterMethodFromHumanString(methodStr string) {
+	self.RegisterMethod(abi.MustNewMethod(methodStr))
+}
+
+func (self *EventRegistry) RegisterErrorFromHumanString(errorStr string) {
+	self.RegisterError(abi.MustNewError(errorStr))
+}
+
 func (self *EventRegistry) ParseLog(log *web3.Log) (*web3.ParsedEvent, error) {
 	if len(log.Topics) == 0 {
 		return nil, errors.New("no topic found")
@@ -85,6 +133,54 @@ func (self *EventRegistry) GetEvent(id web3.Hash) *abi.Event {
 	return self.events[id]
 }
 
+func (self *EventRegistry) GetMethod(id [4]byte) *abi.Method {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+
+	return self.methods[id]
+}
+
+func (self *EventRegistry) GetError(id [4]byte) *abi.Error {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+
+	return self.errors[id]
+}
+
+// DecodeRevert formats the revert data of the registered custom errors as `InsufficientBalance(10, 20)`
+func (self *EventRegistry) DecodeRevert(data []byte) (string, bool) {
+	if len(data) < 4 {
+		return "", false
+	}
+	var id [4]byte
+	copy(id[:], data)
+	e := self.GetError(id)
+	if e == nil {
+		return "", false
+	}
+	msg, err := e.Format(data)
+	if err != nil {
+		return "", false
+	}
+	return msg, true
+}
+
+// MethodSig returns the signature of the registered method with the selector, empty if unknown
+func (self *EventRegistry) MethodSig(selector [4]byte) string {
+	if m := self.GetMethod(selector); m != nil {
+		return m.Sig()
+	}
+	return ""
+}
+
+// ContractName returns the alias of the contract, empty if unknown
+func (self *EventRegistry) ContractName(addr web3.Address) string {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+
+	return self.contractNames[addr]
+}
+
 func (self *EventRegistry) DumpLog(log *web3.Log) string {
 	decoded, err := self.ParseLog(log)
 	if err != nil {
@@ -11,4 +11,5 @@ func Instance() *EventRegistry {
 func init() {
 	eventRegistry.RegisterPresetMainnet()
 	web3.RegisterParser(eventRegistry)
+	web3.RegisterRevertDecoder(eventRegistry)
 }
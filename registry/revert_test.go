@@ -0,0 +1,32 @@
+package registry
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/abi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventRegistry_DecodeRevert(t *testing.T) {
+	e := abi.MustNewError("error InsufficientBalance(uint256 available, uint256 required)")
+	args, err := e.Inputs.Encode([]interface{}{big.NewInt(10), big.NewInt(20)})
+	assert.NoError(t, err)
+	data := append(e.ID(), args...)
+
+	registry := NewEventRegistry()
+	prev := web3.GetRevertDecoder()
+	web3.RegisterRevertDecoder(registry)
+	defer web3.RegisterRevertDecoder(prev)
+
+	_, ok := registry.DecodeRevert(data)
+	assert.False(t, ok)
+
+	registry.RegisterFromAbi(&abi.ABI{Errors: map[string]*abi.Error{e.Name: e}})
+	reason, ok := web3.DecodeRevert(data)
+	assert.True(t, ok)
+	assert.Equal(t, "InsufficientBalance(10, 20)", reason)
+	_, ok = Instance().DecodeRevert(data)
+	assert.False(t, ok)
+}
@@ -0,0 +1,54 @@
+package web3
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RevertDecoder decodes the revert data of custom errors, registry.EventRegistry implements it.
+type RevertDecoder interface {
+	DecodeRevert(data []byte) (string, bool)
+}
+
+type NilRevertDecoder struct{}
+
+func (self *NilRevertDecoder) DecodeRevert(data []byte) (string, bool) {
+	return "", false
+}
+
+var revertDecoder RevertDecoder = &NilRevertDecoder{}
+
+func RegisterRevertDecoder(d RevertDecoder) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	revertDecoder = d
+}
+
+func GetRevertDecoder() RevertDecoder {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return revertDecoder
+}
+
+// panic codes of solidity 0.8, https://docs.soliditylang.org/en/latest/control-structures.html#panic-via-assert-and-error-via-require
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "out-of-bounds array access; popping on an empty array",
+	0x32: "out-of-bounds access of an array or bytesN",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
+
+func decodePanic(code *big.Int) string {
+	if code.IsUint64() {
+		if reason, ok := panicReasons[code.Uint64()]; ok {
+			return fmt.Sprintf("Panic(0x%02x): %s", code.Uint64(), reason)
+		}
+	}
+	return fmt.Sprintf("Panic(0x%x)", code)
+}
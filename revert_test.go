@@ -0,0 +1,32 @@
+package web3
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/laizy/web3/evm/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRevert(t *testing.T) {
+	overflow, _ := hex.DecodeString("4e487b71" + "0000000000000000000000000000000000000000000000000000000000000011")
+	reason, ok := DecodeRevert(overflow)
+	assert.True(t, ok)
+	assert.Equal(t, "Panic(0x11): arithmetic underflow or overflow", reason)
+
+	unknown, _ := hex.DecodeString("4e487b71" + "00000000000000000000000000000000000000000000000000000000000000ff")
+	reason, ok = DecodeRevert(unknown)
+	assert.True(t, ok)
+	assert.Equal(t, "Panic(0xff)", reason)
+
+	_, ok = DecodeRevert([]byte{1, 2, 3, 4})
+	assert.False(t, ok)
+	_, ok = DecodeRevert([]byte{0x08, 0xc3, 0x79, 0xa0})
+	assert.False(t, ok)
+
+	result := NewExecutionResult(100, errors.ErrExecutionReverted, overflow)
+	assert.Equal(t, "Panic(0x11): arithmetic underflow or overflow", result.RevertReason)
+	reason, ok = result.DecodeRevert()
+	assert.True(t, ok)
+	assert.Equal(t, result.RevertReason, reason)
+}
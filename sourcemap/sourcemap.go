@@ -0,0 +1,156 @@
+package sourcemap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Source is a source file given to the compiler
+type Source struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Location is the source range of an instruction, as encoded in the solc source map
+type Location struct {
+	Offset int
+	Length int
+	File   int // index of the source file, -1 if the instruction has no source
+	Jump   byte
+}
+
+// Decode decodes a solc source map, which holds a location for every instruction of the code.
+// Empty fields inherit the value of the previous instruction.
+func Decode(srcmap string) ([]Location, error) {
+	if srcmap == "" {
+		return nil, nil
+	}
+	entries := strings.Split(srcmap, ";")
+	locations := make([]Location, 0, len(entries))
+	prev := Location{File: -1, Jump: '-'}
+	for i, entry := range entries {
+		loc := prev
+		for j, field := range strings.Split(entry, ":") {
+			if field == "" {
+				continue
+			}
+			if j == 3 {
+				loc.Jump = field[0]
+				continue
+			}
+			if j > 3 {
+				// the modifier depth is not used
+				continue
+			}
+			val, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid source map entry %d: %q", i, entry)
+			}
+			switch j {
+			case 0:
+				loc.Offset = val
+			case 1:
+				loc.Length = val
+			case 2:
+				loc.File = val
+			}
+		}
+		locations = append(locations, loc)
+		prev = loc
+	}
+	return locations, nil
+}
+
+const (
+	push1  = 0x60
+	push32 = 0x7f
+)
+
+// Position is a resolved position in a source file
+type Position struct {
+	File    string
+	Line    int // 1-based
+	Column  int // 1-based, in bytes
+	Snippet string
+}
+
+func (self *Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", self.File, self.Line, self.Column)
+}
+
+// Contract is the code of a contract with its source map
+type Contract struct {
+	Name      string
+	Code      []byte
+	locations []Location
+	sources   []Source
+	// instruction index of every pc, -1 for push data
+	instructions []int
+}
+
+// NewContract creates a contract from the code, the source map of the code and the source files
+// indexed by the file index of the source map.
+func NewContract(name string, code []byte, srcmap string, sources []Source) (*Contract, error) {
+	locations, err := Decode(srcmap)
+	if err != nil {
+		return nil, err
+	}
+	instructions := make([]int, len(code))
+	index := 0
+	for pc := 0; pc < len(code); pc++ {
+		instructions[pc] = index
+		index++
+		if op := code[pc]; op >= push1 && op <= push32 {
+			size := int(op - push1 + 1)
+			for i := 1; i <= size && pc+i < len(code); i++ {
+				instructions[pc+i] = -1
+			}
+			pc += size
+		}
+	}
+	return &Contract{
+		Name:         name,
+		Code:         code,
+		locations:    locations,
+		sources:      sources,
+		instructions: instructions,
+	}, nil
+}
+
+// Location returns the source location of the instruction at pc
+func (self *Contract) Location(pc uint64) (Location, bool) {
+	if pc >= uint64(len(self.instructions)) || self.instructions[pc] < 0 {
+		return Location{}, false
+	}
+	index := self.instructions[pc]
+	if index >= len(self.locations) {
+		return Location{}, false
+	}
+	return self.locations[index], true
+}
+
+// Position resolves the instruction at pc to the position in the source file, false if the
+// instruction is not mapped to a source range of a known source file.
+func (self *Contract) Position(pc uint64) (*Position, bool) {
+	loc, ok := self.Location(pc)
+	if !ok || loc.File < 0 || loc.File >= len(self.sources) {
+		return nil, false
+	}
+	source := self.sources[loc.File]
+	if loc.Offset < 0 || loc.Length < 0 || loc.Offset > len(source.Content) {
+		return nil, false
+	}
+	before := source.Content[:loc.Offset]
+	line := strings.Count(before, "\n") + 1
+	column := loc.Offset - (strings.LastIndex(before, "\n") + 1) + 1
+	end := loc.Offset + loc.Length
+	if end > len(source.Content) {
+		end = len(source.Content)
+	}
+	snippet := source.Content[loc.Offset:end]
+	if i := strings.IndexByte(snippet, '\n'); i >= 0 {
+		snippet = snippet[:i]
+	}
+	return &Position{File: source.Name, Line: line, Column: column, Snippet: snippet}, true
+}
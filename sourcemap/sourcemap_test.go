@@ -0,0 +1,73 @@
+package sourcemap
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	locations, err := Decode("1:2:1;:9;2:1:2;;-1:-1:-1:o;5::0:i:1")
+	assert.Nil(t, err)
+	assert.Equal(t, []Location{
+		{Offset: 1, Length: 2, File: 1, Jump: '-'},
+		{Offset: 1, Length: 9, File: 1, Jump: '-'},
+		{Offset: 2, Length: 1, File: 2, Jump: '-'},
+		{Offset: 2, Length: 1, File: 2, Jump: '-'},
+		{Offset: -1, Length: -1, File: -1, Jump: 'o'},
+		{Offset: 5, Length: -1, File: 0, Jump: 'i'},
+	}, locations)
+
+	_, err = Decode("1:x:1")
+	assert.NotNil(t, err)
+}
+
+const testSource = `contract A {
+    function f() public {
+        b.g();
+    }
+}
+contract B {
+    function g() public {
+        require(false, "no");
+    }
+}
+`
+
+func newTestContract(t *testing.T, name, code string, snippets ...string) *Contract {
+	var srcmap []string
+	for _, snippet := range snippets {
+		offset := strings.Index(testSource, snippet)
+		srcmap = append(srcmap, strings.Join([]string{strconv.Itoa(offset), strconv.Itoa(len(snippet)), "0"}, ":"))
+	}
+	raw, _ := hex.DecodeString(code)
+	c, err := NewContract(name, raw, strings.Join(srcmap, ";"), []Source{{Name: "test.sol", Content: testSource}})
+	assert.Nil(t, err)
+	return c
+}
+
+func TestContractPosition(t *testing.T) {
+	// PUSH2 0x0000, CALL, STOP
+	a := newTestContract(t, "A", "610000f100", "contract A", "b.g()", "}")
+	_, ok := a.Position(1)
+	assert.False(t, ok, "push data has no position")
+	pos, ok := a.Position(3)
+	assert.True(t, ok)
+	assert.Equal(t, &Position{File: "test.sol", Line: 3, Column: 9, Snippet: "b.g()"}, pos)
+	assert.Equal(t, "test.sol:3:9", pos.String())
+	_, ok = a.Position(5)
+	assert.False(t, ok)
+
+	// compiler generated code has no source range
+	c, err := NewContract("C", []byte{0x00, 0x00, 0x00}, "0:8:0;-1:-1:0;0:-1:0", []Source{{Name: "test.sol", Content: testSource}})
+	assert.Nil(t, err)
+	_, ok = c.Position(0)
+	assert.True(t, ok)
+	_, ok = c.Position(1)
+	assert.False(t, ok, "negative offset")
+	_, ok = c.Position(2)
+	assert.False(t, ok, "negative length")
+}
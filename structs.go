@@ -113,13 +113,29 @@ type Block struct {
 	GasLimit           uint64
 	GasUsed            uint64
 	Timestamp          uint64
+	BaseFee            *big.Int // nil for blocks before London
+	MixHash            Hash     // prevrandao of the blocks after the merge
+	ExcessBlobGas      *uint64  // nil for blocks before Cancun
 	Transactions       []*Transaction
 	TransactionsHashes []Hash
 	Uncles             []Hash
 }
 
+// TransactionType is the EIP-2718 type of a transaction envelope
+type TransactionType int
+
+const (
+	// TransactionLegacy is the untyped (pre EIP-2718) transaction
+	TransactionLegacy TransactionType = 0
+	// TransactionAccessList is the EIP-2930 access list transaction
+	TransactionAccessList TransactionType = 1
+	// TransactionDynamicFee is the EIP-1559 dynamic fee transaction
+	TransactionDynamicFee TransactionType = 2
+)
+
 type Transaction struct {
 	hash        Hash
+	Type        TransactionType
 	From        Address
 	To          *Address
 	Input       []byte
@@ -133,6 +149,49 @@ type Transaction struct {
 	BlockHash   Hash
 	BlockNumber uint64
 	TxnIndex    uint64
+
+	// typed transaction fields (EIP-2930 and EIP-1559)
+	ChainID              *big.Int
+	AccessList           AccessList
+	MaxPriorityFeePerGas *big.Int
+	MaxFeePerGas         *big.Int
+}
+
+// AccessEntry is an address and the storage slots it will access
+type AccessEntry struct {
+	Address Address `json:"address"`
+	Storage []Hash  `json:"storageKeys"`
+}
+
+// AccessList is an EIP-2930 access list
+type AccessList []AccessEntry
+
+// StorageKeys returns the total number of storage keys in the access list
+func (a AccessList) StorageKeys() int {
+	sum := 0
+	for _, entry := range a {
+		sum += len(entry.Storage)
+	}
+	return sum
+}
+
+// AccountProof is the merkle proof of an account and some of its storage slots, as returned
+// by eth_getProof
+type AccountProof struct {
+	Address      Address
+	AccountProof [][]byte
+	Balance      *big.Int
+	CodeHash     Hash
+	Nonce        uint64
+	StorageHash  Hash
+	StorageProof []*StorageProof
+}
+
+// StorageProof is the merkle proof of a storage slot against the storage root of its account
+type StorageProof struct {
+	Key   Hash
+	Value *big.Int
+	Proof [][]byte
 }
 
 func (t *Transaction) Hash() Hash {
@@ -203,6 +262,7 @@ func (l *LogFilter) SetTo(b BlockNumber) {
 }
 
 type Receipt struct {
+	Type              TransactionType
 	Status            uint64
 	TransactionHash   Hash
 	TransactionIndex  uint64
@@ -212,6 +272,7 @@ type Receipt struct {
 	BlockNumber       uint64
 	GasUsed           uint64
 	CumulativeGasUsed uint64
+	EffectiveGasPrice *big.Int
 	LogsBloom         []byte
 	Logs              []*Log
 }
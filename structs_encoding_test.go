@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"strings"
 	"testing"
 	"text/template"
 
@@ -47,6 +49,27 @@ func TestJSONEncoding(t *testing.T) {
 			}`,
 			build: block,
 		},
+		{
+			Input: `{
+				"number": "0x1",
+				"hash": "{{.Hash1}}",
+				"parentHash": "{{.Hash2}}",
+				"sha3Uncles": "{{.Hash3}}",
+				"transactionsRoot": "{{.Hash1}}",
+				"stateRoot": "{{.Hash3}}",
+				"receiptsRoot": "{{.Hash2}}",
+				"miner": "{{.Addr1}}",
+				"gasLimit": "0x2",
+				"gasUsed": "0x3",
+				"timestamp": "0x4",
+				"difficulty": "0x0",
+				"extraData": "0x01",
+				"baseFeePerGas": "0x7",
+				"mixHash": "{{.Hash3}}",
+				"excessBlobGas": "0x20000"
+			}`,
+			build: block,
+		},
 		{
 			Input: `{
 				"hash": "{{.Hash1}}",
@@ -85,6 +108,36 @@ func TestJSONEncoding(t *testing.T) {
 			}`,
 			build: txn,
 		},
+		{
+			Input: `{
+				"hash": "{{.Hash1}}",
+				"from": "{{.Addr1}}",
+				"input": "0x00",
+				"value": "0x0",
+				"gas": "0x0",
+				"nonce": "0x10",
+				"to": "{{.Addr1}}",
+				"v":"0x01",
+				"r":"{{.Hash1}}",
+				"s":"{{.Hash1}}",
+				"blockHash": "{{.Hash0}}",
+				"blockNumber": "0x0",
+				"transactionIndex": "0x0",
+				"type": "0x2",
+				"chainId": "0x1",
+				"accessList": [
+					{
+						"address": "{{.Addr2}}",
+						"storageKeys": [
+							"{{.Hash2}}"
+						]
+					}
+				],
+				"maxPriorityFeePerGas": "0x1",
+				"maxFeePerGas": "0x2"
+			}`,
+			build: txn,
+		},
 	}
 
 	for _, c := range cases {
@@ -114,6 +167,129 @@ func TestJSONEncoding(t *testing.T) {
 	}
 }
 
+func TestBlockUnknownTransactionType(t *testing.T) {
+	tx := func(typ string) string {
+		return `{
+			"hash": "` + (Hash{0x1}).String() + `",
+			"from": "` + (Address{0x1}).String() + `",
+			"input": "0x00",
+			"value": "0x5",
+			"gas": "0x5208",
+			"gasPrice": "0x4",
+			"nonce": "0x10",
+			"to": "` + (Address{0x2}).String() + `",
+			"v": "0x1",
+			"r": "` + (Hash{0x1}).String() + `",
+			"s": "` + (Hash{0x1}).String() + `",
+			"blockHash": "` + (Hash{0x3}).String() + `",
+			"blockNumber": "0x1",
+			"transactionIndex": "0x0",
+			"type": "` + typ + `",
+			"chainId": "0x1",
+			"accessList": [],
+			"maxPriorityFeePerGas": "0x1",
+			"maxFeePerGas": "0x7",
+			"maxFeePerBlobGas": "0x3",
+			"blobVersionedHashes": ["` + (Hash{0x1}).String() + `"],
+			"yParity": "0x1"
+		}`
+	}
+	input := `{
+		"number": "0x1",
+		"hash": "` + (Hash{0x3}).String() + `",
+		"parentHash": "` + (Hash{0x2}).String() + `",
+		"sha3Uncles": "` + (Hash{0x1}).String() + `",
+		"transactionsRoot": "` + (Hash{0x1}).String() + `",
+		"stateRoot": "` + (Hash{0x1}).String() + `",
+		"receiptsRoot": "` + (Hash{0x1}).String() + `",
+		"miner": "` + (Address{0x1}).String() + `",
+		"gasLimit": "0x2",
+		"gasUsed": "0x3",
+		"timestamp": "0x4",
+		"difficulty": "0x0",
+		"extraData": "0x",
+		"transactions": [` + tx("0x3") + `,` + tx("0x7f") + `]
+	}`
+
+	block := new(Block)
+	assert.NoError(t, block.UnmarshalJSON([]byte(input)))
+	assert.Equal(t, 2, len(block.Transactions))
+	blob := block.Transactions[0]
+	assert.Equal(t, TransactionType(3), blob.Type)
+	assert.Equal(t, TransactionType(0x7f), block.Transactions[1].Type)
+	assert.Equal(t, Hash{0x1}, blob.Hash())
+	assert.Equal(t, uint64(4), blob.GasPrice)
+	assert.Equal(t, uint64(0x5208), blob.Gas)
+	assert.Equal(t, big.NewInt(5), blob.Value)
+	assert.Equal(t, Address{0x2}, *blob.To)
+	assert.Equal(t, big.NewInt(1), blob.ChainID)
+	assert.Equal(t, big.NewInt(7), blob.MaxFeePerGas)
+	assert.Equal(t, big.NewInt(1), blob.MaxPriorityFeePerGas)
+
+	// malformed transactions are reported as errors
+	assert.Error(t, block.UnmarshalJSON([]byte(strings.Replace(input, `"v": "0x1"`, `"v": "0xzz"`, 1))))
+}
+
+func TestTransactionRLP(t *testing.T) {
+	to := Address{0x1}
+	cases := []*Transaction{
+		{
+			Nonce:    1,
+			GasPrice: 2,
+			Gas:      3,
+			To:       &to,
+			Value:    big.NewInt(4),
+			Input:    []byte{0x5},
+			V:        []byte{0x25},
+			R:        []byte{0x1},
+			S:        []byte{0x2},
+		},
+		{
+			Type:     TransactionAccessList,
+			ChainID:  big.NewInt(1),
+			Nonce:    1,
+			GasPrice: 2,
+			Gas:      3,
+			Value:    big.NewInt(4),
+			Input:    []byte{0x5},
+			AccessList: AccessList{
+				{Address: to, Storage: []Hash{{0x1}, {0x2}}},
+				{Address: Address{0x2}},
+			},
+			V: []byte{},
+			R: []byte{0x1},
+			S: []byte{0x2},
+		},
+		{
+			Type:                 TransactionDynamicFee,
+			ChainID:              big.NewInt(1),
+			Nonce:                1,
+			MaxPriorityFeePerGas: big.NewInt(2),
+			MaxFeePerGas:         big.NewInt(3),
+			Gas:                  3,
+			To:                   &to,
+			Value:                big.NewInt(4),
+			Input:                []byte{0x5},
+			AccessList:           AccessList{},
+			V:                    []byte{0x1},
+			R:                    []byte{0x1},
+			S:                    []byte{0x2},
+		},
+	}
+
+	for _, c := range cases {
+		data := c.MarshalRLP()
+		if c.Type != TransactionLegacy {
+			assert.Equal(t, data[0], byte(c.Type))
+		}
+
+		txn := new(Transaction)
+		assert.NoError(t, txn.UnmarshalRLP(data))
+		assert.Equal(t, data, txn.MarshalRLP())
+		assert.Equal(t, c.Hash(), txn.Hash())
+	}
+}
+
 func compactJSON(s string) string {
 	buffer := new(bytes.Buffer)
 	if err := json.Compact(buffer, []byte(s)); err != nil {
@@ -66,6 +66,15 @@ func (t *Block) MarshalJSON() ([]byte, error) {
 	o.Set("timestamp", a.NewString(fmt.Sprintf("0x%x", t.Timestamp)))
 	o.Set("difficulty", a.NewString(fmt.Sprintf("0x%x", t.Difficulty)))
 	o.Set("extraData", a.NewString("0x"+hex.EncodeToString(t.ExtraData)))
+	if t.BaseFee != nil {
+		o.Set("baseFeePerGas", a.NewString(fmt.Sprintf("0x%x", t.BaseFee)))
+	}
+	if t.MixHash != (Hash{}) {
+		o.Set("mixHash", a.NewString(t.MixHash.String()))
+	}
+	if t.ExcessBlobGas != nil {
+		o.Set("excessBlobGas", a.NewString(fmt.Sprintf("0x%x", *t.ExcessBlobGas)))
+	}
 
 	// uncles
 	if len(t.Uncles) != 0 {
@@ -94,7 +103,9 @@ func (t *Transaction) MarshalJSON() ([]byte, error) {
 	if t.Value != nil {
 		o.Set("value", a.NewString(fmt.Sprintf("0x%x", t.Value)))
 	}
-	o.Set("gasPrice", a.NewString(fmt.Sprintf("0x%x", t.GasPrice)))
+	if t.Type != TransactionDynamicFee || t.GasPrice != 0 {
+		o.Set("gasPrice", a.NewString(fmt.Sprintf("0x%x", t.GasPrice)))
+	}
 	o.Set("gas", a.NewString(fmt.Sprintf("0x%x", t.Gas)))
 	if t.Nonce != 0 {
 		// we can remove this once we include support for custom nonces
@@ -113,11 +124,43 @@ func (t *Transaction) MarshalJSON() ([]byte, error) {
 	o.Set("blockNumber", a.NewString(fmt.Sprintf("0x%x", t.BlockNumber)))
 	o.Set("transactionIndex", a.NewString(fmt.Sprintf("0x%x", t.TxnIndex)))
 
+	// typed transaction fields
+	if t.Type != TransactionLegacy {
+		o.Set("type", a.NewString(fmt.Sprintf("0x%x", int(t.Type))))
+		if t.ChainID != nil {
+			o.Set("chainId", a.NewString(fmt.Sprintf("0x%x", t.ChainID)))
+		}
+		o.Set("accessList", t.AccessList.marshalJSONWith(a))
+	}
+	if t.Type == TransactionDynamicFee {
+		if t.MaxPriorityFeePerGas != nil {
+			o.Set("maxPriorityFeePerGas", a.NewString(fmt.Sprintf("0x%x", t.MaxPriorityFeePerGas)))
+		}
+		if t.MaxFeePerGas != nil {
+			o.Set("maxFeePerGas", a.NewString(fmt.Sprintf("0x%x", t.MaxFeePerGas)))
+		}
+	}
+
 	res := o.MarshalTo(nil)
 	defaultArena.Put(a)
 	return res, nil
 }
 
+func (l AccessList) marshalJSONWith(a *fastjson.Arena) *fastjson.Value {
+	v := a.NewArray()
+	for indx, entry := range l {
+		o := a.NewObject()
+		o.Set("address", a.NewString(entry.Address.String()))
+		keys := a.NewArray()
+		for i, key := range entry.Storage {
+			keys.SetArrayItem(i, a.NewString(key.String()))
+		}
+		o.Set("storageKeys", keys)
+		v.SetArrayItem(indx, o)
+	}
+	return v
+}
+
 // MarshalJSON implements the Marshal interface.
 func (c *CallMsg) MarshalJSON() ([]byte, error) {
 	a := defaultArena.Get()
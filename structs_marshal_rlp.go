@@ -1,21 +1,41 @@
 package web3
 
-import "github.com/umbracle/fastrlp"
+import (
+	"fmt"
+	"math/big"
 
+	"github.com/umbracle/fastrlp"
+)
+
+// MarshalRLP marshals the transaction to its canonical encoding. Typed
+// transactions are prefixed with their EIP-2718 type byte.
 func (t *Transaction) MarshalRLP() []byte {
 	ar := fastrlp.DefaultArenaPool.Get()
 	v := t.MarshalRLPWith(ar)
-	data := v.MarshalTo(nil)
+	var data []byte
+	if t.Type != TransactionLegacy {
+		data = append(data, byte(t.Type))
+	}
+	data = v.MarshalTo(data)
 	fastrlp.DefaultArenaPool.Put(ar)
 	return data
 }
 
-// MarshalRLPWith marshals the transaction to RLP with a specific fastrlp.Arena
+// MarshalRLPWith marshals the transaction payload to RLP with a specific fastrlp.Arena.
+// The type byte of a typed transaction is not included.
 func (t *Transaction) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
 	vv := arena.NewArray()
 
+	if t.Type != TransactionLegacy {
+		vv.Set(arena.NewBigInt(t.ChainID))
+	}
 	vv.Set(arena.NewUint(t.Nonce))
-	vv.Set(arena.NewUint(t.GasPrice))
+	if t.Type == TransactionDynamicFee {
+		vv.Set(arena.NewBigInt(t.MaxPriorityFeePerGas))
+		vv.Set(arena.NewBigInt(t.MaxFeePerGas))
+	} else {
+		vv.Set(arena.NewUint(t.GasPrice))
+	}
 	vv.Set(arena.NewUint(t.Gas))
 
 	// Address may be empty
@@ -28,10 +48,246 @@ func (t *Transaction) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
 	vv.Set(arena.NewBigInt(t.Value))
 	vv.Set(arena.NewCopyBytes(t.Input))
 
+	if t.Type != TransactionLegacy {
+		vv.Set(t.AccessList.MarshalRLPWith(arena))
+	}
+
+	// signature values are encoded as integers, without leading zeros
+	vv.Set(arena.NewBigInt(new(big.Int).SetBytes(t.V)))
+	vv.Set(arena.NewBigInt(new(big.Int).SetBytes(t.R)))
+	vv.Set(arena.NewBigInt(new(big.Int).SetBytes(t.S)))
+
+	return vv
+}
+
+// MarshalRLPWith marshals the access list to RLP with a specific fastrlp.Arena
+func (a AccessList) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
+	if len(a) == 0 {
+		return arena.NewNullArray()
+	}
+	vv := arena.NewArray()
+	for _, entry := range a {
+		elem := arena.NewArray()
+		elem.Set(arena.NewCopyBytes(entry.Address[:]))
+
+		storage := arena.NewNullArray()
+		if len(entry.Storage) != 0 {
+			storage = arena.NewArray()
+			for _, key := range entry.Storage {
+				storage.Set(arena.NewCopyBytes(key[:]))
+			}
+		}
+		elem.Set(storage)
+		vv.Set(elem)
+	}
+	return vv
+}
+
+// UnmarshalRLP decodes a transaction from its canonical encoding, either a legacy
+// RLP list or an EIP-2718 typed envelope.
+func (t *Transaction) UnmarshalRLP(buf []byte) error {
+	if len(buf) == 0 {
+		return fmt.Errorf("empty transaction data")
+	}
+	t.Type = TransactionLegacy
+	if buf[0] <= 0x7f {
+		// typed envelope: type byte followed by the rlp payload
+		t.Type = TransactionType(buf[0])
+		if t.Type != TransactionAccessList && t.Type != TransactionDynamicFee {
+			return fmt.Errorf("transaction type %d not supported", t.Type)
+		}
+		buf = buf[1:]
+	}
+
+	p := fastrlp.DefaultParserPool.Get()
+	defer fastrlp.DefaultParserPool.Put(p)
+
+	v, err := p.Parse(buf)
+	if err != nil {
+		return err
+	}
+	if err := t.UnmarshalRLPFrom(v); err != nil {
+		return err
+	}
+	t.hash = Hash{}
+	return nil
+}
+
+// UnmarshalRLPFrom decodes the transaction payload from a parsed rlp value.
+// The Type field must be set before calling it.
+func (t *Transaction) UnmarshalRLPFrom(v *fastrlp.Value) error {
+	elems, err := v.GetElems()
+	if err != nil {
+		return err
+	}
+	num := 9
+	switch t.Type {
+	case TransactionAccessList:
+		num = 11
+	case TransactionDynamicFee:
+		num = 12
+	}
+	if len(elems) != num {
+		return fmt.Errorf("incorrect number of elements to decode transaction, expected %d but found %d", num, len(elems))
+	}
+
+	getBig := func(v *fastrlp.Value) (*big.Int, error) {
+		b := new(big.Int)
+		if err := v.GetBigInt(b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+
+	if t.Type != TransactionLegacy {
+		if t.ChainID, err = getBig(elems[0]); err != nil {
+			return err
+		}
+		elems = elems[1:]
+	}
+	if t.Nonce, err = elems[0].GetUint64(); err != nil {
+		return err
+	}
+	if t.Type == TransactionDynamicFee {
+		if t.MaxPriorityFeePerGas, err = getBig(elems[1]); err != nil {
+			return err
+		}
+		if t.MaxFeePerGas, err = getBig(elems[2]); err != nil {
+			return err
+		}
+		elems = elems[3:]
+	} else {
+		if t.GasPrice, err = elems[1].GetUint64(); err != nil {
+			return err
+		}
+		elems = elems[2:]
+	}
+	if t.Gas, err = elems[0].GetUint64(); err != nil {
+		return err
+	}
+
+	// to address may be empty for contract creation
+	to, err := elems[1].Bytes()
+	if err != nil {
+		return err
+	}
+	t.To = nil
+	if len(to) != 0 {
+		var addr Address
+		if err := elems[1].GetAddr(addr[:]); err != nil {
+			return err
+		}
+		t.To = &addr
+	}
+
+	if t.Value, err = getBig(elems[2]); err != nil {
+		return err
+	}
+	if t.Input, err = elems[3].GetBytes(t.Input[:0]); err != nil {
+		return err
+	}
+	elems = elems[4:]
+
+	if t.Type != TransactionLegacy {
+		if err := t.AccessList.UnmarshalRLPFrom(elems[0]); err != nil {
+			return err
+		}
+		elems = elems[1:]
+	}
+
 	// signature values
-	vv.Set(arena.NewCopyBytes(t.V))
-	vv.Set(arena.NewCopyBytes(t.R))
-	vv.Set(arena.NewCopyBytes(t.S))
+	if t.V, err = elems[0].GetBytes(t.V[:0]); err != nil {
+		return err
+	}
+	if t.R, err = elems[1].GetBytes(t.R[:0]); err != nil {
+		return err
+	}
+	if t.S, err = elems[2].GetBytes(t.S[:0]); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnmarshalRLPFrom decodes the access list from a parsed rlp value
+func (a *AccessList) UnmarshalRLPFrom(v *fastrlp.Value) error {
+	elems, err := v.GetElems()
+	if err != nil {
+		return err
+	}
+	list := AccessList{}
+	for _, elem := range elems {
+		fields, err := elem.GetElems()
+		if err != nil {
+			return err
+		}
+		if len(fields) != 2 {
+			return fmt.Errorf("incorrect number of elements to decode access entry, expected 2 but found %d", len(fields))
+		}
+		entry := AccessEntry{}
+		if err := fields[0].GetAddr(entry.Address[:]); err != nil {
+			return err
+		}
+		keys, err := fields[1].GetElems()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			var h Hash
+			if err := key.GetHash(h[:]); err != nil {
+				return err
+			}
+			entry.Storage = append(entry.Storage, h)
+		}
+		list = append(list, entry)
+	}
+	*a = list
+	return nil
+}
+
+// MarshalRLP marshals the consensus fields of the receipt, as hashed into the receipts root of
+// the block. Typed receipts are prefixed with their EIP-2718 type byte.
+func (r *Receipt) MarshalRLP() []byte {
+	ar := fastrlp.DefaultArenaPool.Get()
+	v := r.MarshalRLPWith(ar)
+	var data []byte
+	if r.Type != TransactionLegacy {
+		data = append(data, byte(r.Type))
+	}
+	data = v.MarshalTo(data)
+	fastrlp.DefaultArenaPool.Put(ar)
+	return data
+}
 
+// MarshalRLPWith marshals the consensus fields of the receipt to RLP with a specific fastrlp.Arena.
+// The bloom is computed from the logs if it is not set.
+func (r *Receipt) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
+	vv := arena.NewArray()
+	vv.Set(arena.NewUint(r.Status))
+	vv.Set(arena.NewUint(r.CumulativeGasUsed))
+	bloom := r.LogsBloom
+	if len(bloom) == 0 {
+		bloom = LogsBloom(r.Logs).Bytes()
+	}
+	vv.Set(arena.NewCopyBytes(bloom))
+
+	logs := arena.NewNullArray()
+	if len(r.Logs) != 0 {
+		logs = arena.NewArray()
+		for _, log := range r.Logs {
+			elem := arena.NewArray()
+			elem.Set(arena.NewCopyBytes(log.Address[:]))
+			topics := arena.NewNullArray()
+			if len(log.Topics) != 0 {
+				topics = arena.NewArray()
+				for _, topic := range log.Topics {
+					topics.Set(arena.NewCopyBytes(topic[:]))
+				}
+			}
+			elem.Set(topics)
+			elem.Set(arena.NewCopyBytes(log.Data))
+			logs.Set(elem)
+		}
+	}
+	vv.Set(logs)
 	return vv
 }
@@ -61,6 +61,26 @@ func (b *Block) UnmarshalJSON(buf []byte) error {
 	if b.ExtraData, err = decodeBytes(b.ExtraData[:0], v, "extraData"); err != nil {
 		return err
 	}
+	b.BaseFee = nil
+	if fieldNotFull(v, "baseFeePerGas") {
+		if b.BaseFee, err = decodeBigInt(b.BaseFee, v, "baseFeePerGas"); err != nil {
+			return err
+		}
+	}
+	b.MixHash = Hash{}
+	if fieldNotFull(v, "mixHash") {
+		if err := decodeHash(&b.MixHash, v, "mixHash"); err != nil {
+			return err
+		}
+	}
+	b.ExcessBlobGas = nil
+	if fieldNotFull(v, "excessBlobGas") {
+		excessBlobGas, err := decodeUint(v, "excessBlobGas")
+		if err != nil {
+			return err
+		}
+		b.ExcessBlobGas = &excessBlobGas
+	}
 
 	b.TransactionsHashes = b.TransactionsHashes[:0]
 	b.Transactions = b.Transactions[:0]
@@ -81,7 +101,7 @@ func (b *Block) UnmarshalJSON(buf []byte) error {
 			for _, elem := range elems {
 				txn := new(Transaction)
 				if err := txn.unmarshalJSON(elem); err != nil {
-					panic(err)
+					return err
 				}
 				b.Transactions = append(b.Transactions, txn)
 			}
@@ -121,8 +141,25 @@ func (t *Transaction) unmarshalJSON(v *fastjson.Value) error {
 	if err = decodeAddr(&t.From, v, "from"); err != nil {
 		return err
 	}
-	if t.GasPrice, err = decodeUint(v, "gasPrice"); err != nil {
-		return err
+
+	// legacy transactions do not include the type field
+	t.Type = TransactionLegacy
+	if v.Exists("type") {
+		typ, err := decodeUint(v, "type")
+		if err != nil {
+			return err
+		}
+		t.Type = TransactionType(typ)
+	}
+	// the types after the dynamic fee one, e.g. blob transactions, are decoded leniently: only the
+	// fields shared with the known types are kept
+	unknown := t.Type > TransactionDynamicFee
+
+	// dynamic fee transactions only include gasPrice once mined
+	if (t.Type != TransactionDynamicFee && !unknown) || fieldNotFull(v, "gasPrice") {
+		if t.GasPrice, err = decodeUint(v, "gasPrice"); err != nil {
+			return err
+		}
 	}
 	if t.Gas, err = decodeUint(v, "gas"); err != nil {
 		return err
@@ -149,13 +186,13 @@ func (t *Transaction) unmarshalJSON(v *fastjson.Value) error {
 	}
 
 	if t.V, err = decodeBytes(t.V[:0], v, "v"); err != nil {
-		panic(err)
+		return err
 	}
 	if t.R, err = decodeBytes(t.R[:0], v, "r"); err != nil {
-		panic(err)
+		return err
 	}
 	if t.S, err = decodeBytes(t.S[:0], v, "s"); err != nil {
-		panic(err)
+		return err
 	}
 
 	// those fields are null for pending transaction
@@ -168,6 +205,46 @@ func (t *Transaction) unmarshalJSON(v *fastjson.Value) error {
 	if t.TxnIndex, err = decodeUintOrNull(v, "transactionIndex"); err != nil {
 		return err
 	}
+
+	// typed transaction fields
+	if t.Type != TransactionLegacy {
+		if fieldNotFull(v, "chainId") {
+			if t.ChainID, err = decodeBigInt(t.ChainID, v, "chainId"); err != nil {
+				return err
+			}
+		}
+		if err = t.AccessList.unmarshalJSON(v.GetArray("accessList")); err != nil {
+			return err
+		}
+	}
+	if t.Type == TransactionDynamicFee || (unknown && fieldNotFull(v, "maxFeePerGas")) {
+		if t.MaxPriorityFeePerGas, err = decodeBigInt(t.MaxPriorityFeePerGas, v, "maxPriorityFeePerGas"); err != nil {
+			return err
+		}
+		if t.MaxFeePerGas, err = decodeBigInt(t.MaxFeePerGas, v, "maxFeePerGas"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *AccessList) unmarshalJSON(elems []*fastjson.Value) error {
+	list := AccessList{}
+	for _, elem := range elems {
+		entry := AccessEntry{}
+		if err := decodeAddr(&entry.Address, elem, "address"); err != nil {
+			return err
+		}
+		for _, key := range elem.GetArray("storageKeys") {
+			var h Hash
+			if err := h.UnmarshalText(key.GetStringBytes()); err != nil {
+				return err
+			}
+			entry.Storage = append(entry.Storage, h)
+		}
+		list = append(list, entry)
+	}
+	*l = list
 	return nil
 }
 
@@ -204,12 +281,27 @@ func (r *Receipt) UnmarshalJSON(buf []byte) error {
 	if r.GasUsed, err = decodeUint(v, "gasUsed"); err != nil {
 		return err
 	}
+	// nodes before berlin do not include the type field
+	r.Type = TransactionLegacy
+	if v.Exists("type") {
+		typ, err := decodeUint(v, "type")
+		if err != nil {
+			return err
+		}
+		r.Type = TransactionType(typ)
+	}
 	if r.Status, err = decodeUint(v, "status"); err != nil {
 		return err
 	}
 	if r.CumulativeGasUsed, err = decodeUint(v, "cumulativeGasUsed"); err != nil {
 		return err
 	}
+	r.EffectiveGasPrice = nil
+	if fieldNotFull(v, "effectiveGasPrice") {
+		if r.EffectiveGasPrice, err = decodeBigInt(r.EffectiveGasPrice, v, "effectiveGasPrice"); err != nil {
+			return err
+		}
+	}
 	if r.LogsBloom, err = decodeBytes(r.LogsBloom[:0], v, "logsBloom", 256); err != nil {
 		return err
 	}
@@ -227,6 +319,82 @@ func (r *Receipt) UnmarshalJSON(buf []byte) error {
 	return nil
 }
 
+// UnmarshalJSON implements the unmarshal interface
+func (p *AccountProof) UnmarshalJSON(buf []byte) error {
+	pp := defaultPool.Get()
+	defer defaultPool.Put(pp)
+
+	v, err := pp.Parse(string(buf))
+	if err != nil {
+		return err
+	}
+
+	if err := decodeAddr(&p.Address, v, "address"); err != nil {
+		return err
+	}
+	if p.AccountProof, err = decodeProof(v, "accountProof"); err != nil {
+		return err
+	}
+	if p.Balance, err = decodeBigInt(p.Balance, v, "balance"); err != nil {
+		return err
+	}
+	if err := decodeHash(&p.CodeHash, v, "codeHash"); err != nil {
+		return err
+	}
+	if p.Nonce, err = decodeUint(v, "nonce"); err != nil {
+		return err
+	}
+	if err := decodeHash(&p.StorageHash, v, "storageHash"); err != nil {
+		return err
+	}
+
+	p.StorageProof = p.StorageProof[:0]
+	for _, elem := range v.GetArray("storageProof") {
+		proof := &StorageProof{}
+		// nodes return the key as requested, which may be shorter than 32 bytes
+		key, err := decodeBytes(nil, elem, "key")
+		if err != nil {
+			return err
+		}
+		if len(key) > HashLength {
+			return fmt.Errorf("storage proof key too long: %d bytes", len(key))
+		}
+		proof.Key = BytesToHash(key)
+		if proof.Value, err = decodeBigInt(nil, elem, "value"); err != nil {
+			return err
+		}
+		if proof.Proof, err = decodeProof(elem, "proof"); err != nil {
+			return err
+		}
+		p.StorageProof = append(p.StorageProof, proof)
+	}
+	return nil
+}
+
+func decodeProof(v *fastjson.Value, key string) ([][]byte, error) {
+	vv := v.Get(key)
+	if vv == nil {
+		return nil, fmt.Errorf("field '%s' not found", key)
+	}
+	elems, err := vv.Array()
+	if err != nil {
+		return nil, err
+	}
+	proof := make([][]byte, 0, len(elems))
+	for _, elem := range elems {
+		str := strings.Trim(elem.String(), "\"")
+		if !strings.HasPrefix(str, "0x") {
+			return nil, fmt.Errorf("field %s does not have 0x prefix", str)
+		}
+		node, err := hex.DecodeString(str[2:])
+		if err != nil {
+			return nil, err
+		}
+		proof = append(proof, node)
+	}
+	return proof, nil
+}
+
 // UnmarshalJSON implements the unmarshal interface
 func (r *Log) UnmarshalJSON(buf []byte) error {
 	p := defaultPool.Get()
@@ -88,11 +88,16 @@ func NewSubscriptionBlockTracker(logger *log.Logger, client *jsonrpc.Client) (*S
 	return s, nil
 }
 
-// Track implements the BlockTracker interface
+// Track implements the BlockTracker interface. The subscription is re-established by the client
+// when the connection is lost, the blocks missed meanwhile are recovered by the reconciliation
+// of the next block.
 func (s *SubscriptionBlockTracker) Track(ctx context.Context, handle func(block *web3.Block) error) error {
 	data := make(chan []byte)
 	cancel, err := s.client.Subscribe("newHeads", nil, func(b []byte) {
-		data <- b
+		select {
+		case data <- b:
+		case <-ctx.Done():
+		}
 	})
 	if err != nil {
 		return err
@@ -111,6 +116,7 @@ func (s *SubscriptionBlockTracker) Track(ctx context.Context, handle func(block
 
 			case <-ctx.Done():
 				cancel()
+				return
 			}
 		}
 	}()
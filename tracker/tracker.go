@@ -205,6 +205,27 @@ type Provider interface {
 	ChainID() (*big.Int, error)
 }
 
+// ContextProvider is implemented by providers which can abort the requests of a cancelled sync,
+// jsonrpc.Eth implements it.
+type ContextProvider interface {
+	GetBlockByNumberContext(ctx context.Context, i web3.BlockNumber, full bool) (*web3.Block, error)
+	GetLogsContext(ctx context.Context, filter *web3.LogFilter) ([]*web3.Log, error)
+}
+
+func (t *Tracker) getLogs(ctx context.Context, filter *web3.LogFilter) ([]*web3.Log, error) {
+	if provider, ok := t.provider.(ContextProvider); ok {
+		return provider.GetLogsContext(ctx, filter)
+	}
+	return t.provider.GetLogs(filter)
+}
+
+func (t *Tracker) getBlockByNumber(ctx context.Context, i web3.BlockNumber, full bool) (*web3.Block, error) {
+	if provider, ok := t.provider.(ContextProvider); ok {
+		return provider.GetBlockByNumberContext(ctx, i, full)
+	}
+	return t.provider.GetBlockByNumber(i, full)
+}
+
 // Tracker is a contract event tracker
 type Tracker struct {
 	logger      *log.Logger
@@ -366,7 +387,7 @@ START:
 	query.SetFromUint64(i)
 	query.SetToUint64(dst)
 
-	logs, err := t.provider.GetLogs(query)
+	logs, err := t.getLogs(ctx, query)
 	if err != nil {
 		if tooMuchDataRequestedError(err) {
 			// multiplicative decrease
@@ -390,7 +411,7 @@ START:
 	filter.emitLogs(EventAdd, logs)
 
 	// update the last block entry
-	block, err := t.provider.GetBlockByNumber(web3.BlockNumber(dst), false)
+	block, err := t.getBlockByNumber(ctx, web3.BlockNumber(dst), false)
 	if err != nil {
 		return err
 	}
@@ -646,7 +667,7 @@ func (t *Tracker) syncImpl(ctx context.Context, filter *Filter) error {
 			return fmt.Errorf("store is more advanced than the chain")
 		}
 
-		pivot, err := t.provider.GetBlockByNumber(web3.BlockNumber(last.Number), false)
+		pivot, err := t.getBlockByNumber(ctx, web3.BlockNumber(last.Number), false)
 		if err != nil {
 			return err
 		}
@@ -670,7 +691,7 @@ func (t *Tracker) syncImpl(ctx context.Context, filter *Filter) error {
 			}
 			filter.emitLogs(EventDel, logs)
 
-			last, err = t.provider.GetBlockByNumber(web3.BlockNumber(ancestor), false)
+			last, err = t.getBlockByNumber(ctx, web3.BlockNumber(ancestor), false)
 			if err != nil {
 				return err
 			}
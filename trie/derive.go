@@ -0,0 +1,32 @@
+package trie
+
+import (
+	"github.com/laizy/web3"
+	"github.com/umbracle/fastrlp"
+)
+
+// DeriveTransactionsRoot returns the transactions root of a block holding the transactions
+func DeriveTransactionsRoot(txs []*web3.Transaction) web3.Hash {
+	t := NewTrie()
+	for i, tx := range txs {
+		t.Update(indexKey(i), tx.MarshalRLP())
+	}
+	return t.Hash()
+}
+
+// DeriveReceiptsRoot returns the receipts root of a block with the receipts, which must be
+// post-byzantium receipts holding a status
+func DeriveReceiptsRoot(receipts []*web3.Receipt) web3.Hash {
+	t := NewTrie()
+	for i, receipt := range receipts {
+		t.Update(indexKey(i), receipt.MarshalRLP())
+	}
+	return t.Hash()
+}
+
+// indexKey returns the trie key of the i-th item of a block, the rlp encoding of the index
+func indexKey(i int) []byte {
+	ar := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(ar)
+	return ar.NewUint(uint64(i)).MarshalTo(nil)
+}
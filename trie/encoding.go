@@ -0,0 +1,56 @@
+package trie
+
+// keybytesToHex converts a key to nibbles, one nibble per byte
+func keybytesToHex(key []byte) []byte {
+	nibbles := make([]byte, len(key)*2)
+	for i, b := range key {
+		nibbles[i*2] = b / 16
+		nibbles[i*2+1] = b % 16
+	}
+	return nibbles
+}
+
+// hexToCompact encodes nibbles with the hex prefix encoding, the flag nibble tells whether the
+// node is a leaf and whether the number of nibbles is odd.
+func hexToCompact(hex []byte, leaf bool) []byte {
+	terminator := byte(0)
+	if leaf {
+		terminator = 1
+	}
+	buf := make([]byte, len(hex)/2+1)
+	buf[0] = terminator << 5 // the flag byte
+	if len(hex)&1 == 1 {
+		buf[0] |= 1 << 4 // odd flag
+		buf[0] |= hex[0] // first nibble is contained in the first byte
+		hex = hex[1:]
+	}
+	for bi, ni := 0, 0; ni < len(hex); bi, ni = bi+1, ni+2 {
+		buf[bi+1] = hex[ni]<<4 | hex[ni+1]
+	}
+	return buf
+}
+
+// prefixLen returns the length of the common prefix of a and b
+func prefixLen(a, b []byte) int {
+	i, length := 0, len(a)
+	if len(b) < length {
+		length = len(b)
+	}
+	for ; i < length; i++ {
+		if a[i] != b[i] {
+			break
+		}
+	}
+	return i
+}
+
+// compactToHex decodes the hex prefix encoding, returning the nibbles and whether the node is a leaf
+func compactToHex(compact []byte) ([]byte, bool) {
+	leaf := compact[0]>>5&1 == 1
+	nibbles := keybytesToHex(compact)
+	// skip the flag nibble, and the padding nibble for an even number of nibbles
+	if nibbles[0]&1 == 1 {
+		return nibbles[1:], leaf
+	}
+	return nibbles[2:], leaf
+}
@@ -0,0 +1,167 @@
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/umbracle/fastrlp"
+)
+
+// ErrInvalidProof is returned when a merkle proof does not match the root
+var ErrInvalidProof = errors.New("invalid merkle proof")
+
+// VerifyProof checks the merkle proof of the key against the root hash and returns the value of
+// the key, nil if the proof shows the key is not in the trie. The proof is the list of the rlp
+// encoded nodes on the path from the root to the key, as returned by eth_getProof.
+func VerifyProof(root web3.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	nodes := make(map[web3.Hash][]byte, len(proof))
+	for _, node := range proof {
+		nodes[crypto.Keccak256Hash(node)] = node
+	}
+
+	p := fastrlp.DefaultParserPool.Get()
+	defer fastrlp.DefaultParserPool.Put(p)
+
+	path := keybytesToHex(key)
+	want := root
+	for {
+		raw, ok := nodes[want]
+		if !ok {
+			if want == EmptyRoot && len(proof) == 0 {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("%w: missing node %s", ErrInvalidProof, want)
+		}
+		node, err := p.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+		}
+
+		// walk the nodes embedded in the current node until a hash reference is met
+		for {
+			var child *fastrlp.Value
+			var value []byte
+			child, value, path, err = walkNode(node, path)
+			if err != nil {
+				return nil, err
+			}
+			if child == nil {
+				return value, nil
+			}
+			if child.Type() == fastrlp.TypeArray {
+				node = child
+				continue
+			}
+			ref, err := child.Bytes()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+			}
+			if len(ref) == 0 {
+				// empty branch slot, the key is not in the trie
+				return nil, nil
+			}
+			if len(ref) != web3.HashLength {
+				return nil, fmt.Errorf("%w: invalid node reference", ErrInvalidProof)
+			}
+			want = web3.BytesToHash(ref)
+			break
+		}
+	}
+}
+
+// walkNode follows the path through the node. It returns the child to continue with and the
+// remaining path, or the value of the key when the walk ends, nil if the key is not in the trie.
+func walkNode(node *fastrlp.Value, path []byte) (*fastrlp.Value, []byte, []byte, error) {
+	elems, err := node.GetElems()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	switch len(elems) {
+	case 2:
+		compact, err := elems[0].Bytes()
+		if err != nil || len(compact) == 0 {
+			return nil, nil, nil, fmt.Errorf("%w: invalid short node", ErrInvalidProof)
+		}
+		nibbles, leaf := compactToHex(compact)
+		if !bytes.HasPrefix(path, nibbles) {
+			return nil, nil, nil, nil
+		}
+		path = path[len(nibbles):]
+		if !leaf {
+			return elems[1], nil, path, nil
+		}
+		if len(path) != 0 {
+			return nil, nil, nil, nil
+		}
+		value, err := elems[1].Bytes()
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+		}
+		return nil, append([]byte{}, value...), nil, nil
+	case 17:
+		if len(path) == 0 {
+			value, err := elems[16].Bytes()
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+			}
+			if len(value) == 0 {
+				return nil, nil, nil, nil
+			}
+			return nil, append([]byte{}, value...), nil, nil
+		}
+		return elems[path[0]], nil, path[1:], nil
+	default:
+		return nil, nil, nil, fmt.Errorf("%w: invalid node with %d elements", ErrInvalidProof, len(elems))
+	}
+}
+
+// VerifyAccountProof checks the account and storage proofs returned by eth_getProof against the
+// state root of a block.
+func VerifyAccountProof(stateRoot web3.Hash, proof *web3.AccountProof) error {
+	value, err := VerifyProof(stateRoot, crypto.Keccak256(proof.Address[:]), proof.AccountProof)
+	if err != nil {
+		return fmt.Errorf("account %s: %w", proof.Address, err)
+	}
+
+	ar := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(ar)
+	storageRoot := proof.StorageHash
+	if value == nil {
+		// the account does not exist, the proof must carry the fields of an empty account.
+		// geth reports a zero storage hash for it, other clients the empty root
+		if proof.Nonce != 0 || proof.Balance.Sign() != 0 ||
+			(proof.StorageHash != EmptyRoot && proof.StorageHash != web3.Hash{}) ||
+			(proof.CodeHash != crypto.Keccak256Hash(nil) && proof.CodeHash != web3.Hash{}) {
+			return fmt.Errorf("account %s: %w: non-existent account with non empty fields", proof.Address, ErrInvalidProof)
+		}
+		storageRoot = EmptyRoot
+	} else {
+		vv := ar.NewArray()
+		vv.Set(ar.NewUint(proof.Nonce))
+		vv.Set(ar.NewBigInt(proof.Balance))
+		vv.Set(ar.NewCopyBytes(proof.StorageHash[:]))
+		vv.Set(ar.NewCopyBytes(proof.CodeHash[:]))
+		if !bytes.Equal(vv.MarshalTo(nil), value) {
+			return fmt.Errorf("account %s: %w: account fields do not match the proof", proof.Address, ErrInvalidProof)
+		}
+	}
+
+	for _, slot := range proof.StorageProof {
+		value, err := VerifyProof(storageRoot, crypto.Keccak256(slot.Key[:]), slot.Proof)
+		if err != nil {
+			return fmt.Errorf("account %s slot %s: %w", proof.Address, slot.Key, err)
+		}
+		ar.Reset()
+		expected := []byte(nil)
+		if slot.Value.Sign() != 0 {
+			expected = ar.NewBigInt(slot.Value).MarshalTo(nil)
+		}
+		if !bytes.Equal(expected, value) {
+			return fmt.Errorf("account %s slot %s: %w: value does not match the proof", proof.Address, slot.Key, ErrInvalidProof)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package trie
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/fastrlp"
+)
+
+func TestProof(t *testing.T) {
+	trie := NewTrie()
+	vals := map[string]string{
+		"do":    "verb",
+		"dog":   "puppy",
+		"doge":  "coin",
+		"horse": "stallion",
+		"long":  "a value which is long enough to be referenced by hash",
+	}
+	for k, v := range vals {
+		trie.Update([]byte(k), []byte(v))
+	}
+	root := trie.Hash()
+
+	for k, v := range vals {
+		value, err := VerifyProof(root, []byte(k), trie.Prove([]byte(k)))
+		assert.Nil(t, err)
+		assert.Equal(t, []byte(v), value)
+	}
+	for _, k := range []string{"d", "dogs", "cat", "horses", ""} {
+		value, err := VerifyProof(root, []byte(k), trie.Prove([]byte(k)))
+		assert.Nil(t, err, k)
+		assert.Nil(t, value, k)
+	}
+
+	// a proof against another root is rejected
+	_, err := VerifyProof(web3.Hash{1}, []byte("dog"), trie.Prove([]byte("dog")))
+	assert.ErrorIs(t, err, ErrInvalidProof)
+	// a proof missing nodes is rejected
+	proof := trie.Prove([]byte("long"))
+	_, err = VerifyProof(root, []byte("long"), proof[:len(proof)-1])
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	value, err := VerifyProof(EmptyRoot, []byte("dog"), nil)
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+}
+
+func TestVerifyAccountProof(t *testing.T) {
+	addr := web3.BytesToAddress([]byte{1})
+	slot := web3.BytesToHash([]byte{1})
+	codeHash := crypto.Keccak256Hash([]byte{0x60, 0x00})
+
+	storage := NewSecureTrie()
+	storage.Update(slot[:], []byte{0x82, 0x12, 0x34})
+	ar := &fastrlp.Arena{}
+	storageHash := storage.Hash()
+	acct := ar.NewArray()
+	acct.Set(ar.NewUint(1))
+	acct.Set(ar.NewUint(1000))
+	acct.Set(ar.NewBytes(storageHash[:]))
+	acct.Set(ar.NewBytes(codeHash[:]))
+	state := NewSecureTrie()
+	state.Update(addr[:], acct.MarshalTo(nil))
+	state.Update(web3.BytesToAddress([]byte{2}).Bytes(), []byte{0xc0})
+
+	missing := web3.BytesToHash([]byte{2})
+	proof := &web3.AccountProof{
+		Address:      addr,
+		AccountProof: state.Prove(addr[:]),
+		Balance:      big.NewInt(1000),
+		CodeHash:     codeHash,
+		Nonce:        1,
+		StorageHash:  storageHash,
+		StorageProof: []*web3.StorageProof{
+			{Key: slot, Value: big.NewInt(0x1234), Proof: storage.Prove(slot[:])},
+			{Key: missing, Value: big.NewInt(0), Proof: storage.Prove(missing[:])},
+		},
+	}
+	assert.Nil(t, VerifyAccountProof(state.Hash(), proof))
+
+	proof.Balance = big.NewInt(1001)
+	assert.ErrorIs(t, VerifyAccountProof(state.Hash(), proof), ErrInvalidProof)
+	proof.Balance = big.NewInt(1000)
+
+	proof.StorageProof[0].Value = big.NewInt(0x1235)
+	assert.ErrorIs(t, VerifyAccountProof(state.Hash(), proof), ErrInvalidProof)
+	proof.StorageProof[0].Value = big.NewInt(0x1234)
+
+	// the proof of a missing account must carry empty fields
+	other := web3.BytesToAddress([]byte{3})
+	absent := &web3.AccountProof{
+		Address:      other,
+		AccountProof: state.Prove(other[:]),
+		Balance:      big.NewInt(0),
+		CodeHash:     crypto.Keccak256Hash(nil),
+		StorageHash:  EmptyRoot,
+	}
+	assert.Nil(t, VerifyAccountProof(state.Hash(), absent))
+	absent.Balance = big.NewInt(1)
+	assert.ErrorIs(t, VerifyAccountProof(state.Hash(), absent), ErrInvalidProof)
+
+	// geth answers with zero hashes and empty slot proofs for a missing account
+	absent = &web3.AccountProof{
+		Address:      other,
+		AccountProof: state.Prove(other[:]),
+		Balance:      big.NewInt(0),
+		StorageProof: []*web3.StorageProof{
+			{Key: slot, Value: big.NewInt(0), Proof: [][]byte{}},
+		},
+	}
+	assert.Nil(t, VerifyAccountProof(state.Hash(), absent))
+	absent.StorageProof[0].Value = big.NewInt(1)
+	assert.ErrorIs(t, VerifyAccountProof(state.Hash(), absent), ErrInvalidProof)
+	absent.StorageProof[0].Value = big.NewInt(0)
+	absent.StorageHash = storageHash
+	assert.ErrorIs(t, VerifyAccountProof(state.Hash(), absent), ErrInvalidProof)
+}
@@ -0,0 +1,42 @@
+package trie
+
+import (
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+)
+
+// SecureTrie is a trie whose keys are hashed with keccak256 before insertion, it is used for the
+// account trie and the storage tries of the state.
+type SecureTrie struct {
+	trie *Trie
+}
+
+// NewSecureTrie creates an empty secure trie
+func NewSecureTrie() *SecureTrie {
+	return &SecureTrie{trie: NewTrie()}
+}
+
+// Update sets the value of the key, an empty value deletes the key
+func (self *SecureTrie) Update(key, value []byte) {
+	self.trie.Update(crypto.Keccak256(key), value)
+}
+
+// Delete removes the key from the trie
+func (self *SecureTrie) Delete(key []byte) {
+	self.trie.Delete(crypto.Keccak256(key))
+}
+
+// Get returns the value of the key, nil if the key is not in the trie
+func (self *SecureTrie) Get(key []byte) []byte {
+	return self.trie.Get(crypto.Keccak256(key))
+}
+
+// Prove returns the merkle proof of the hashed key, see Trie.Prove
+func (self *SecureTrie) Prove(key []byte) [][]byte {
+	return self.trie.Prove(crypto.Keccak256(key))
+}
+
+// Hash returns the root hash of the trie
+func (self *SecureTrie) Hash() web3.Hash {
+	return self.trie.Hash()
+}
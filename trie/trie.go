@@ -0,0 +1,175 @@
+// Package trie computes the root hash of Merkle Patricia Tries, as used for the state, storage,
+// transactions and receipts roots of ethereum blocks.
+package trie
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/umbracle/fastrlp"
+)
+
+// EmptyRoot is the root hash of an empty trie
+var EmptyRoot = web3.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+// Trie is an in-memory Merkle Patricia Trie. Only the key/value pairs are kept, the nodes are
+// built when the root hash is computed, so updates are cheap and Hash is O(n log n).
+type Trie struct {
+	kvs map[string][]byte
+}
+
+// NewTrie creates an empty trie
+func NewTrie() *Trie {
+	return &Trie{kvs: make(map[string][]byte)}
+}
+
+// Update sets the value of the key, an empty value deletes the key
+func (self *Trie) Update(key, value []byte) {
+	if len(value) == 0 {
+		self.Delete(key)
+		return
+	}
+	self.kvs[string(key)] = append([]byte{}, value...)
+}
+
+// Delete removes the key from the trie
+func (self *Trie) Delete(key []byte) {
+	delete(self.kvs, string(key))
+}
+
+// Get returns the value of the key, nil if the key is not in the trie
+func (self *Trie) Get(key []byte) []byte {
+	return self.kvs[string(key)]
+}
+
+// Len returns the number of keys in the trie
+func (self *Trie) Len() int {
+	return len(self.kvs)
+}
+
+type entry struct {
+	key   []byte // nibbles
+	value []byte
+}
+
+// Hash returns the root hash of the trie
+func (self *Trie) Hash() web3.Hash {
+	if len(self.kvs) == 0 {
+		return EmptyRoot
+	}
+	ar := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(ar)
+	root := build(ar, self.entries(), 0).MarshalTo(nil)
+	return crypto.Keccak256Hash(root)
+}
+
+// Prove returns the merkle proof of the key, the rlp encoded nodes on the path from the root to
+// the key, which can be checked with VerifyProof. The proof of a missing key shows its absence.
+func (self *Trie) Prove(key []byte) [][]byte {
+	ar := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(ar)
+
+	var proof [][]byte
+	path := keybytesToHex(key)
+	entries := self.entries()
+	for depth := 0; len(entries) != 0; {
+		ar.Reset()
+		// nodes shorter than a hash are embedded into their parent, except the root
+		if raw := build(ar, entries, depth).MarshalTo(nil); len(proof) == 0 || len(raw) >= 32 {
+			proof = append(proof, raw)
+		}
+		if len(entries) == 1 {
+			break
+		}
+		first, last := entries[0].key[depth:], entries[len(entries)-1].key[depth:]
+		if n := prefixLen(first, last); n > 0 {
+			if !bytes.HasPrefix(path[depth:], first[:n]) {
+				break
+			}
+			depth += n
+			continue
+		}
+		if len(path) == depth {
+			break
+		}
+		var children []entry
+		for _, entry := range entries {
+			if len(entry.key) > depth && entry.key[depth] == path[depth] {
+				children = append(children, entry)
+			}
+		}
+		entries = children
+		depth++
+	}
+	return proof
+}
+
+// entries returns the key/value pairs sorted by key
+func (self *Trie) entries() []entry {
+	keys := make([]string, 0, len(self.kvs))
+	for key := range self.kvs {
+		keys = append(keys, key)
+	}
+	// the byte order of the keys is also the order of their nibbles
+	sort.Strings(keys)
+	entries := make([]entry, len(keys))
+	for i, key := range keys {
+		entries[i] = entry{key: keybytesToHex([]byte(key)), value: self.kvs[key]}
+	}
+	return entries
+}
+
+// build returns the node holding the sorted entries, whose keys share the first depth nibbles
+func build(ar *fastrlp.Arena, entries []entry, depth int) *fastrlp.Value {
+	if len(entries) == 1 {
+		// leaf node
+		node := ar.NewArray()
+		node.Set(ar.NewBytes(hexToCompact(entries[0].key[depth:], true)))
+		node.Set(ar.NewBytes(entries[0].value))
+		return node
+	}
+
+	// the keys are sorted, so the common prefix of the first and last key is shared by all of them
+	first, last := entries[0].key[depth:], entries[len(entries)-1].key[depth:]
+	if n := prefixLen(first, last); n > 0 {
+		// extension node
+		node := ar.NewArray()
+		node.Set(ar.NewBytes(hexToCompact(first[:n], false)))
+		node.Set(reference(ar, build(ar, entries, depth+n)))
+		return node
+	}
+
+	// branch node, a key which ends here is stored as the value of the branch
+	node := ar.NewArray()
+	value := ar.NewNull()
+	if len(first) == 0 {
+		value = ar.NewBytes(entries[0].value)
+		entries = entries[1:]
+	}
+	for nibble := byte(0); nibble < 16; nibble++ {
+		end := 0
+		for end < len(entries) && entries[end].key[depth] == nibble {
+			end++
+		}
+		if end == 0 {
+			node.Set(ar.NewNull())
+			continue
+		}
+		node.Set(reference(ar, build(ar, entries[:end], depth+1)))
+		entries = entries[end:]
+	}
+	node.Set(value)
+	return node
+}
+
+// reference returns how a child node is referenced by its parent, nodes shorter than a hash are
+// embedded into the parent, other nodes are referenced by their hash.
+func reference(ar *fastrlp.Arena, node *fastrlp.Value) *fastrlp.Value {
+	raw := node.MarshalTo(nil)
+	if len(raw) < 32 {
+		return node
+	}
+	return ar.NewCopyBytes(crypto.Keccak256(raw))
+}
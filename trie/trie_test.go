@@ -0,0 +1,61 @@
+package trie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/laizy/web3"
+	"github.com/laizy/web3/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyTrie(t *testing.T) {
+	assert.Equal(t, EmptyRoot, NewTrie().Hash())
+	assert.Equal(t, EmptyRoot, NewSecureTrie().Hash())
+	assert.Equal(t, EmptyRoot, DeriveTransactionsRoot(nil))
+	assert.Equal(t, EmptyRoot, DeriveReceiptsRoot(nil))
+}
+
+func TestInsert(t *testing.T) {
+	trie := NewTrie()
+	trie.Update([]byte("doe"), []byte("reindeer"))
+	trie.Update([]byte("dog"), []byte("puppy"))
+	trie.Update([]byte("dogglesworth"), []byte("cat"))
+	assert.Equal(t, web3.HexToHash("0x8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3"), trie.Hash())
+
+	trie = NewTrie()
+	trie.Update([]byte("A"), []byte(strings.Repeat("a", 50)))
+	assert.Equal(t, web3.HexToHash("0xd23786fb4a010da3ce639d66d5e904a11dbc02746d1ce25029e53290cabf28ab"), trie.Hash())
+}
+
+func TestDelete(t *testing.T) {
+	trie := NewTrie()
+	vals := []struct{ k, v string }{
+		{"do", "verb"},
+		{"ether", "wookiedoo"},
+		{"horse", "stallion"},
+		{"shaman", "horse"},
+		{"doge", "coin"},
+		{"ether", ""},
+		{"dog", "puppy"},
+		{"shaman", ""},
+	}
+	for _, val := range vals {
+		trie.Update([]byte(val.k), []byte(val.v))
+	}
+	assert.Equal(t, 4, trie.Len())
+	assert.Nil(t, trie.Get([]byte("ether")))
+	assert.Equal(t, []byte("puppy"), trie.Get([]byte("dog")))
+	assert.Equal(t, web3.HexToHash("0x5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84"), trie.Hash())
+}
+
+func TestSecureTrie(t *testing.T) {
+	secure, plain := NewSecureTrie(), NewTrie()
+	for i := byte(0); i < 100; i++ {
+		key := []byte{i, i + 1}
+		secure.Update(key, []byte{i})
+		plain.Update(crypto.Keccak256(key), []byte{i})
+	}
+	assert.Equal(t, []byte{5}, secure.Get([]byte{5, 6}))
+	assert.Equal(t, plain.Hash(), secure.Hash())
+}
@@ -23,7 +23,6 @@ import (
 	"math/big"
 
 	"github.com/laizy/web3/evm/errors"
-	"github.com/laizy/web3/utils"
 	"github.com/laizy/web3/utils/common/hexutil"
 )
 
@@ -54,6 +53,8 @@ func NewExecutionResult(usedGas uint64, err error, ret []byte) *ExecutionResult
 	return result
 }
 
+// DecodeRevert decodes the revert data of Error(string), Panic(uint256) and the custom errors
+// known by the registered RevertDecoder.
 // revert data signature is: Error(string) (0x08c379a0)
 // https://ethereum.stackexchange.com/questions/83528/how-can-i-get-the-revert-reason-of-a-call-in-solidity-so-that-i-can-use-it-in-th
 func DecodeRevert(ret []byte) (string, bool) {
@@ -61,9 +62,17 @@ func DecodeRevert(ret []byte) (string, bool) {
 		// data layout: sig(4bytes) + strpos(32bytes,should equal 2) + strlength(32bytes) + strdata
 		data := ret[36:]
 		length, err := readLength(data)
-		utils.Ensure(err)
+		if err != nil || 32+length > len(data) {
+			return "", false
+		}
 		return string(data[32 : 32+length]), true
 	}
+	if len(ret) == 36 && hex.EncodeToString(ret[:4]) == "4e487b71" {
+		return decodePanic(new(big.Int).SetBytes(ret[4:])), true
+	}
+	if len(ret) >= 4 {
+		return GetRevertDecoder().DecodeRevert(ret)
+	}
 
 	return "", false
 }
@@ -107,3 +116,11 @@ func (result *ExecutionResult) Revert() []byte {
 	}
 	return CopyBytes(result.ReturnData)
 }
+
+// DecodeRevert decodes the revert data, false if the execution is not reverted or the data is unknown
+func (result *ExecutionResult) DecodeRevert() (string, bool) {
+	if result.Err != errors.ErrExecutionReverted {
+		return "", false
+	}
+	return DecodeRevert(result.ReturnData)
+}
@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/laizy/web3"
@@ -24,6 +25,9 @@ func NewEIP155Signer(chainID uint64) *EIP1155Signer {
 }
 
 func (e *EIP1155Signer) RecoverSender(tx *web3.Transaction) (web3.Address, error) {
+	if tx.Type != web3.TransactionLegacy {
+		return web3.Address{}, fmt.Errorf("transaction type %d not supported by eip155 signer", tx.Type)
+	}
 	v := new(big.Int).SetBytes(tx.V).Uint64()
 	v -= e.chainID * 2
 	v -= 8
@@ -41,6 +45,9 @@ func (e *EIP1155Signer) RecoverSender(tx *web3.Transaction) (web3.Address, error
 }
 
 func (e *EIP1155Signer) SignTx(tx *web3.Transaction, key *Key) (*web3.Transaction, error) {
+	if tx.Type != web3.TransactionLegacy {
+		return nil, fmt.Errorf("transaction type %d not supported by eip155 signer", tx.Type)
+	}
 	hash := signHash(tx, e.chainID)
 
 	sig, err := key.Sign(hash)
@@ -56,12 +63,85 @@ func (e *EIP1155Signer) SignTx(tx *web3.Transaction, key *Key) (*web3.Transactio
 	return tx, nil
 }
 
+// LondonSigner signs legacy (EIP-155), access list (EIP-2930) and
+// dynamic fee (EIP-1559) transactions
+type LondonSigner struct {
+	chainID uint64
+	legacy  *EIP1155Signer
+}
+
+func NewLondonSigner(chainID uint64) *LondonSigner {
+	return &LondonSigner{chainID: chainID, legacy: NewEIP155Signer(chainID)}
+}
+
+func (l *LondonSigner) RecoverSender(tx *web3.Transaction) (web3.Address, error) {
+	if tx.Type == web3.TransactionLegacy {
+		return l.legacy.RecoverSender(tx)
+	}
+	if err := l.checkTyped(tx); err != nil {
+		return web3.Address{}, err
+	}
+
+	// typed transactions use the y parity as v
+	v := new(big.Int).SetBytes(tx.V).Uint64()
+	if v > 1 {
+		return web3.Address{}, fmt.Errorf("invalid signature y parity %d", v)
+	}
+	sig, err := encodeSignature(tx.R, tx.S, byte(v))
+	if err != nil {
+		return web3.Address{}, err
+	}
+	return Ecrecover(signHash(tx, l.chainID), sig)
+}
+
+func (l *LondonSigner) SignTx(tx *web3.Transaction, key *Key) (*web3.Transaction, error) {
+	if tx.Type == web3.TransactionLegacy {
+		return l.legacy.SignTx(tx, key)
+	}
+	if tx.ChainID == nil {
+		tx.ChainID = new(big.Int).SetUint64(l.chainID)
+	}
+	if err := l.checkTyped(tx); err != nil {
+		return nil, err
+	}
+
+	sig, err := key.Sign(signHash(tx, l.chainID))
+	if err != nil {
+		return nil, err
+	}
+
+	tx.R = new(big.Int).SetBytes(sig[:32]).Bytes() // used to clean leading zeros
+	tx.S = new(big.Int).SetBytes(sig[32:64]).Bytes()
+	tx.V = new(big.Int).SetUint64(uint64(sig[64])).Bytes()
+	return tx, nil
+}
+
+func (l *LondonSigner) checkTyped(tx *web3.Transaction) error {
+	if tx.Type != web3.TransactionAccessList && tx.Type != web3.TransactionDynamicFee {
+		return fmt.Errorf("transaction type %d not supported", tx.Type)
+	}
+	if tx.ChainID == nil || tx.ChainID.Uint64() != l.chainID {
+		return fmt.Errorf("invalid chain id %v, expected %d", tx.ChainID, l.chainID)
+	}
+	return nil
+}
+
+// signHash returns the hash to sign for the transaction. Typed transactions commit
+// to their own chain id and type byte, legacy ones follow EIP-155.
 func signHash(tx *web3.Transaction, chainID uint64) []byte {
 	a := fastrlp.DefaultArenaPool.Get()
 
 	v := a.NewArray()
+	if tx.Type != web3.TransactionLegacy {
+		v.Set(a.NewBigInt(tx.ChainID))
+	}
 	v.Set(a.NewUint(tx.Nonce))
-	v.Set(a.NewUint(tx.GasPrice))
+	if tx.Type == web3.TransactionDynamicFee {
+		v.Set(a.NewBigInt(tx.MaxPriorityFeePerGas))
+		v.Set(a.NewBigInt(tx.MaxFeePerGas))
+	} else {
+		v.Set(a.NewUint(tx.GasPrice))
+	}
 	v.Set(a.NewUint(tx.Gas))
 	if tx.To == nil {
 		v.Set(a.NewNull())
@@ -71,14 +151,20 @@ func signHash(tx *web3.Transaction, chainID uint64) []byte {
 	v.Set(a.NewBigInt(tx.Value))
 	v.Set(a.NewCopyBytes(tx.Input))
 
-	// EIP155
-	if chainID != 0 {
+	if tx.Type != web3.TransactionLegacy {
+		v.Set(tx.AccessList.MarshalRLPWith(a))
+	} else if chainID != 0 {
+		// EIP155
 		v.Set(a.NewUint(chainID))
 		v.Set(a.NewUint(0))
 		v.Set(a.NewUint(0))
 	}
 
-	hash := keccak256(v.MarshalTo(nil))
+	var payload []byte
+	if tx.Type != web3.TransactionLegacy {
+		payload = append(payload, byte(tx.Type))
+	}
+	hash := keccak256(v.MarshalTo(payload))
 	fastrlp.DefaultArenaPool.Put(a)
 	return hash
 }
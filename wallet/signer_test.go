@@ -35,3 +35,56 @@ func TestSigner_EIP1155(t *testing.T) {
 		assert.NotEqual(t, from, from2)
 	*/
 }
+
+func TestSigner_London(t *testing.T) {
+	signer := NewLondonSigner(1337)
+
+	addr0 := web3.Address{0x1}
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	txns := []*web3.Transaction{
+		{
+			To:       &addr0,
+			Value:    big.NewInt(10),
+			GasPrice: 1,
+		},
+		{
+			Type:     web3.TransactionAccessList,
+			To:       &addr0,
+			Value:    big.NewInt(10),
+			GasPrice: 1,
+			AccessList: web3.AccessList{
+				{Address: addr0, Storage: []web3.Hash{{0x1}}},
+			},
+		},
+		{
+			Type:                 web3.TransactionDynamicFee,
+			To:                   &addr0,
+			Value:                big.NewInt(10),
+			MaxFeePerGas:         big.NewInt(100),
+			MaxPriorityFeePerGas: big.NewInt(2),
+		},
+	}
+	for _, txn := range txns {
+		txn, err = signer.SignTx(txn, key)
+		assert.NoError(t, err)
+
+		from, err := signer.RecoverSender(txn)
+		assert.NoError(t, err)
+		assert.Equal(t, from, key.addr)
+
+		// the signature survives the encoding round trip
+		txn2 := new(web3.Transaction)
+		assert.NoError(t, txn2.UnmarshalRLP(txn.MarshalRLP()))
+		assert.Equal(t, txn.Hash(), txn2.Hash())
+
+		from, err = signer.RecoverSender(txn2)
+		assert.NoError(t, err)
+		assert.Equal(t, from, key.addr)
+	}
+
+	// the eip155 signer does not sign typed transactions
+	_, err = NewEIP155Signer(1337).SignTx(txns[2], key)
+	assert.Error(t, err)
+}